@@ -1,21 +1,39 @@
 package api
 
 import (
+	"04blog/middleware"
 	"04blog/models"
+	"04blog/repositories"
 	"04blog/servers"
+	"errors"
 	"strconv"
 
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
 )
 
 type CommentVo struct {
 	ID int64 `json:"id"`
 	//必填 长度 2-2000
 	Content string `json:"content" binding:"required,min=2,max=2000"`
-	//必填
-	UserID int64 `json:"user_id" binding:"required"`
+	//评论用户 取当前登录用户
+	UserID int64 `json:"-"`
 	//必填
 	PostID int64 `json:"post_id" binding:"required"`
+	//选填 回复的评论ID
+	ParentID int64 `json:"parent_id"`
+}
+
+// 编辑评论VO
+type EditCommentVo struct {
+	//必填 长度 2-2000
+	Content string `json:"content" binding:"required,min=2,max=2000"`
+}
+
+// 审核评论VO
+type ModerateCommentVo struct {
+	//必填 pending/approved/hidden
+	Status models.CommentStatus `json:"status" binding:"required"`
 }
 
 type CommentApi struct {
@@ -27,7 +45,7 @@ func NewCommentAPI(commentService servers.CommentService) *CommentApi {
 	return &CommentApi{commentService: commentService}
 }
 
-// 新增评论
+// 新增评论或回复
 func (comm *CommentApi) CreateComment(c *gin.Context) {
 	var commentVo CommentVo
 	if err := c.ShouldBindJSON(&commentVo); err != nil {
@@ -35,42 +53,142 @@ func (comm *CommentApi) CreateComment(c *gin.Context) {
 		return
 	}
 	//上下文获取用户id
-	userID, exists := c.Get("user_id")
+	userID, exists := middleware.CurrentUserID(c)
 	if !exists {
 		c.JSON(401, gin.H{"error": "该用户没有登录 没有权限"})
 		return
 	}
-	commentVo.UserID = userID.(int64)
-	//todo 需要更新评论表中的评论数使用rides实现 评论数+1 默认为0
+	commentVo.UserID = userID
 
-	err := comm.commentService.CreateComment(&models.Comment{
-		Content: commentVo.Content,
-		UserID:  commentVo.UserID,
-		PostID:  commentVo.PostID,
-	})
+	comment := &models.Comment{
+		Content:  commentVo.Content,
+		UserID:   commentVo.UserID,
+		PostID:   commentVo.PostID,
+		ParentID: commentVo.ParentID,
+	}
+	err := comm.commentService.CreateComment(comment, middleware.IsAdmin(c))
 	if err != nil {
+		if errors.Is(err, servers.ErrInvalidParentComment) {
+			c.JSON(400, gin.H{"error": err.Error()})
+			return
+		}
 		c.JSON(500, gin.H{"error": err.Error()})
 		return
 	}
-	c.JSON(200, gin.H{"message": "评论创建成功"})
+	message := "评论创建成功"
+	if comment.Status == models.CommentStatusPending {
+		message = "评论已提交 审核通过后显示"
+	}
+	c.JSON(200, gin.H{"message": message, "data": comment})
 }
 
-// 根据文章ID获取评论
+// 根据文章ID分页获取评论楼层
 func (comm *CommentApi) GetCommentsByPostID(c *gin.Context) {
 	postID, err := strconv.ParseInt(c.Param("postID"), 10, 64)
 	if err != nil {
 		c.JSON(400, gin.H{"error": "无效的文章ID"})
 		return
 	}
-	comments, err := comm.commentService.GetCommentsByPostID(postID)
+	page, pageSize, ok := parsePageQuery(c)
+	if !ok {
+		return
+	}
+	threads, err := comm.commentService.GetThreads(postID, page, pageSize)
+	if err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(200, PageResult{
+		Data:  threads.Items,
+		Total: threads.Total,
+		Page:  offsetPageInfo(threads.Page, threads.PageSize, threads.Total),
+	})
+}
+
+// 获取文章已通过审核的评论数
+func (comm *CommentApi) GetCommentCount(c *gin.Context) {
+	postID, err := strconv.ParseInt(c.Param("postID"), 10, 64)
+	if err != nil {
+		c.JSON(400, gin.H{"error": "无效的文章ID"})
+		return
+	}
+	count, err := comm.commentService.GetCommentCount(postID)
+	if err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(200, gin.H{"post_id": postID, "count": count})
+}
+
+// 分页获取楼层中的回复 顶层评论未通过审核时返回404
+func (comm *CommentApi) GetReplies(c *gin.Context) {
+	commentID, err := strconv.ParseInt(c.Param("commentID"), 10, 64)
+	if err != nil {
+		c.JSON(400, gin.H{"error": "无效的评论ID"})
+		return
+	}
+	page, pageSize, ok := parsePageQuery(c)
+	if !ok {
+		return
+	}
+	replies, err := comm.commentService.GetReplies(commentID, page, pageSize)
+	if err != nil {
+		respondCommentError(c, err)
+		return
+	}
+	c.JSON(200, commentPageResult(replies))
+}
+
+// CommentOwner 根据路径参数commentID查询评论作者 供权限中间件使用
+func (comm *CommentApi) CommentOwner(c *gin.Context) (int64, error) {
+	commentID, err := strconv.ParseInt(c.Param("commentID"), 10, 64)
+	if err != nil {
+		return 0, middleware.ErrInvalidResourceID
+	}
+	comment, err := comm.commentService.GetCommentByID(commentID)
+	if err != nil {
+		return 0, err
+	}
+	return comment.UserID, nil
+}
+
+// 编辑评论 权限由路由上的 RequireOwnerOrAdmin 校验
+func (comm *CommentApi) EditComment(c *gin.Context) {
+	commentID, err := strconv.ParseInt(c.Param("commentID"), 10, 64)
+	if err != nil {
+		c.JSON(400, gin.H{"error": "无效的评论ID"})
+		return
+	}
+	var vo EditCommentVo
+	if err := c.ShouldBindJSON(&vo); err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+	editorID, _ := middleware.CurrentUserID(c)
+	comment, err := comm.commentService.EditComment(commentID, editorID, vo.Content, middleware.IsAdmin(c))
+	if err != nil {
+		respondCommentError(c, err)
+		return
+	}
+	c.JSON(200, gin.H{"message": "评论修改成功", "data": comment})
+}
+
+// 获取评论编辑历史 权限由路由上的 RequireOwnerOrAdmin 校验
+func (comm *CommentApi) GetRevisions(c *gin.Context) {
+	commentID, err := strconv.ParseInt(c.Param("commentID"), 10, 64)
+	if err != nil {
+		c.JSON(400, gin.H{"error": "无效的评论ID"})
+		return
+	}
+	revisions, err := comm.commentService.GetRevisions(commentID)
 	if err != nil {
 		c.JSON(500, gin.H{"error": err.Error()})
 		return
 	}
-	c.JSON(200, gin.H{"data": comments})
+	c.JSON(200, gin.H{"data": revisions})
 }
 
-// 删除评论
+// 删除评论及其回复 权限由路由上的 RequireOwnerOrAdmin 校验
 func (comm *CommentApi) DeleteComment(c *gin.Context) {
 	commentID, err := strconv.ParseInt(c.Param("commentID"), 10, 64)
 	if err != nil {
@@ -79,13 +197,13 @@ func (comm *CommentApi) DeleteComment(c *gin.Context) {
 	}
 	err = comm.commentService.DeleteComment(commentID)
 	if err != nil {
-		c.JSON(500, gin.H{"error": err.Error()})
+		respondCommentError(c, err)
 		return
 	}
 	c.JSON(200, gin.H{"message": "评论删除成功"})
 }
 
-// 根据评论ID获取评论
+// 根据评论ID获取评论 未通过审核的评论只有作者和管理员可见
 func (comm *CommentApi) GetCommentByID(c *gin.Context) {
 	commentID, err := strconv.ParseInt(c.Param("commentID"), 10, 64)
 	if err != nil {
@@ -94,8 +212,68 @@ func (comm *CommentApi) GetCommentByID(c *gin.Context) {
 	}
 	comment, err := comm.commentService.GetCommentByID(commentID)
 	if err != nil {
-		c.JSON(500, gin.H{"error": err.Error()})
+		respondCommentError(c, err)
+		return
+	}
+	if comment.Status != models.CommentStatusApproved && !middleware.IsOwnerOrAdmin(c, comment.UserID) {
+		c.JSON(404, gin.H{"error": "评论不存在"})
 		return
 	}
 	c.JSON(200, gin.H{"data": comment})
 }
+
+// 审核队列 按状态分页获取评论 默认待审核 仅管理员可用
+func (comm *CommentApi) GetModerationQueue(c *gin.Context) {
+	page, pageSize, ok := parsePageQuery(c)
+	if !ok {
+		return
+	}
+	status := models.CommentStatus(c.DefaultQuery("status", string(models.CommentStatusPending)))
+	comments, err := comm.commentService.GetModerationQueue(status, page, pageSize)
+	if err != nil {
+		respondCommentError(c, err)
+		return
+	}
+	c.JSON(200, commentPageResult(comments))
+}
+
+// 修改评论审核状态 仅管理员可用
+func (comm *CommentApi) ModerateComment(c *gin.Context) {
+	commentID, err := strconv.ParseInt(c.Param("commentID"), 10, 64)
+	if err != nil {
+		c.JSON(400, gin.H{"error": "无效的评论ID"})
+		return
+	}
+	var vo ModerateCommentVo
+	if err := c.ShouldBindJSON(&vo); err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+	comment, err := comm.commentService.Moderate(commentID, vo.Status)
+	if err != nil {
+		respondCommentError(c, err)
+		return
+	}
+	c.JSON(200, gin.H{"message": "审核状态已更新", "data": comment})
+}
+
+func commentPageResult(page *repositories.CommentPage) PageResult {
+	return PageResult{
+		Data:  page.Items,
+		Total: page.Total,
+		Page:  offsetPageInfo(page.Page, page.PageSize, page.Total),
+	}
+}
+
+func respondCommentError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		c.JSON(404, gin.H{"error": "评论不存在"})
+	case errors.Is(err, servers.ErrInvalidCommentStatus):
+		c.JSON(400, gin.H{"error": err.Error()})
+	case errors.Is(err, repositories.ErrCommentStatusChanged):
+		c.JSON(409, gin.H{"error": "评论状态已变化 请刷新后重试"})
+	default:
+		c.JSON(500, gin.H{"error": err.Error()})
+	}
+}
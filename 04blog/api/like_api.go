@@ -1,12 +1,14 @@
 package api
 
 import (
-	"04blog/models"
+	"04blog/middleware"
 	"04blog/servers"
+	"errors"
 	"net/http"
 	"strconv"
 
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
 )
 
 type LikeAPI struct {
@@ -17,63 +19,111 @@ func NewLikeAPI(likeService servers.LikeService) *LikeAPI {
 	return &LikeAPI{likeService: likeService}
 }
 
-// Create 创建点赞
+// 点赞VO 点赞用户取当前登录用户
+type LikeVO struct {
+	PostID int64 `json:"post_id" binding:"required"`
+}
+
+// 设置点赞状态VO
+type LikeStateVO struct {
+	// 必填 true 点赞 false 取消点赞
+	Liked *bool `json:"liked" binding:"required"`
+}
+
+// Create 点赞 重复点赞返回当前状态
 func (a *LikeAPI) Create(c *gin.Context) {
-	var like models.Like
-	//上下文获取用户id
-	userID, exists := c.Get("user_id")
-	if !exists {
+	a.setLikedFromBody(c, true)
+}
+
+// Delete 取消点赞 未点赞时返回当前状态
+func (a *LikeAPI) Delete(c *gin.Context) {
+	a.setLikedFromBody(c, false)
+}
+
+func (a *LikeAPI) setLikedFromBody(c *gin.Context, liked bool) {
+	userID, ok := middleware.CurrentUserID(c)
+	if !ok {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "未授权"})
 		return
 	}
-	like.UserID = userID.(int64)
-	if err := c.ShouldBindJSON(&like); err != nil {
+	var vo LikeVO
+	if err := c.ShouldBindJSON(&vo); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
-	if err := a.likeService.Create(&like); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	state, err := a.likeService.SetLiked(c.Request.Context(), userID, vo.PostID, liked)
+	if err != nil {
+		respondLikeError(c, err)
 		return
 	}
-	c.JSON(http.StatusOK, gin.H{"message": "新增点赞"})
+	c.JSON(http.StatusOK, state)
 }
 
-// Delete 删除点赞
-func (a *LikeAPI) Delete(c *gin.Context) {
-	var like models.Like
-	//上下文获取用户id
-	userID, exists := c.Get("user_id")
-	if !exists {
+// GetMyState 查询当前用户是否点赞了文章
+func (a *LikeAPI) GetMyState(c *gin.Context) {
+	userID, ok := middleware.CurrentUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "未授权"})
+		return
+	}
+	postID, err := strconv.ParseInt(c.Param("postID"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的文章ID"})
+		return
+	}
+	state, err := a.likeService.GetState(c.Request.Context(), userID, postID)
+	if err != nil {
+		respondLikeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, state)
+}
+
+// SetMyState 设置当前用户对文章的点赞状态 重复请求结果相同
+func (a *LikeAPI) SetMyState(c *gin.Context) {
+	userID, ok := middleware.CurrentUserID(c)
+	if !ok {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "未授权"})
 		return
 	}
-	like.UserID = userID.(int64)
-	if err := c.ShouldBindJSON(&like); err != nil {
+	postID, err := strconv.ParseInt(c.Param("postID"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的文章ID"})
+		return
+	}
+	var vo LikeStateVO
+	if err := c.ShouldBindJSON(&vo); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
-	if err := a.likeService.Delete(&like); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	state, err := a.likeService.SetLiked(c.Request.Context(), userID, postID, *vo.Liked)
+	if err != nil {
+		respondLikeError(c, err)
 		return
 	}
-	c.JSON(http.StatusOK, gin.H{"message": "删除点赞"})
+	c.JSON(http.StatusOK, state)
 }
 
 // GetByArticleID 获取文章点赞列表
 func (a *LikeAPI) GetByArticleID(c *gin.Context) {
-	var likes []*models.Like
 	//请求参数中获取文章id
 	postID, err := strconv.ParseInt(c.Param("postID"), 10, 64)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的文章ID"})
 		return
-
 	}
-	likes, err = a.likeService.GetByPostID(postID)
-
+	likes, err := a.likeService.GetByPostID(postID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 	c.JSON(http.StatusOK, gin.H{"likes": likes})
 }
+
+func respondLikeError(c *gin.Context, err error) {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "文章不存在"})
+		return
+	}
+	c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+}
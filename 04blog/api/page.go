@@ -0,0 +1,46 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// PageInfo 分页信息
+type PageInfo struct {
+	Page       int    `json:"page"`
+	PageSize   int    `json:"page_size"`
+	HasMore    bool   `json:"has_more"`
+	NextCursor string `json:"next_cursor,omitempty"` //传给下一次请求的 cursor 参数
+}
+
+// PageResult 列表接口统一返回结构
+type PageResult struct {
+	Data  interface{} `json:"data"`
+	Total int64       `json:"total"`
+	Page  PageInfo    `json:"page"`
+}
+
+// offsetPageInfo 偏移分页的分页信息
+func offsetPageInfo(page, pageSize int, total int64) PageInfo {
+	return PageInfo{
+		Page:     page,
+		PageSize: pageSize,
+		HasMore:  int64(page)*int64(pageSize) < total,
+	}
+}
+
+// parsePageQuery 解析 page、page_size 参数 解析失败时已写入400响应
+func parsePageQuery(c *gin.Context) (int, int, bool) {
+	page, err := parseInt64Query(c, "page")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return 0, 0, false
+	}
+	pageSize, err := parseInt64Query(c, "page_size")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return 0, 0, false
+	}
+	return int(page), int(pageSize), true
+}
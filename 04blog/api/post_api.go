@@ -1,14 +1,18 @@
 package api
 
 import (
+	"04blog/middleware"
 	"04blog/models"
+	"04blog/repositories"
 	"04blog/servers"
+	"errors"
 	"fmt"
 	"net/http"
 	"strconv"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
 )
 
 type PostAPI struct {
@@ -24,10 +28,7 @@ type PostVO struct {
 	Summary string `json:"summary" binding:"required,min=2,max=500"`
 	//可选 长度 2-255
 	Cover string `json:"cover" binding:"omitempty,min=2,max=255"`
-
-	ViewCount int   `json:"view_count"`
-	LikeCount int   `json:"like_count"`
-	UserID    int64 `json:"user_id" binding:"required"`
+	// 作者取登录用户 阅读量和点赞数由计数服务维护 都不从请求中读取
 }
 
 // NewPostAPI 创建文章API
@@ -44,8 +45,6 @@ func (api *PostAPI) SavePost(c *gin.Context) {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "该用户没有登录 没有权限"})
 		return
 	}
-	vo.UserID = userID.(int64)
-
 	if err := c.ShouldBindJSON(&vo); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
@@ -58,21 +57,11 @@ func (api *PostAPI) SavePost(c *gin.Context) {
 			CreatedAt: now,
 			UpdatedAt: now,
 		},
-		Title:     vo.Title,
-		Content:   vo.Content,
-		Summary:   vo.Summary,
-		Cover:     vo.Cover,
-		ViewCount: vo.ViewCount,
-		LikeCount: vo.LikeCount,
-		UserID:    vo.UserID,
-	}
-
-	// 设置默认值
-	if vo.ViewCount == 0 {
-		post.ViewCount = 0
-	}
-	if vo.LikeCount == 0 {
-		post.LikeCount = 0
+		Title:   vo.Title,
+		Content: vo.Content,
+		Summary: vo.Summary,
+		Cover:   vo.Cover,
+		UserID:  userID.(int64),
 	}
 	message := "文章创建成功"
 
@@ -84,7 +73,22 @@ func (api *PostAPI) SavePost(c *gin.Context) {
 			return
 		}
 	} else {
-		//更新文章
+		//更新文章 只有作者或管理员可以修改
+		ownerID, err := api.service.GetPostAuthorID(vo.ID)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				c.JSON(http.StatusNotFound, gin.H{"error": "文章不存在"})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		if !middleware.IsOwnerOrAdmin(c, ownerID) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "只能修改自己的文章"})
+			return
+		}
+		//管理员修改时保留原作者
+		post.UserID = ownerID
 		post.UpdatedAt = time.Now() // 确保更新时也设置正确的更新时间
 		fmt.Println("更新文章", post)
 		message = "文章更新成功"
@@ -114,38 +118,122 @@ func (api *PostAPI) GetPost(c *gin.Context) {
 	c.JSON(http.StatusOK, post)
 }
 
-// 多条件查询文章 不传入就是查询所有
+// GetPosts 分页查询文章
+// 支持参数: title content user_id created_from created_to sort_by order page page_size cursor
 func (api *PostAPI) GetPosts(c *gin.Context) {
-	conditions := map[string]interface{}{}
-	//请求参数  可选参数
-	title := c.Query("title")
-	if title != "" {
-		conditions["title"] = title
-	}
-	//请求参数  可选参数
-	userID := c.Query("user_id")
-	if userID != "" {
-		userIDInt, err := strconv.ParseInt(userID, 10, 64)
-		if err != nil {
+	query, err := parsePostQuery(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	page, err := api.service.ListPosts(query)
+	if err != nil {
+		if errors.Is(err, repositories.ErrInvalidCursor) {
 			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 			return
 		}
-		conditions["user_id"] = userIDInt
-	}
-	posts, err := api.service.GetPostsByConditions(conditions)
-	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
-	//内容 可选参数
-	content := c.Query("content")
-	if content != "" {
-		conditions["content"] = content
+	c.JSON(http.StatusOK, PageResult{
+		Data:  page.Items,
+		Total: page.Total,
+		Page: PageInfo{
+			Page:       page.Page,
+			PageSize:   page.PageSize,
+			HasMore:    page.HasMore,
+			NextCursor: page.NextCursor,
+		},
+	})
+}
+
+// parsePostQuery 解析文章列表查询参数
+func parsePostQuery(c *gin.Context) (*repositories.PostQuery, error) {
+	query := &repositories.PostQuery{
+		Title:   c.Query("title"),
+		Content: c.Query("content"),
+		Cursor:  c.Query("cursor"),
+		SortBy:  repositories.PostSortField(c.DefaultQuery("sort_by", string(repositories.PostSortCreatedAt))),
+	}
+	if !repositories.ValidPostSortField(query.SortBy) {
+		return nil, fmt.Errorf("sort_by 只支持 created_at、view_count、like_count")
+	}
+	//默认倒序 最新的在前
+	switch order := c.DefaultQuery("order", "desc"); order {
+	case "desc":
+		query.Desc = true
+	case "asc":
+		query.Desc = false
+	default:
+		return nil, fmt.Errorf("order 只支持 asc、desc")
+	}
+
+	var err error
+	if query.UserID, err = parseInt64Query(c, "user_id"); err != nil {
+		return nil, err
+	}
+	page, err := parseInt64Query(c, "page")
+	if err != nil {
+		return nil, err
+	}
+	pageSize, err := parseInt64Query(c, "page_size")
+	if err != nil {
+		return nil, err
+	}
+	query.Page, query.PageSize = int(page), int(pageSize)
+
+	if query.CreatedFrom, err = parseTimeQuery(c, "created_from", false); err != nil {
+		return nil, err
+	}
+	if query.CreatedTo, err = parseTimeQuery(c, "created_to", true); err != nil {
+		return nil, err
+	}
+	return query, nil
+}
+
+// parseInt64Query 解析可选的整数参数 未传入时返回0
+func parseInt64Query(c *gin.Context, key string) (int64, error) {
+	value := c.Query(key)
+	if value == "" {
+		return 0, nil
+	}
+	n, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("%s 必须是非负整数", key)
+	}
+	return n, nil
+}
+
+// parseTimeQuery 解析可选的时间参数 支持 RFC3339 和 2006-01-02
+// endOfDay 为 true 时只传日期表示包含当天 上限取次日零点
+func parseTimeQuery(c *gin.Context, key string, endOfDay bool) (*time.Time, error) {
+	value := c.Query(key)
+	if value == "" {
+		return nil, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return &t, nil
+	}
+	t, err := time.ParseInLocation("2006-01-02", value, time.Local)
+	if err != nil {
+		return nil, fmt.Errorf("%s 时间格式错误 应为 RFC3339 或 2006-01-02", key)
+	}
+	if endOfDay {
+		t = t.AddDate(0, 0, 1)
+	}
+	return &t, nil
+}
+
+// PostOwner 根据路径参数id查询文章作者 供权限中间件使用
+func (api *PostAPI) PostOwner(c *gin.Context) (int64, error) {
+	postID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return 0, middleware.ErrInvalidResourceID
 	}
-	c.JSON(http.StatusOK, posts)
+	return api.service.GetPostAuthorID(postID)
 }
 
-// DeletePost 删除文章
+// DeletePost 删除文章 权限由路由上的 RequireOwnerOrAdmin 校验
 func (api *PostAPI) DeletePost(c *gin.Context) {
 	id := c.Param("id")
 
@@ -0,0 +1,52 @@
+package api
+
+import (
+	"04blog/servers"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+type SearchAPI struct {
+	service servers.SearchService
+}
+
+// NewSearchAPI 创建搜索API
+func NewSearchAPI(service servers.SearchService) *SearchAPI {
+	return &SearchAPI{service: service}
+}
+
+// Search 全文搜索文章
+// 参数: q 关键词 必填; page page_size 分页 选填
+func (api *SearchAPI) Search(c *gin.Context) {
+	q := strings.TrimSpace(c.Query("q"))
+	if q == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "搜索关键词不能为空"})
+		return
+	}
+	page, pageSize, ok := parsePageQuery(c)
+	if !ok {
+		return
+	}
+	result, err := api.service.Search(q, page, pageSize)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, PageResult{
+		Data:  result.Hits,
+		Total: int64(result.Total),
+		Page:  offsetPageInfo(result.Page, result.PageSize, int64(result.Total)),
+	})
+}
+
+// RebuildIndex 从数据库全量重建搜索索引 仅管理员可用
+func (api *SearchAPI) RebuildIndex(c *gin.Context) {
+	count, err := api.service.Rebuild(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "索引重建完成", "count": count})
+}
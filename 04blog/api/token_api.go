@@ -0,0 +1,83 @@
+package api
+
+import (
+	"04blog/servers"
+	"04blog/utils"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type TokenAPI struct {
+	tokenService servers.TokenService
+}
+
+func NewTokenAPI(tokenService servers.TokenService) *TokenAPI {
+	return &TokenAPI{tokenService: tokenService}
+}
+
+// 刷新令牌VO
+type RefreshTokenVO struct {
+	// 刷新令牌 必填
+	RefreshToken string `json:"refresh_token" binding:"required"`
+}
+
+// 登出VO
+type LogoutVO struct {
+	// 刷新令牌 选填 传入时一并吊销
+	RefreshToken string `json:"refresh_token"`
+}
+
+// Refresh 使用刷新令牌换取新的令牌对
+func (t *TokenAPI) Refresh(c *gin.Context) {
+	var vo RefreshTokenVO
+	if err := c.ShouldBindJSON(&vo); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	tokens, err := t.tokenService.Refresh(c.Request.Context(), vo.RefreshToken)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "无效的刷新令牌"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"token":         tokens.AccessToken,
+		"refresh_token": tokens.RefreshToken,
+		"expires_in":    tokens.ExpiresIn,
+	})
+}
+
+// Logout 登出 吊销当前访问令牌和传入的刷新令牌
+func (t *TokenAPI) Logout(c *gin.Context) {
+	value, exists := c.Get("claims")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "未授权"})
+		return
+	}
+	claims := value.(*utils.JWTClaims)
+
+	var vo LogoutVO
+	// 请求体可以为空
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&vo); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	if err := t.tokenService.Revoke(c.Request.Context(), claims); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if vo.RefreshToken != "" {
+		refreshClaims, err := utils.ParseRefreshToken(vo.RefreshToken)
+		// 只能吊销属于当前用户的刷新令牌
+		if err == nil && refreshClaims.UserID == claims.UserID {
+			if err := t.tokenService.Revoke(c.Request.Context(), refreshClaims); err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+				return
+			}
+		}
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "已退出登录"})
+}
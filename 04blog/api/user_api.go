@@ -39,8 +39,12 @@ type LoginUserVO struct {
 
 // 登录响应VO
 type LoginUserResponseVO struct {
-	// 登录凭证
+	// 登录凭证（访问令牌）
 	Token string `json:"token"`
+	// 刷新令牌
+	RefreshToken string `json:"refresh_token"`
+	// 访问令牌剩余有效秒数
+	ExpiresIn int64 `json:"expires_in"`
 	// 用户信息
 	UserInfo models.User `json:"user_info"`
 }
@@ -80,7 +84,7 @@ func (u *UserAPI) LoginUser(c *gin.Context) {
 	}
 
 	//调用服务层登录用户
-	token, userInfo, err := u.userService.LoginUser(vo.UsernameOrMobile, vo.Password)
+	tokens, userInfo, err := u.userService.LoginUser(vo.UsernameOrMobile, vo.Password)
 	if err != nil {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
 		return
@@ -88,8 +92,10 @@ func (u *UserAPI) LoginUser(c *gin.Context) {
 
 	//返回登录成功响应
 	c.JSON(http.StatusOK, gin.H{
-		"token":     token,
-		"user_info": userInfo,
+		"token":         tokens.AccessToken,
+		"refresh_token": tokens.RefreshToken,
+		"expires_in":    tokens.ExpiresIn,
+		"user_info":     userInfo,
 	})
 }
 
@@ -17,68 +17,111 @@ import (
 
 type Config struct {
 	//服务器配置
-	Server ServerConfig
+	Server ServerConfig `yaml:"server" toml:"server"`
 	//数据库配置
-	DB DBConfig
+	DB DBConfig `yaml:"db" toml:"db"`
 	//Redis配置
-	Redis RedisConfig
+	Redis RedisConfig `yaml:"redis" toml:"redis"`
+	//JWT配置
+	JWT JWTConfig `yaml:"jwt" toml:"jwt"`
+	//密码哈希配置
+	Password PasswordConfig `yaml:"password" toml:"password"`
+	//日志配置
+	Log LogConfig `yaml:"log" toml:"log"`
+	//启动重试配置
+	Startup StartupConfig `yaml:"startup" toml:"startup"`
+	//计数器回写配置
+	Counter CounterConfig `yaml:"counter" toml:"counter"`
+	//评论配置
+	Comment CommentConfig `yaml:"comment" toml:"comment"`
 }
 
 type ServerConfig struct {
-	Port string //服务器端口
-	Host string //服务器主机
+	Port string `yaml:"port" toml:"port"` //服务器端口
+	Host string `yaml:"host" toml:"host"` //服务器主机
+	//优雅关闭时等待请求处理完成的最长时间
+	ShutdownTimeout Duration `yaml:"shutdown_timeout" toml:"shutdown_timeout"`
 }
 
 type DBConfig struct {
-	Host          string //数据库主机
-	Port          string //数据库端口
-	User          string //数据库用户名
-	Pass          string //数据库密码
-	Name          string //数据库名
-	IsAutoMigrate bool   //是否自动迁移数据库
+	Host          string `yaml:"host" toml:"host"`                       //数据库主机
+	Port          string `yaml:"port" toml:"port"`                       //数据库端口
+	User          string `yaml:"user" toml:"user"`                       //数据库用户名
+	Pass          string `yaml:"pass" toml:"pass"`                       //数据库密码
+	Name          string `yaml:"name" toml:"name"`                       //数据库名
+	IsAutoMigrate bool   `yaml:"is_auto_migrate" toml:"is_auto_migrate"` //是否自动迁移数据库
 }
 
 type RedisConfig struct {
-	Host     string //Redis主机
-	Port     string //Redis端口
-	Password string //Redis密码
-	DB       int    //Redis数据库索引
+	Host     string `yaml:"host" toml:"host"`         //Redis主机
+	Port     string `yaml:"port" toml:"port"`         //Redis端口
+	Password string `yaml:"password" toml:"password"` //Redis密码
+	DB       int    `yaml:"db" toml:"db"`             //Redis数据库索引
 }
 
-// AppConfig 全局配置实例
-var AppConfig Config
+type JWTConfig struct {
+	KeyID           string            `yaml:"key_id" toml:"key_id"`                       //当前签名密钥ID 写入令牌头部 kid
+	Secret          string            `yaml:"secret" toml:"secret"`                       //当前签名密钥
+	RetiredKeys     map[string]string `yaml:"retired_keys" toml:"retired_keys"`           //已轮换的旧密钥 kid -> secret 旧令牌过期前仍可校验
+	TokenTTL        Duration          `yaml:"token_ttl" toml:"token_ttl"`                 //访问令牌有效期 如 2h
+	RefreshTokenTTL Duration          `yaml:"refresh_token_ttl" toml:"refresh_token_ttl"` //刷新令牌有效期 如 168h
+}
 
-// RedisClient 全局Redis客户端实例
-var RedisClient *redis.Client
+type PasswordConfig struct {
+	Algorithm string `yaml:"algorithm" toml:"algorithm"` //新密码使用的哈希算法 argon2id/bcrypt
+}
 
-func LoadConfig() error {
-	//走环境变量加载配置信息  如果没有 则走默认值
-	AppConfig = Config{
-		Server: ServerConfig{
-			Port: "8080",
-			Host: "localhost",
-		},
-		DB: DBConfig{
-			Host:          "172.18.112.82", //wls 虚拟化ip  数据库主机
-			Port:          "3306",
-			User:          "root",
-			Pass:          "root",
-			Name:          "moon_blog",
-			IsAutoMigrate: false,
-		},
-		Redis: RedisConfig{
-			Host:     "172.18.112.82",
-			Port:     "6379",
-			Password: "123456",
-			DB:       0,
-		},
+type LogConfig struct {
+	Level string `yaml:"level" toml:"level"` //日志级别 debug/info/warn/error/silent
+}
+
+// StartupConfig 启动时连接依赖服务（MySQL、Redis）的重试配置
+type StartupConfig struct {
+	MaxRetries     int      `yaml:"max_retries" toml:"max_retries"`         //最大重试次数 0 表示只尝试一次
+	InitialBackoff Duration `yaml:"initial_backoff" toml:"initial_backoff"` //首次重试等待时间
+	MaxBackoff     Duration `yaml:"max_backoff" toml:"max_backoff"`         //重试等待时间上限
+	PingTimeout    Duration `yaml:"ping_timeout" toml:"ping_timeout"`       //单次健康检查超时时间
+}
+
+// CounterConfig 阅读量、点赞数从Redis回写MySQL的配置
+type CounterConfig struct {
+	FlushInterval     Duration `yaml:"flush_interval" toml:"flush_interval"`         //回写间隔
+	FlushBatchSize    int      `yaml:"flush_batch_size" toml:"flush_batch_size"`     //每批回写的文章数
+	ReconcileInterval Duration `yaml:"reconcile_interval" toml:"reconcile_interval"` //按点赞表校正点赞数的间隔
+}
+
+type CommentConfig struct {
+	RequireApproval bool `yaml:"require_approval" toml:"require_approval"` //普通用户的评论是否需要管理员审核后显示
+}
+
+// Duration 支持从 "30m"、"24h" 这类字符串解析的时间间隔
+type Duration struct {
+	time.Duration
+}
+
+// UnmarshalText 实现 encoding.TextUnmarshaler，供 YAML/TOML 解析使用
+func (d *Duration) UnmarshalText(text []byte) error {
+	v, err := time.ParseDuration(string(text))
+	if err != nil {
+		return err
 	}
+	d.Duration = v
 	return nil
 }
 
-func InitDB() (*gorm.DB, error) {
-	// 初始化数据库连接
-	// 这里可以使用数据库驱动的连接函数，如 mysql.Open()
+// MarshalText 实现 encoding.TextMarshaler
+func (d Duration) MarshalText() ([]byte, error) {
+	return []byte(d.Duration.String()), nil
+}
+
+// AppConfig 全局配置实例
+var AppConfig Config
+
+// RedisClient 全局Redis客户端实例
+var RedisClient *redis.Client
+
+// InitDB 初始化数据库连接 连接失败时按启动配置退避重试
+func InitDB(ctx context.Context) (*gorm.DB, error) {
 	// 连接字符串格式："username:password@tcp(host:port)/dbname?charset=utf8mb4&parseTime=True&loc=Local"
 	dsn := fmt.Sprintf("%s:%s@tcp(%s:%s)/%s?charset=utf8mb4&parseTime=True&loc=Local",
 		AppConfig.DB.User, AppConfig.DB.Pass,
@@ -88,20 +131,32 @@ func InitDB() (*gorm.DB, error) {
 	newLogger := logger.New(
 		log.New(os.Stdout, "\r\n", log.LstdFlags), // io writer
 		logger.Config{
-			SlowThreshold:             time.Second, // Slow SQL threshold
-			LogLevel:                  logger.Info, // Log level
-			IgnoreRecordNotFoundError: true,        // Ignore ErrRecordNotFound error for logger
-			ParameterizedQueries:      true,        // Don't include params in the SQL log
-			Colorful:                  true,        // Disable color
+			SlowThreshold:             time.Second,                       // Slow SQL threshold
+			LogLevel:                  gormLogLevel(AppConfig.Log.Level), // Log level
+			IgnoreRecordNotFoundError: true,                              // Ignore ErrRecordNotFound error for logger
+			ParameterizedQueries:      true,                              // Don't include params in the SQL log
+			Colorful:                  true,                              // Disable color
 		},
 	)
 
-	// 打开数据库连接
-	db, err := gorm.Open(mysql.Open(dsn), &gorm.Config{
-		Logger: newLogger,
+	// 打开数据库连接 gorm.Open 会自动 ping 一次
+	var db *gorm.DB
+	err := retry(ctx, "MySQL", func() error {
+		var err error
+		db, err = gorm.Open(mysql.Open(dsn), &gorm.Config{
+			Logger: newLogger,
+		})
+		if err != nil {
+			return err
+		}
+		if err := PingDB(ctx, db); err != nil {
+			CloseDB(db)
+			return err
+		}
+		return nil
 	})
 	if err != nil {
-		panic("failed to connect database")
+		return nil, fmt.Errorf("连接数据库失败: %w", err)
 	}
 
 	// 自动迁移所有模型
@@ -110,35 +165,92 @@ func InitDB() (*gorm.DB, error) {
 			&models.User{},
 			&models.Post{},
 			&models.Comment{},
+			&models.CommentRevision{},
 			&models.Like{},
 		); err != nil {
-			panic("failed to migrate database")
+			CloseDB(db)
+			return nil, fmt.Errorf("数据库迁移失败: %w", err)
 		}
 	}
 
 	// 数据库连接成功
+	log.Println("数据库连接成功")
 	return db, nil
 }
 
-// InitRedis 初始化Redis连接
-func InitRedis() (*redis.Client, error) {
+// PingDB 数据库健康检查
+func PingDB(ctx context.Context, db *gorm.DB) error {
+	sqlDB, err := db.DB()
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(ctx, AppConfig.Startup.PingTimeout.Duration)
+	defer cancel()
+	return sqlDB.PingContext(ctx)
+}
+
+// CloseDB 关闭数据库连接池
+func CloseDB(db *gorm.DB) error {
+	if db == nil {
+		return nil
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		return err
+	}
+	return sqlDB.Close()
+}
+
+// InitRedis 初始化Redis连接 连接失败时按启动配置退避重试
+func InitRedis(ctx context.Context) (*redis.Client, error) {
 	// 创建Redis客户端
-	RedisClient = redis.NewClient(&redis.Options{
+	client := redis.NewClient(&redis.Options{
 		Addr:     fmt.Sprintf("%s:%s", AppConfig.Redis.Host, AppConfig.Redis.Port),
 		Password: AppConfig.Redis.Password,
 		DB:       AppConfig.Redis.DB,
 	})
 
 	// 测试连接
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
-
-	_, err := RedisClient.Ping(ctx).Result()
+	err := retry(ctx, "Redis", func() error {
+		return PingRedis(ctx, client)
+	})
 	if err != nil {
-		log.Printf("Redis连接失败: %v\n", err)
-		return nil, err
+		client.Close()
+		return nil, fmt.Errorf("连接Redis失败: %w", err)
 	}
 
 	log.Println("Redis连接成功")
+	RedisClient = client
 	return RedisClient, nil
 }
+
+// PingRedis Redis健康检查
+func PingRedis(ctx context.Context, client *redis.Client) error {
+	ctx, cancel := context.WithTimeout(ctx, AppConfig.Startup.PingTimeout.Duration)
+	defer cancel()
+	return client.Ping(ctx).Err()
+}
+
+// retry 以指数退避的方式执行 fn 直到成功、重试次数用尽或 ctx 被取消
+func retry(ctx context.Context, name string, fn func() error) error {
+	backoff := AppConfig.Startup.InitialBackoff.Duration
+	var err error
+	for attempt := 0; ; attempt++ {
+		if err = fn(); err == nil {
+			return nil
+		}
+		if attempt >= AppConfig.Startup.MaxRetries {
+			return err
+		}
+		log.Printf("%s连接失败（第%d次）: %v，%s后重试", name, attempt+1, err, backoff)
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("%w（最后一次错误: %v）", ctx.Err(), err)
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+		if max := AppConfig.Startup.MaxBackoff.Duration; max > 0 && backoff > max {
+			backoff = max
+		}
+	}
+}
@@ -0,0 +1,306 @@
+package config
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/goccy/go-yaml"
+	"github.com/pelletier/go-toml/v2"
+	"gorm.io/gorm/logger"
+)
+
+// EnvPrefix 环境变量前缀
+const EnvPrefix = "BLOG_"
+
+// DefaultConfigFile 默认配置文件路径 不存在时跳过
+const DefaultConfigFile = "config/config.yaml"
+
+// option 描述一个可以由环境变量和命令行参数覆盖的配置项
+type option struct {
+	env   string                          //环境变量名（不含前缀）
+	flag  string                          //命令行参数名
+	usage string                          //参数说明
+	set   func(c *Config, v string) error //写入配置
+}
+
+// options 所有可覆盖的配置项
+var options = []option{
+	{"SERVER_HOST", "host", "服务器主机", func(c *Config, v string) error { c.Server.Host = v; return nil }},
+	{"SERVER_PORT", "port", "服务器端口", func(c *Config, v string) error { c.Server.Port = v; return nil }},
+	{"SERVER_SHUTDOWN_TIMEOUT", "shutdown-timeout", "优雅关闭超时时间 如 15s", func(c *Config, v string) error {
+		return c.Server.ShutdownTimeout.UnmarshalText([]byte(v))
+	}},
+	{"DB_HOST", "db-host", "数据库主机", func(c *Config, v string) error { c.DB.Host = v; return nil }},
+	{"DB_PORT", "db-port", "数据库端口", func(c *Config, v string) error { c.DB.Port = v; return nil }},
+	{"DB_USER", "db-user", "数据库用户名", func(c *Config, v string) error { c.DB.User = v; return nil }},
+	{"DB_PASS", "db-pass", "数据库密码", func(c *Config, v string) error { c.DB.Pass = v; return nil }},
+	{"DB_NAME", "db-name", "数据库名", func(c *Config, v string) error { c.DB.Name = v; return nil }},
+	{"DB_AUTO_MIGRATE", "db-auto-migrate", "是否自动迁移数据库", func(c *Config, v string) error {
+		b, err := strconv.ParseBool(v)
+		c.DB.IsAutoMigrate = b
+		return err
+	}},
+	{"REDIS_HOST", "redis-host", "Redis主机", func(c *Config, v string) error { c.Redis.Host = v; return nil }},
+	{"REDIS_PORT", "redis-port", "Redis端口", func(c *Config, v string) error { c.Redis.Port = v; return nil }},
+	{"REDIS_PASSWORD", "redis-password", "Redis密码", func(c *Config, v string) error { c.Redis.Password = v; return nil }},
+	{"REDIS_DB", "redis-db", "Redis数据库索引", func(c *Config, v string) error {
+		n, err := strconv.Atoi(v)
+		c.Redis.DB = n
+		return err
+	}},
+	{"JWT_KEY_ID", "jwt-key-id", "JWT当前签名密钥ID", func(c *Config, v string) error { c.JWT.KeyID = v; return nil }},
+	{"JWT_SECRET", "jwt-secret", "JWT签名密钥", func(c *Config, v string) error { c.JWT.Secret = v; return nil }},
+	{"JWT_RETIRED_KEYS", "jwt-retired-keys", "已轮换的JWT密钥 格式 kid1=secret1,kid2=secret2", func(c *Config, v string) error {
+		keys, err := parseKeyList(v)
+		c.JWT.RetiredKeys = keys
+		return err
+	}},
+	{"JWT_TOKEN_TTL", "jwt-token-ttl", "JWT访问令牌有效期 如 2h", func(c *Config, v string) error {
+		return c.JWT.TokenTTL.UnmarshalText([]byte(v))
+	}},
+	{"JWT_REFRESH_TOKEN_TTL", "jwt-refresh-token-ttl", "JWT刷新令牌有效期 如 168h", func(c *Config, v string) error {
+		return c.JWT.RefreshTokenTTL.UnmarshalText([]byte(v))
+	}},
+	{"PASSWORD_ALGORITHM", "password-algorithm", "密码哈希算法 argon2id/bcrypt", func(c *Config, v string) error { c.Password.Algorithm = v; return nil }},
+	{"LOG_LEVEL", "log-level", "日志级别 debug/info/warn/error/silent", func(c *Config, v string) error { c.Log.Level = v; return nil }},
+	{"STARTUP_MAX_RETRIES", "startup-max-retries", "启动时连接依赖服务的最大重试次数", func(c *Config, v string) error {
+		n, err := strconv.Atoi(v)
+		c.Startup.MaxRetries = n
+		return err
+	}},
+	{"COUNTER_FLUSH_INTERVAL", "counter-flush-interval", "计数器回写MySQL的间隔 如 10s", func(c *Config, v string) error {
+		return c.Counter.FlushInterval.UnmarshalText([]byte(v))
+	}},
+	{"COUNTER_RECONCILE_INTERVAL", "counter-reconcile-interval", "按点赞表校正点赞数的间隔 如 1h", func(c *Config, v string) error {
+		return c.Counter.ReconcileInterval.UnmarshalText([]byte(v))
+	}},
+	{"COMMENT_REQUIRE_APPROVAL", "comment-require-approval", "普通用户的评论是否需要审核", func(c *Config, v string) error {
+		b, err := strconv.ParseBool(v)
+		c.Comment.RequireApproval = b
+		return err
+	}},
+}
+
+// DefaultConfig 返回默认配置 只包含与部署环境无关的值
+func DefaultConfig() Config {
+	return Config{
+		Server: ServerConfig{
+			Port:            "8080",
+			Host:            "localhost",
+			ShutdownTimeout: Duration{15 * time.Second},
+		},
+		DB: DBConfig{
+			Host:          "127.0.0.1",
+			Port:          "3306",
+			User:          "root",
+			Name:          "moon_blog",
+			IsAutoMigrate: false,
+		},
+		Redis: RedisConfig{
+			Host: "127.0.0.1",
+			Port: "6379",
+			DB:   0,
+		},
+		JWT: JWTConfig{
+			KeyID:           "default",
+			TokenTTL:        Duration{2 * time.Hour},
+			RefreshTokenTTL: Duration{7 * 24 * time.Hour},
+		},
+		Password: PasswordConfig{
+			Algorithm: "argon2id",
+		},
+		Log: LogConfig{
+			Level: "info",
+		},
+		Startup: StartupConfig{
+			MaxRetries:     5,
+			InitialBackoff: Duration{time.Second},
+			MaxBackoff:     Duration{30 * time.Second},
+			PingTimeout:    Duration{5 * time.Second},
+		},
+		Counter: CounterConfig{
+			FlushInterval:     Duration{10 * time.Second},
+			FlushBatchSize:    500,
+			ReconcileInterval: Duration{time.Hour},
+		},
+	}
+}
+
+// LoadConfig 按 默认值 -> 配置文件 -> BLOG_* 环境变量 -> 命令行参数 的顺序加载配置
+// 配置文件路径由 -config 参数或 BLOG_CONFIG 环境变量指定
+func LoadConfig() error {
+	cfg, err := Load(os.Args[1:])
+	if err != nil {
+		return err
+	}
+	AppConfig = cfg
+	return nil
+}
+
+// Load 根据命令行参数加载配置 不修改全局配置
+func Load(args []string) (Config, error) {
+	cfg := DefaultConfig()
+
+	//解析命令行参数 只记录显式传入的参数 后面再覆盖
+	fs := flag.NewFlagSet("blog", flag.ContinueOnError)
+	configFile := fs.String("config", "", "配置文件路径（.yaml/.yml/.toml）")
+	flagValues := make(map[string]*string, len(options))
+	for _, opt := range options {
+		flagValues[opt.flag] = fs.String(opt.flag, "", opt.usage)
+	}
+	if err := fs.Parse(args); err != nil {
+		return cfg, err
+	}
+	setFlags := map[string]bool{}
+	fs.Visit(func(f *flag.Flag) { setFlags[f.Name] = true })
+
+	//配置文件
+	path, explicit := *configFile, true
+	if path == "" {
+		path = os.Getenv(EnvPrefix + "CONFIG")
+	}
+	if path == "" {
+		path, explicit = DefaultConfigFile, false
+	}
+	if err := loadFile(&cfg, path); err != nil {
+		if explicit || !errors.Is(err, os.ErrNotExist) {
+			return cfg, err
+		}
+	}
+
+	//环境变量
+	for _, opt := range options {
+		if v, ok := os.LookupEnv(EnvPrefix + opt.env); ok {
+			if err := opt.set(&cfg, v); err != nil {
+				return cfg, fmt.Errorf("环境变量 %s%s 无效: %w", EnvPrefix, opt.env, err)
+			}
+		}
+	}
+
+	//命令行参数
+	for _, opt := range options {
+		if setFlags[opt.flag] {
+			if err := opt.set(&cfg, *flagValues[opt.flag]); err != nil {
+				return cfg, fmt.Errorf("命令行参数 -%s 无效: %w", opt.flag, err)
+			}
+		}
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return cfg, err
+	}
+	return cfg, nil
+}
+
+// loadFile 根据扩展名解析 YAML 或 TOML 配置文件
+func loadFile(cfg *Config, path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("读取配置文件 %s 失败: %w", path, err)
+	}
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, cfg)
+	case ".toml":
+		err = toml.Unmarshal(data, cfg)
+	default:
+		return fmt.Errorf("不支持的配置文件格式: %s", path)
+	}
+	if err != nil {
+		return fmt.Errorf("解析配置文件 %s 失败: %w", path, err)
+	}
+	return nil
+}
+
+// parseKeyList 解析 kid1=secret1,kid2=secret2 格式的密钥列表
+func parseKeyList(v string) (map[string]string, error) {
+	keys := map[string]string{}
+	for _, item := range strings.Split(v, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		kid, secret, ok := strings.Cut(item, "=")
+		if !ok || kid == "" || secret == "" {
+			return nil, fmt.Errorf("密钥格式应为 kid=secret: %q", item)
+		}
+		keys[kid] = secret
+	}
+	return keys, nil
+}
+
+// Validate 校验必填配置
+func (c *Config) Validate() error {
+	var errs []error
+	required := []struct{ key, value string }{
+		{"server.port", c.Server.Port},
+		{"db.host", c.DB.Host},
+		{"db.port", c.DB.Port},
+		{"db.user", c.DB.User},
+		{"db.name", c.DB.Name},
+		{"redis.host", c.Redis.Host},
+		{"redis.port", c.Redis.Port},
+		{"jwt.secret", c.JWT.Secret},
+	}
+	for _, r := range required {
+		if strings.TrimSpace(r.value) == "" {
+			errs = append(errs, fmt.Errorf("缺少必填配置 %s", r.key))
+		}
+	}
+	if c.JWT.TokenTTL.Duration <= 0 || c.JWT.RefreshTokenTTL.Duration <= 0 {
+		errs = append(errs, errors.New("jwt.token_ttl 和 jwt.refresh_token_ttl 必须大于0"))
+	}
+	if _, ok := c.JWT.RetiredKeys[c.JWT.KeyID]; ok {
+		errs = append(errs, fmt.Errorf("jwt.retired_keys 不能包含当前密钥ID %q", c.JWT.KeyID))
+	}
+	if c.Server.ShutdownTimeout.Duration <= 0 {
+		errs = append(errs, errors.New("server.shutdown_timeout 必须大于0"))
+	}
+	if c.Startup.MaxRetries < 0 {
+		errs = append(errs, errors.New("startup.max_retries 不能小于0"))
+	}
+	if c.Startup.InitialBackoff.Duration <= 0 || c.Startup.PingTimeout.Duration <= 0 {
+		errs = append(errs, errors.New("startup.initial_backoff 和 startup.ping_timeout 必须大于0"))
+	}
+	if c.Counter.FlushInterval.Duration <= 0 || c.Counter.ReconcileInterval.Duration <= 0 {
+		errs = append(errs, errors.New("counter.flush_interval 和 counter.reconcile_interval 必须大于0"))
+	}
+	if c.Counter.FlushBatchSize <= 0 {
+		errs = append(errs, errors.New("counter.flush_batch_size 必须大于0"))
+	}
+	switch strings.ToLower(c.Password.Algorithm) {
+	case "argon2id", "bcrypt":
+	default:
+		errs = append(errs, fmt.Errorf("不支持的密码哈希算法 %q", c.Password.Algorithm))
+	}
+	if _, ok := logLevels[strings.ToLower(c.Log.Level)]; !ok {
+		errs = append(errs, fmt.Errorf("不支持的日志级别 %q", c.Log.Level))
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("配置校验失败: %w", errors.Join(errs...))
+	}
+	return nil
+}
+
+// logLevels 日志级别与gorm日志级别的对应关系
+var logLevels = map[string]logger.LogLevel{
+	"debug":  logger.Info,
+	"info":   logger.Info,
+	"warn":   logger.Warn,
+	"error":  logger.Error,
+	"silent": logger.Silent,
+}
+
+// gormLogLevel 将配置中的日志级别转换为gorm日志级别
+func gormLogLevel(level string) logger.LogLevel {
+	if l, ok := logLevels[strings.ToLower(level)]; ok {
+		return l
+	}
+	return logger.Info
+}
@@ -8,3 +8,16 @@ const RedisKeyPostLikes = "post_likes:%d"
 
 //文章阅读量缓存键
 const RedisKeyPostViews = "post_views:%d"
+
+// RedisKeyTokenDenylist 已吊销令牌的jti 过期时间与令牌剩余有效期一致
+const RedisKeyTokenDenylist = "token_denylist:%s"
+
+// RedisKeyDirtyPostCounters 阅读量或点赞数有变化、等待回写MySQL的文章ID集合
+const RedisKeyDirtyPostCounters = "post_counters:dirty"
+
+// RedisKeyPostLikers 点赞了文章的用户ID集合 包含占位成员0 用于区分"没有人点赞"和"缓存不存在"
+const RedisKeyPostLikers = "post_likers:%d"
+
+// RedisKeyPostLikersVersion 点赞用户集合的版本号 每次点赞或取消点赞后递增
+// 从数据库加载集合前读取 写入时版本号已变化说明加载的数据可能已过期 放弃写入
+const RedisKeyPostLikersVersion = "post_likers_version:%d"
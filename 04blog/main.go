@@ -3,30 +3,109 @@ package main
 import (
 	"04blog/config"
 	"04blog/middleware"
+	"04blog/repositories"
 	"04blog/routes"
+	"04blog/servers"
+	"04blog/utils"
+	"context"
+	"errors"
 	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
 
 	"github.com/gin-gonic/gin" // 需运行 go mod tidy 安装依赖
 )
 
 func main() {
+	//子命令 rebuild-index 通知运行中的服务从数据库重建搜索索引
+	if len(os.Args) > 1 && os.Args[1] == "rebuild-index" {
+		if err := rebuildIndex(os.Args[2:]); err != nil {
+			log.Fatalf("重建搜索索引失败: %v", err)
+		}
+		return
+	}
+	if err := run(); err != nil {
+		log.Fatalf("服务异常退出: %v", err)
+	}
+}
 
+func run() error {
 	//加载服务器配置信息
 	if err := config.LoadConfig(); err != nil {
-		fmt.Printf("加载配置失败: %v", err)
+		return fmt.Errorf("加载配置失败: %w", err)
 	}
+	//JWT配置
+	utils.InitJWT(utils.JWTOptions{
+		KeyID:       config.AppConfig.JWT.KeyID,
+		Secret:      config.AppConfig.JWT.Secret,
+		RetiredKeys: config.AppConfig.JWT.RetiredKeys,
+		AccessTTL:   config.AppConfig.JWT.TokenTTL.Duration,
+		RefreshTTL:  config.AppConfig.JWT.RefreshTokenTTL.Duration,
+	})
+	//密码哈希算法 存量哈希在登录成功后自动升级为该算法
+	hasher, err := utils.NewPasswordHasher(config.AppConfig.Password.Algorithm)
+	if err != nil {
+		return err
+	}
+	utils.SetPasswordHasher(hasher)
+	//非debug日志级别下使用gin的release模式
+	if strings.ToLower(config.AppConfig.Log.Level) != "debug" {
+		gin.SetMode(gin.ReleaseMode)
+	}
+
+	//监听退出信号 启动阶段收到信号也会中断重试
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
 
 	//数据库初始化
-	db, err := config.InitDB()
+	db, err := config.InitDB(ctx)
 	if err != nil {
-		fmt.Printf("数据库初始化失败: %v", err)
+		return fmt.Errorf("数据库初始化失败: %w", err)
 	}
+	defer func() {
+		if err := config.CloseDB(db); err != nil {
+			log.Printf("关闭数据库连接失败: %v", err)
+		}
+		log.Println("数据库连接已关闭")
+	}()
 
 	//Redis初始化
-	redisClient, err := config.InitRedis()
+	redisClient, err := config.InitRedis(ctx)
 	if err != nil {
-		fmt.Printf("Redis初始化失败: %v", err)
+		return fmt.Errorf("Redis初始化失败: %w", err)
 	}
+	// defer 逆序执行 Redis 先于数据库关闭
+	defer func() {
+		if err := redisClient.Close(); err != nil {
+			log.Printf("关闭Redis连接失败: %v", err)
+		}
+		log.Println("Redis连接已关闭")
+	}()
+
+	//令牌服务 吊销列表保存在Redis中 刷新令牌时读取用户的当前角色
+	tokenService := servers.NewTokenService(redisClient, repositories.NewUserRepository(db))
+	//计数服务 阅读量、点赞数定期从Redis回写MySQL
+	counterService := servers.NewCounterService(repositories.NewPostRepository(db), redisClient, servers.CounterOptions{
+		FlushInterval:     config.AppConfig.Counter.FlushInterval.Duration,
+		BatchSize:         config.AppConfig.Counter.FlushBatchSize,
+		ReconcileInterval: config.AppConfig.Counter.ReconcileInterval.Duration,
+	})
+	//计数服务使用单独的上下文 HTTP服务关闭后再停止 处理中的请求产生的计数也会在最后一次回写中写入
+	counterCtx, stopCounter := context.WithCancel(context.Background())
+	counterDone := make(chan struct{})
+	go func() {
+		counterService.Run(counterCtx)
+		close(counterDone)
+	}()
+	//任何退出路径都等待最后一次回写完成 defer 逆序执行 先于Redis和数据库关闭
+	defer func() {
+		stopCounter()
+		<-counterDone
+	}()
 
 	//创建gin路由
 	r := gin.Default()
@@ -36,10 +115,38 @@ func main() {
 	// 添加自定义日志中间件
 	r.Use(middleware.Logger())
 	//添加认证中间件
-	r.Use(middleware.AuthMiddleware())
+	r.Use(middleware.AuthMiddleware(tokenService))
 	//设置路由
-	routes.SetRoutes(r, db, redisClient)
+	routes.SetRoutes(r, db, redisClient, tokenService, counterService)
+
 	//启动服务器
-	r.Run(fmt.Sprintf("%s:%s", config.AppConfig.Server.Host, config.AppConfig.Server.Port))
+	srv := &http.Server{
+		Addr:    fmt.Sprintf("%s:%s", config.AppConfig.Server.Host, config.AppConfig.Server.Port),
+		Handler: r,
+	}
+	serveErr := make(chan error, 1)
+	go func() {
+		log.Printf("服务启动，监听地址: %s", srv.Addr)
+		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			serveErr <- err
+		}
+		close(serveErr)
+	}()
 
+	select {
+	case err := <-serveErr:
+		return fmt.Errorf("启动服务失败: %w", err)
+	case <-ctx.Done():
+	}
+
+	//收到退出信号 停止接收新请求并等待处理中的请求完成
+	stop()
+	log.Println("收到退出信号，开始优雅关闭...")
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), config.AppConfig.Server.ShutdownTimeout.Duration)
+	defer cancel()
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		return fmt.Errorf("优雅关闭超时: %w", err)
+	}
+	log.Println("HTTP服务已关闭")
+	return nil
 }
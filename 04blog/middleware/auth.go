@@ -1,6 +1,7 @@
 package middleware
 
 import (
+	"04blog/servers"
 	"04blog/utils"
 	"net/http"
 	"strings"
@@ -8,10 +9,12 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
-func AuthMiddleware() gin.HandlerFunc {
+// AuthMiddleware 校验访问令牌 并拒绝已吊销（已登出）的令牌
+func AuthMiddleware(tokenService servers.TokenService) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		//排除登录和注册接口
-		if c.Request.URL.Path == "/v1/login" || c.Request.URL.Path == "/v1/register" {
+		//排除登录、注册和刷新令牌接口
+		switch c.Request.URL.Path {
+		case "/v1/login", "/v1/register", "/v1/token/refresh":
 			c.Next()
 			return
 		}
@@ -38,10 +41,26 @@ func AuthMiddleware() gin.HandlerFunc {
 			c.Abort()
 			return
 		}
+		//检查令牌是否已被吊销
+		revoked, err := tokenService.IsRevoked(c.Request.Context(), claims)
+		if err != nil {
+			c.JSON(http.StatusServiceUnavailable, gin.H{"error": "认证服务暂不可用"})
+			c.Abort()
+			return
+		}
+		if revoked {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "令牌已失效，请重新登录"})
+			c.Abort()
+			return
+		}
+		//设置令牌信息到上下文 供登出使用
+		c.Set("claims", claims)
 		//设置用户ID到上下文
 		c.Set("user_id", claims.UserID)
 		//设置用户名到上下文
 		c.Set("username", claims.Username)
+		//设置用户角色到上下文
+		c.Set("role", claims.Role)
 
 		// 授权成功，继续处理请求
 		c.Next()
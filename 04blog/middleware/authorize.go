@@ -0,0 +1,97 @@
+package middleware
+
+import (
+	"04blog/models"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// ErrInvalidResourceID 路径中的资源ID无效
+var ErrInvalidResourceID = errors.New("invalid resource id")
+
+// OwnerLookup 根据请求找到目标资源所属的用户ID
+type OwnerLookup func(c *gin.Context) (int64, error)
+
+// CurrentUserID 从上下文获取当前登录用户ID
+func CurrentUserID(c *gin.Context) (int64, bool) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		return 0, false
+	}
+	id, ok := userID.(int64)
+	return id, ok
+}
+
+// CurrentRole 从上下文获取当前登录用户角色
+func CurrentRole(c *gin.Context) int {
+	role, _ := c.Get("role")
+	r, _ := role.(int)
+	return r
+}
+
+// IsAdmin 当前登录用户是否为管理员
+func IsAdmin(c *gin.Context) bool {
+	return CurrentRole(c) == models.RoleAdmin
+}
+
+// IsOwnerOrAdmin 当前登录用户是否为资源所有者或管理员
+func IsOwnerOrAdmin(c *gin.Context, ownerID int64) bool {
+	if IsAdmin(c) {
+		return true
+	}
+	userID, ok := CurrentUserID(c)
+	return ok && userID == ownerID
+}
+
+// RequireRole 只允许指定角色访问
+func RequireRole(roles ...int) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		role := CurrentRole(c)
+		for _, r := range roles {
+			if r == role {
+				c.Next()
+				return
+			}
+		}
+		c.JSON(http.StatusForbidden, gin.H{"error": "没有权限"})
+		c.Abort()
+	}
+}
+
+// RequireOwnerOrAdmin 只允许资源所有者或管理员访问
+func RequireOwnerOrAdmin(lookup OwnerLookup) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if _, ok := CurrentUserID(c); !ok {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "未授权"})
+			c.Abort()
+			return
+		}
+		// 管理员无需查询资源
+		if IsAdmin(c) {
+			c.Next()
+			return
+		}
+		ownerID, err := lookup(c)
+		if err != nil {
+			switch {
+			case errors.Is(err, ErrInvalidResourceID):
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			case errors.Is(err, gorm.ErrRecordNotFound):
+				c.JSON(http.StatusNotFound, gin.H{"error": "资源不存在"})
+			default:
+				c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			}
+			c.Abort()
+			return
+		}
+		if !IsOwnerOrAdmin(c, ownerID) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "只能操作自己的内容"})
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
@@ -1,11 +1,36 @@
 package models
 
+import "time"
+
+// CommentStatus 评论审核状态
+type CommentStatus string
+
+const (
+	CommentStatusPending  CommentStatus = "pending"  //待审核 仅作者和管理员可见
+	CommentStatusApproved CommentStatus = "approved" //已通过 所有人可见
+	CommentStatusHidden   CommentStatus = "hidden"   //已隐藏 仅作者和管理员可见
+)
+
+// Valid 是否为合法的审核状态
+func (s CommentStatus) Valid() bool {
+	switch s {
+	case CommentStatusPending, CommentStatusApproved, CommentStatusHidden:
+		return true
+	}
+	return false
+}
+
 // Comment 评论模型
+// 顶层评论 ParentID、RootID 为0 回复的 RootID 为所在楼层的顶层评论ID
 type Comment struct {
 	BaseModel
-	Content string `json:"content" gorm:"type:text comment '评论内容';not null"`
-	UserID  int64  `json:"user_id" gorm:"index;not null;comment '评论用户ID'"` // 评论用户ID
-	PostID  int64  `json:"post_id" gorm:"index;not null;comment '评论文章ID'"` // 评论文章ID
+	Content  string        `json:"content" gorm:"type:text comment '评论内容';not null"`
+	UserID   int64         `json:"user_id" gorm:"index;not null;comment '评论用户ID'"`                                  // 评论用户ID
+	PostID   int64         `json:"post_id" gorm:"index;not null;comment '评论文章ID'"`                                  // 评论文章ID
+	ParentID int64         `json:"parent_id" gorm:"index;not null;default:0;comment '回复的评论ID'"`                     // 回复的评论ID
+	RootID   int64         `json:"root_id" gorm:"index;not null;default:0;comment '所在楼层的顶层评论ID'"`                   // 所在楼层的顶层评论ID
+	Status   CommentStatus `json:"status" gorm:"type:varchar(16);index;not null;default:'approved';comment '审核状态'"` // 审核状态
+	EditedAt *time.Time    `json:"edited_at" gorm:"comment '最后编辑时间'"`                                               // 最后编辑时间
 
 	// 关联
 	// User User `json:"user" gorm:"foreignKey:UserID"` // 评论用户
@@ -16,3 +41,16 @@ type Comment struct {
 func (Comment) TableName() string {
 	return "comments"
 }
+
+// CommentRevision 评论编辑历史 保存每次编辑前的内容
+type CommentRevision struct {
+	BaseModel
+	CommentID int64  `json:"comment_id" gorm:"index;not null;comment '评论ID'"`
+	Content   string `json:"content" gorm:"type:text comment '编辑前的内容';not null"`
+	EditorID  int64  `json:"editor_id" gorm:"not null;comment '编辑人ID'"`
+}
+
+// TableName 指定表名
+func (CommentRevision) TableName() string {
+	return "comment_revisions"
+}
@@ -1,10 +1,12 @@
 package models
 
 // Like 点赞模型
+// (user_id, post_id) 唯一 同一用户对同一文章只有一条点赞记录
+// 开启自动迁移前需先清理重复的点赞记录 否则唯一索引创建失败
 type Like struct {
 	BaseModel
-	UserID int64 `json:"user_id" gorm:"index;not null;comment '点赞用户ID'"` // 点赞用户ID
-	PostID int64 `json:"post_id" gorm:"index;not null;comment '点赞文章ID'"` // 点赞文章ID
+	UserID int64 `json:"user_id" gorm:"uniqueIndex:idx_likes_user_post,priority:1;not null;comment '点赞用户ID'"`       // 点赞用户ID
+	PostID int64 `json:"post_id" gorm:"uniqueIndex:idx_likes_user_post,priority:2;index;not null;comment '点赞文章ID'"` // 点赞文章ID
 
 	// 关联
 	// User User `json:"user" gorm:"foreignKey:UserID"` // 点赞用户
@@ -15,12 +15,18 @@ type BaseModel struct {
 	IsDeleted bool           `json:"is_deleted" gorm:"column:is_deleted"`
 }
 
+// 用户角色
+const (
+	RoleAdmin = 1 // 管理员
+	RoleUser  = 2 // 普通用户
+)
+
 // User 用户模型
 type User struct {
 	BaseModel
 	Username string     `json:"username" gorm:"index:idx_username;unique; type:varchar(20);not null"`
 	Mobile   string     `json:"mobile" gorm:"index:idx_mobile;unique; type:varchar(11);not null"`
-	Password string     `json:"password" gorm:"type:varchar(100);not null"`
+	Password string     `json:"password" gorm:"type:varchar(255);not null"`
 	Nickname string     `json:"nickname" gorm:"type:varchar(20);not null"`
 	Birthday *time.Time `json:"birthday" gorm:"type:datetime"`
 	Gender   string     `json:"gender" gorm:"column:gender;type:varchar(10) comment '性别:male 表示男性,female 表示女性';default:male"`
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"04blog/config"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"time"
+)
+
+// rebuildIndexTimeout 等待服务重建完成的最长时间
+const rebuildIndexTimeout = 5 * time.Minute
+
+// rebuildIndex 子命令 rebuild-index 调用运行中服务的 POST /v1/admin/search/rebuild
+// 搜索索引保存在服务进程内存中 只能由服务自己重建
+// 服务地址与启动服务时一样由配置文件、BLOG_* 环境变量和 -host/-port 参数决定
+// 管理员访问令牌从 BLOG_ADMIN_TOKEN 环境变量读取
+func rebuildIndex(args []string) error {
+	cfg, err := config.Load(args)
+	if err != nil {
+		return fmt.Errorf("加载配置失败: %w", err)
+	}
+	token := os.Getenv(config.EnvPrefix + "ADMIN_TOKEN")
+	if token == "" {
+		return errors.New("请通过 " + config.EnvPrefix + "ADMIN_TOKEN 环境变量提供管理员访问令牌")
+	}
+
+	url := fmt.Sprintf("http://%s:%s/v1/admin/search/rebuild", cfg.Server.Host, cfg.Server.Port)
+	req, err := http.NewRequest(http.MethodPost, url, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	resp, err := (&http.Client{Timeout: rebuildIndexTimeout}).Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	var body struct {
+		Message string `json:"message"`
+		Count   int    `json:"count"`
+		Error   string `json:"error"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return fmt.Errorf("解析响应失败(HTTP %d): %w", resp.StatusCode, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("HTTP %d: %s", resp.StatusCode, body.Error)
+	}
+	log.Printf("%s，共 %d 篇文章", body.Message, body.Count)
+	return nil
+}
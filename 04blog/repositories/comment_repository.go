@@ -2,22 +2,49 @@ package repositories
 
 import (
 	"04blog/models"
+	"errors"
+	"time"
 
 	"gorm.io/gorm"
 )
 
+// ErrCommentStatusChanged 评论状态已被并发修改
+var ErrCommentStatusChanged = errors.New("comment status changed")
+
 // 定义评论的仓库接口
 type CommentRepository interface {
 	//新增评论
 	CreateComment(comment *models.Comment) error
-	//根据文章ID获取评论
-	GetCommentsByPostID(postID int64) ([]*models.Comment, error)
-	//删除评论
-	DeleteComment(commentID int64) error
+	//分页获取文章的顶层评论
+	ListThreads(postID int64, status models.CommentStatus, page, pageSize int) (*CommentPage, error)
+	//分页获取楼层中的回复
+	ListReplies(rootID int64, status models.CommentStatus, page, pageSize int) (*CommentPage, error)
+	//统计各楼层的回复数
+	CountReplies(rootIDs []int64, status models.CommentStatus) (map[int64]int64, error)
+	//获取楼层中的全部评论 包括顶层评论
+	GetThreadComments(rootID int64) ([]*models.Comment, error)
+	//按审核状态分页获取评论
+	ListByStatus(status models.CommentStatus, page, pageSize int) (*CommentPage, error)
+	//统计文章中可见的评论数 已通过审核的顶层评论和楼层可见的已通过审核的回复
+	CountVisibleByPostID(postID int64) (int64, error)
+	//批量删除评论
+	DeleteComments(commentIDs []int64) error
 	//根据评论ID获取评论
 	GetCommentByID(commentID int64) (*models.Comment, error)
-	//更新评论
-	UpdateComment(comment *models.Comment) error
+	//编辑评论内容 保存编辑前的内容 评论状态不是 from 时返回 ErrCommentStatusChanged
+	EditComment(comment *models.Comment, from models.CommentStatus, revision *models.CommentRevision) error
+	//修改审核状态 返回状态是否从 from 变为 to
+	UpdateStatus(commentID int64, from, to models.CommentStatus) (bool, error)
+	//获取评论编辑历史
+	GetRevisions(commentID int64) ([]*models.CommentRevision, error)
+}
+
+// CommentPage 评论分页结果
+type CommentPage struct {
+	Items    []*models.Comment
+	Total    int64
+	Page     int
+	PageSize int
 }
 
 // CommentRepositoryImpl 评论仓库实现
@@ -35,16 +62,69 @@ func (c *CommentRepositoryImpl) CreateComment(comment *models.Comment) error {
 	return c.db.Create(comment).Error
 }
 
-// 根据文章ID获取评论
-func (c *CommentRepositoryImpl) GetCommentsByPostID(postID int64) ([]*models.Comment, error) {
+// 分页获取文章的顶层评论 新评论在前
+func (c *CommentRepositoryImpl) ListThreads(postID int64, status models.CommentStatus, page, pageSize int) (*CommentPage, error) {
+	db := c.db.Model(&models.Comment{}).Where("post_id = ? AND root_id = 0 AND status = ?", postID, status)
+	return c.paginate(db, "created_at DESC, id DESC", page, pageSize)
+}
+
+// 分页获取楼层中的回复 按回复时间顺序
+func (c *CommentRepositoryImpl) ListReplies(rootID int64, status models.CommentStatus, page, pageSize int) (*CommentPage, error) {
+	db := c.db.Model(&models.Comment{}).Where("root_id = ? AND status = ?", rootID, status)
+	return c.paginate(db, "created_at ASC, id ASC", page, pageSize)
+}
+
+// 统计各楼层的回复数
+func (c *CommentRepositoryImpl) CountReplies(rootIDs []int64, status models.CommentStatus) (map[int64]int64, error) {
+	counts := make(map[int64]int64, len(rootIDs))
+	if len(rootIDs) == 0 {
+		return counts, nil
+	}
+	var rows []struct {
+		RootID int64
+		Count  int64
+	}
+	err := c.db.Model(&models.Comment{}).
+		Select("root_id, COUNT(*) AS count").
+		Where("root_id IN ? AND status = ?", rootIDs, status).
+		Group("root_id").
+		Scan(&rows).Error
+	for _, row := range rows {
+		counts[row.RootID] = row.Count
+	}
+	return counts, err
+}
+
+// 获取楼层中的全部评论
+func (c *CommentRepositoryImpl) GetThreadComments(rootID int64) ([]*models.Comment, error) {
 	var comments []*models.Comment
-	err := c.db.Where("post_id = ?", postID).Find(&comments).Error
+	err := c.db.Where("id = ? OR root_id = ?", rootID, rootID).Find(&comments).Error
 	return comments, err
 }
 
-// 删除评论
-func (c *CommentRepositoryImpl) DeleteComment(commentID int64) error {
-	return c.db.Delete(&models.Comment{}, commentID).Error
+// 按审核状态分页获取评论 先提交的在前
+func (c *CommentRepositoryImpl) ListByStatus(status models.CommentStatus, page, pageSize int) (*CommentPage, error) {
+	db := c.db.Model(&models.Comment{}).Where("status = ?", status)
+	return c.paginate(db, "created_at ASC, id ASC", page, pageSize)
+}
+
+// 统计文章中可见的评论数 顶层评论未通过审核时其下的回复都不可见
+func (c *CommentRepositoryImpl) CountVisibleByPostID(postID int64) (int64, error) {
+	var count int64
+	err := c.db.Model(&models.Comment{}).
+		Joins("LEFT JOIN comments AS roots ON roots.id = comments.root_id AND roots.deleted_at IS NULL").
+		Where("comments.post_id = ? AND comments.status = ?", postID, models.CommentStatusApproved).
+		Where("comments.root_id = 0 OR roots.status = ?", models.CommentStatusApproved).
+		Count(&count).Error
+	return count, err
+}
+
+// 批量删除评论
+func (c *CommentRepositoryImpl) DeleteComments(commentIDs []int64) error {
+	if len(commentIDs) == 0 {
+		return nil
+	}
+	return c.db.Delete(&models.Comment{}, commentIDs).Error
 }
 
 // 根据评论ID获取评论
@@ -54,7 +134,61 @@ func (c *CommentRepositoryImpl) GetCommentByID(commentID int64) (*models.Comment
 	return &comment, err
 }
 
-// 更新评论
-func (c *CommentRepositoryImpl) UpdateComment(comment *models.Comment) error {
-	return c.db.Save(comment).Error
+// 编辑评论 在同一事务中保存历史和新内容
+func (c *CommentRepositoryImpl) EditComment(comment *models.Comment, from models.CommentStatus, revision *models.CommentRevision) error {
+	return c.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(revision).Error; err != nil {
+			return err
+		}
+		now := time.Now()
+		result := tx.Model(&models.Comment{}).
+			Where("id = ? AND status = ?", comment.ID, from).
+			Updates(map[string]interface{}{
+				"content":   comment.Content,
+				"status":    comment.Status,
+				"edited_at": now,
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrCommentStatusChanged
+		}
+		comment.EditedAt = &now
+		return nil
+	})
+}
+
+// 修改审核状态 只有当前状态为 from 时才修改
+func (c *CommentRepositoryImpl) UpdateStatus(commentID int64, from, to models.CommentStatus) (bool, error) {
+	result := c.db.Model(&models.Comment{}).
+		Where("id = ? AND status = ?", commentID, from).
+		Update("status", to)
+	return result.RowsAffected > 0, result.Error
+}
+
+// 获取评论编辑历史 最近的编辑在前
+func (c *CommentRepositoryImpl) GetRevisions(commentID int64) ([]*models.CommentRevision, error) {
+	var revisions []*models.CommentRevision
+	err := c.db.Where("comment_id = ?", commentID).Order("id DESC").Find(&revisions).Error
+	return revisions, err
+}
+
+// paginate 统计总数并取出指定页
+func (c *CommentRepositoryImpl) paginate(db *gorm.DB, order string, page, pageSize int) (*CommentPage, error) {
+	if page <= 0 {
+		page = 1
+	}
+	if pageSize <= 0 {
+		pageSize = DefaultPageSize
+	}
+	if pageSize > MaxPageSize {
+		pageSize = MaxPageSize
+	}
+	result := &CommentPage{Page: page, PageSize: pageSize}
+	if err := db.Count(&result.Total).Error; err != nil {
+		return nil, err
+	}
+	err := db.Order(order).Offset((page - 1) * pageSize).Limit(pageSize).Find(&result.Items).Error
+	return result, err
 }
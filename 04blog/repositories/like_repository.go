@@ -4,18 +4,21 @@ import (
 	"04blog/models"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // LikeRepository 点赞仓库接口
 type LikeRepository interface {
-	// Create 创建点赞
-	Create(like *models.Like) error
-	// Delete 删除点赞
-	Delete(like *models.Like) error
+	// Like 点赞 已点赞时不做修改 返回是否新增了点赞
+	Like(userID, postID int64) (bool, error)
+	// Unlike 取消点赞 未点赞时不做修改 返回是否删除了点赞
+	Unlike(userID, postID int64) (bool, error)
+	// Exists 用户是否已点赞
+	Exists(userID, postID int64) (bool, error)
 	// GetByPostID 根据帖子ID获取点赞列表
 	GetByPostID(postID int64) ([]*models.Like, error)
-	// GetByUserIDAndPostID 根据用户ID和帖子ID获取点赞
-	GetByUserIDAndPostID(userID, postID int64) (*models.Like, error)
+	// GetUserIDsByPostID 获取点赞了文章的用户ID
+	GetUserIDsByPostID(postID int64) ([]int64, error)
 }
 
 type LikeRepositoryImpl struct {
@@ -27,14 +30,29 @@ func NewLikeRepositoryImpl(db *gorm.DB) *LikeRepositoryImpl {
 	return &LikeRepositoryImpl{db: db}
 }
 
-// Create 点赞
-func (r *LikeRepositoryImpl) Create(like *models.Like) error {
-	return r.db.Create(like).Error
+// Like 依靠 (user_id, post_id) 唯一索引保证并发点赞只生效一次
+// 唯一索引冲突时恢复早期软删除的记录 记录本来就有效时影响行数为0
+func (r *LikeRepositoryImpl) Like(userID, postID int64) (bool, error) {
+	like := models.Like{UserID: userID, PostID: postID}
+	result := r.db.Clauses(clause.OnConflict{
+		DoUpdates: clause.Assignments(map[string]interface{}{"deleted_at": nil}),
+	}).Create(&like)
+	return result.RowsAffected > 0, result.Error
 }
 
-// Delete 删除点赞
-func (r *LikeRepositoryImpl) Delete(like *models.Like) error {
-	return r.db.Where("user_id = ? AND post_id = ?", like.UserID, like.PostID).Delete(&models.Like{}).Error
+// Unlike 物理删除点赞记录 避免软删除的记录占用唯一索引
+func (r *LikeRepositoryImpl) Unlike(userID, postID int64) (bool, error) {
+	result := r.db.Unscoped().
+		Where("user_id = ? AND post_id = ? AND deleted_at IS NULL", userID, postID).
+		Delete(&models.Like{})
+	return result.RowsAffected > 0, result.Error
+}
+
+// Exists 用户是否已点赞
+func (r *LikeRepositoryImpl) Exists(userID, postID int64) (bool, error) {
+	var count int64
+	err := r.db.Model(&models.Like{}).Where("user_id = ? AND post_id = ?", userID, postID).Count(&count).Error
+	return count > 0, err
 }
 
 // GetByPostID 根据帖子ID获取点赞列表
@@ -44,9 +62,9 @@ func (r *LikeRepositoryImpl) GetByPostID(postID int64) ([]*models.Like, error) {
 	return likes, err
 }
 
-// GetByUserIDAndPostID 根据用户ID和帖子ID获取点赞
-func (r *LikeRepositoryImpl) GetByUserIDAndPostID(userID, postID int64) (*models.Like, error) {
-	var like models.Like
-	err := r.db.Where("user_id = ? AND post_id = ?", userID, postID).First(&like).Error
-	return &like, err
+// GetUserIDsByPostID 获取点赞了文章的用户ID
+func (r *LikeRepositoryImpl) GetUserIDsByPostID(postID int64) ([]int64, error) {
+	var userIDs []int64
+	err := r.db.Model(&models.Like{}).Where("post_id = ?", postID).Pluck("user_id", &userIDs).Error
+	return userIDs, err
 }
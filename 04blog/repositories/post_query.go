@@ -0,0 +1,131 @@
+package repositories
+
+import (
+	"04blog/models"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+// PostSortField 文章列表排序字段
+type PostSortField string
+
+const (
+	PostSortCreatedAt PostSortField = "created_at"
+	PostSortViewCount PostSortField = "view_count"
+	PostSortLikeCount PostSortField = "like_count"
+)
+
+// 分页默认值
+const (
+	DefaultPageSize = 10
+	MaxPageSize     = 100
+)
+
+// ErrInvalidCursor 游标无法解析或与排序字段不匹配
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// postSortColumns 允许排序的字段 防止拼接任意列名
+var postSortColumns = map[PostSortField]string{
+	PostSortCreatedAt: "created_at",
+	PostSortViewCount: "view_count",
+	PostSortLikeCount: "like_count",
+}
+
+// ValidPostSortField 判断排序字段是否合法
+func ValidPostSortField(f PostSortField) bool {
+	_, ok := postSortColumns[f]
+	return ok
+}
+
+// PostQuery 文章列表查询条件
+// Cursor 不为空时使用游标分页 忽略 Page
+type PostQuery struct {
+	Title       string     //标题 精确匹配
+	Content     string     //内容 模糊匹配
+	UserID      int64      //作者ID
+	CreatedFrom *time.Time //创建时间下限（包含）
+	CreatedTo   *time.Time //创建时间上限（不包含）
+
+	SortBy PostSortField //排序字段 默认 created_at
+	Desc   bool          //是否倒序
+
+	Page     int    //页码 从1开始
+	PageSize int    //每页条数
+	Cursor   string //上一页返回的 NextCursor
+}
+
+// Normalize 填充默认值
+func (q *PostQuery) Normalize() {
+	if q.SortBy == "" {
+		q.SortBy = PostSortCreatedAt
+	}
+	if q.Page <= 0 {
+		q.Page = 1
+	}
+	if q.PageSize <= 0 {
+		q.PageSize = DefaultPageSize
+	}
+	if q.PageSize > MaxPageSize {
+		q.PageSize = MaxPageSize
+	}
+}
+
+// PostPage 文章分页结果
+type PostPage struct {
+	Items      []*models.Post
+	Total      int64
+	Page       int
+	PageSize   int
+	HasMore    bool
+	NextCursor string
+}
+
+// postCursor 游标内容 记录最后一条数据的排序值和ID
+type postCursor struct {
+	SortBy PostSortField `json:"s"`
+	Desc   bool          `json:"d"`
+	Time   *time.Time    `json:"t,omitempty"`
+	Count  int           `json:"c,omitempty"`
+	ID     int64         `json:"i"`
+}
+
+// encodePostCursor 根据最后一条数据生成游标
+func encodePostCursor(q *PostQuery, last *models.Post) string {
+	c := postCursor{SortBy: q.SortBy, Desc: q.Desc, ID: last.ID}
+	switch q.SortBy {
+	case PostSortCreatedAt:
+		t := last.CreatedAt
+		c.Time = &t
+	case PostSortViewCount:
+		c.Count = last.ViewCount
+	case PostSortLikeCount:
+		c.Count = last.LikeCount
+	}
+	b, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// decodePostCursor 解析游标并返回排序值
+func decodePostCursor(q *PostQuery) (interface{}, int64, error) {
+	b, err := base64.RawURLEncoding.DecodeString(q.Cursor)
+	if err != nil {
+		return nil, 0, ErrInvalidCursor
+	}
+	var c postCursor
+	if err := json.Unmarshal(b, &c); err != nil {
+		return nil, 0, ErrInvalidCursor
+	}
+	// 游标必须与本次查询的排序方式一致
+	if c.SortBy != q.SortBy || c.Desc != q.Desc {
+		return nil, 0, ErrInvalidCursor
+	}
+	if c.SortBy == PostSortCreatedAt {
+		if c.Time == nil {
+			return nil, 0, ErrInvalidCursor
+		}
+		return *c.Time, c.ID, nil
+	}
+	return c.Count, c.ID, nil
+}
@@ -2,6 +2,7 @@ package repositories
 
 import (
 	"04blog/models"
+	"fmt"
 
 	"gorm.io/gorm"
 )
@@ -12,12 +13,33 @@ type PostRepository interface {
 	CreatePost(post *models.Post) error
 	// 获取文章
 	GetPost(postID int64) (*models.Post, error)
-	//多条件查询文章 不传入就是查询所有
-	GetPostsByConditions(conditions map[string]interface{}) ([]*models.Post, error)
+	// 分页查询文章 支持过滤、排序、偏移分页和游标分页
+	ListPosts(query *PostQuery) (*PostPage, error)
 	// 删除文章
 	DeletePost(postID int64) error
-	// 更新文章
+	// 更新文章的标题、内容、摘要和封面
 	UpdatePost(post *models.Post) error
+	// 批量回写阅读量、点赞数 不修改更新时间
+	UpdateCounters(counters []PostCounters) error
+	// 按 likes 表统计 afterID 之后的 limit 篇文章的实际点赞数
+	RecountLikes(afterID int64, limit int) ([]PostLikeCount, error)
+}
+
+// postContentColumns UpdatePost 更新的列
+var postContentColumns = []string{"title", "content", "summary", "cover", "updated_at"}
+
+// PostCounters 待回写的文章计数 为 nil 的字段不更新
+type PostCounters struct {
+	PostID    int64
+	ViewCount *int64
+	LikeCount *int64
+}
+
+// PostLikeCount 文章表中的点赞数与点赞表实际数量
+type PostLikeCount struct {
+	PostID int64
+	Stored int64 //posts.like_count
+	Actual int64 //likes 表中的记录数
 }
 
 type PostRepositoryImpl struct {
@@ -43,26 +65,118 @@ func (p *PostRepositoryImpl) GetPost(postID int64) (*models.Post, error) {
 	return &post, nil
 }
 
-// GetPostsByConditions 多条件查询文章
-func (p *PostRepositoryImpl) GetPostsByConditions(conditions map[string]interface{}) ([]*models.Post, error) {
-	var posts []*models.Post
+// ListPosts 分页查询文章
+func (p *PostRepositoryImpl) ListPosts(query *PostQuery) (*PostPage, error) {
+	query.Normalize()
+	column, ok := postSortColumns[query.SortBy]
+	if !ok {
+		return nil, fmt.Errorf("unsupported sort field %q", query.SortBy)
+	}
+
+	//过滤条件
+	db := p.db.Model(&models.Post{})
+	if query.Title != "" {
+		db = db.Where("title = ?", query.Title)
+	}
 	//内容模糊查询
-	if content, ok := conditions["content"]; ok {
-		conditions["content"] = gorm.Expr("content LIKE ?", "%"+content.(string)+"%")
+	if query.Content != "" {
+		db = db.Where("content LIKE ?", "%"+query.Content+"%")
+	}
+	if query.UserID > 0 {
+		db = db.Where("user_id = ?", query.UserID)
+	}
+	if query.CreatedFrom != nil {
+		db = db.Where("created_at >= ?", *query.CreatedFrom)
 	}
-	if err := p.db.Where(conditions).Find(&posts).Error; err != nil {
+	if query.CreatedTo != nil {
+		db = db.Where("created_at < ?", *query.CreatedTo)
+	}
+
+	//总数不受分页影响
+	page := &PostPage{Page: query.Page, PageSize: query.PageSize}
+	if err := db.Count(&page.Total).Error; err != nil {
 		return nil, err
 	}
-	return posts, nil
+
+	//排序 相同排序值时按ID保证顺序稳定
+	direction, cmp := "ASC", ">"
+	if query.Desc {
+		direction, cmp = "DESC", "<"
+	}
+	db = db.Order(fmt.Sprintf("%s %s, id %s", column, direction, direction))
+
+	if query.Cursor != "" {
+		//游标分页 从上一页最后一条之后开始
+		value, lastID, err := decodePostCursor(query)
+		if err != nil {
+			return nil, err
+		}
+		db = db.Where(fmt.Sprintf("(%s %s ?) OR (%s = ? AND id %s ?)", column, cmp, column, cmp), value, value, lastID)
+	} else {
+		db = db.Offset((query.Page - 1) * query.PageSize)
+	}
+
+	//多取一条判断是否还有下一页
+	var posts []*models.Post
+	if err := db.Limit(query.PageSize + 1).Find(&posts).Error; err != nil {
+		return nil, err
+	}
+	if len(posts) > query.PageSize {
+		posts = posts[:query.PageSize]
+		page.HasMore = true
+	}
+	page.Items = posts
+	if page.HasMore {
+		page.NextCursor = encodePostCursor(query, posts[len(posts)-1])
+	}
+	return page, nil
 }
 
 // UpdatePost 更新文章
 func (p *PostRepositoryImpl) UpdatePost(post *models.Post) error {
-	//根据id更新文章
-	return p.db.Model(post).Updates(post).Error
+	//根据id更新文章 作者和计数不随文章内容修改
+	return p.db.Model(&models.Post{BaseModel: models.BaseModel{ID: post.ID}}).
+		Select(postContentColumns).Updates(post).Error
 }
 
 // DeletePost 删除文章
 func (p *PostRepositoryImpl) DeletePost(postID int64) error {
 	return p.db.Delete(&models.Post{}, postID).Error
 }
+
+// UpdateCounters 在一个事务中批量回写计数
+func (p *PostRepositoryImpl) UpdateCounters(counters []PostCounters) error {
+	return p.db.Transaction(func(tx *gorm.DB) error {
+		for _, c := range counters {
+			columns := map[string]interface{}{}
+			if c.ViewCount != nil {
+				columns["view_count"] = *c.ViewCount
+			}
+			if c.LikeCount != nil {
+				columns["like_count"] = *c.LikeCount
+			}
+			if len(columns) == 0 {
+				continue
+			}
+			//UpdateColumns 不触发 updated_at 自动更新
+			if err := tx.Model(&models.Post{}).Where("id = ?", c.PostID).UpdateColumns(columns).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// RecountLikes 统计文章实际点赞数 按文章ID升序
+func (p *PostRepositoryImpl) RecountLikes(afterID int64, limit int) ([]PostLikeCount, error) {
+	var counts []PostLikeCount
+	err := p.db.Table("posts").
+		Select("posts.id AS post_id, posts.like_count AS stored, COUNT(likes.id) AS actual").
+		Joins("LEFT JOIN likes ON likes.post_id = posts.id AND likes.deleted_at IS NULL").
+		Where("posts.deleted_at IS NULL AND posts.id > ?", afterID).
+		Group("posts.id, posts.like_count").
+		Order("posts.id").
+		Limit(limit).
+		Scan(&counts).Error
+	return counts, err
+}
@@ -19,6 +19,8 @@ type UserRepository interface {
 	GetUserByMobile(mobile string) (*models.User, error)
 	// UpdateUser 更新用户信息
 	UpdateUser(user *models.User) error
+	// UpdatePassword 更新用户密码哈希
+	UpdatePassword(userID int64, passwordHash string) error
 	// DeleteUser 删除用户
 	DeleteUser(userID int64) error
 	// GetUserByEmail 根据邮箱获取用户
@@ -65,6 +67,11 @@ func (u *userRepository) UpdateUser(user *models.User) error {
 	return u.db.Save(user).Error
 }
 
+// UpdatePassword 更新用户密码哈希
+func (u *userRepository) UpdatePassword(userID int64, passwordHash string) error {
+	return u.db.Model(&models.User{}).Where("id = ?", userID).Update("password", passwordHash).Error
+}
+
 // DeleteUser 删除用户
 func (u *userRepository) DeleteUser(userID int64) error {
 	return u.db.Delete(&models.User{}, userID).Error
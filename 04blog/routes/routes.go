@@ -2,8 +2,13 @@ package routes
 
 import (
 	"04blog/api"
+	"04blog/config"
+	"04blog/middleware"
+	"04blog/models"
 	"04blog/repositories"
 	"04blog/servers"
+	"context"
+	"log"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
@@ -12,7 +17,7 @@ import (
 )
 
 // gin routes 设置
-func SetRoutes(r *gin.Engine, db *gorm.DB, redisClient *redis.Client) {
+func SetRoutes(r *gin.Engine, db *gorm.DB, redisClient *redis.Client, tokenService servers.TokenService, counterService servers.CounterService) {
 	//后续接口路由
 	//添加一个hello路由
 	r.GET("/hello", func(c *gin.Context) {
@@ -23,19 +28,31 @@ func SetRoutes(r *gin.Engine, db *gorm.DB, redisClient *redis.Client) {
 	userDao := repositories.NewUserRepository(db)
 	userService := servers.NewUserService(userDao, redisClient)
 	userApi := api.NewUserAPI(userService)
+	tokenApi := api.NewTokenAPI(tokenService)
 
 	//文章相关
 	postDao := repositories.NewPostRepository(db)
-	postService := servers.NewPostService(postDao, redisClient)
+	searchService := servers.NewSearchService(postDao)
+	postService := servers.NewPostService(postDao, counterService, searchService)
 	postApi := api.NewPostAPI(postService)
+	searchApi := api.NewSearchAPI(searchService)
+	//启动时后台加载已有文章到搜索索引 加载完成前搜索结果不完整
+	go func() {
+		count, err := searchService.Rebuild(context.Background())
+		if err != nil {
+			log.Printf("搜索索引构建失败: %v", err)
+			return
+		}
+		log.Printf("搜索索引构建完成，共 %d 篇文章", count)
+	}()
 
 	//评论相关
 	commentDao := repositories.NewCommentRepository(db)
-	commentService := servers.NewCommentService(commentDao, redisClient)
+	commentService := servers.NewCommentService(commentDao, redisClient, config.AppConfig.Comment.RequireApproval)
 	commApi := api.NewCommentAPI(commentService)
 	//点赞相关
 	likeDao := repositories.NewLikeRepositoryImpl(db)
-	likeService := servers.NewLikeServiceImpl(likeDao, redisClient)
+	likeService := servers.NewLikeServiceImpl(likeDao, counterService, redisClient)
 	likeApi := api.NewLikeAPI(likeService)
 
 	// 设置v1路由组
@@ -45,22 +62,36 @@ func SetRoutes(r *gin.Engine, db *gorm.DB, redisClient *redis.Client) {
 		v1.POST("/register", userApi.RegisterUser)
 		v1.POST("/login", userApi.LoginUser)
 		v1.GET("/user", userApi.GetCurrentUser)
+		v1.POST("/token/refresh", tokenApi.Refresh)
+		v1.POST("/logout", tokenApi.Logout)
 
 		//文章路由
 		v1.POST("/post", postApi.SavePost)
 		v1.GET("/posts", postApi.GetPosts)
 		v1.GET("/post/:id", postApi.GetPost)
-		v1.DELETE("/post/:id", postApi.DeletePost)
+		v1.DELETE("/post/:id", middleware.RequireOwnerOrAdmin(postApi.PostOwner), postApi.DeletePost)
+		//搜索路由
+		v1.GET("/search", searchApi.Search)
+		v1.POST("/admin/search/rebuild", middleware.RequireRole(models.RoleAdmin), searchApi.RebuildIndex)
 		//评论路由
 		v1.POST("/comment", commApi.CreateComment)
 		v1.GET("/comments/:postID", commApi.GetCommentsByPostID)
-		v1.DELETE("/comment/:commentID", commApi.DeleteComment)
+		v1.GET("/comments/:postID/count", commApi.GetCommentCount)
+		v1.DELETE("/comment/:commentID", middleware.RequireOwnerOrAdmin(commApi.CommentOwner), commApi.DeleteComment)
+		v1.PUT("/comment/:commentID", middleware.RequireOwnerOrAdmin(commApi.CommentOwner), commApi.EditComment)
 		v1.GET("/comment/:commentID", commApi.GetCommentByID)
+		v1.GET("/comment/:commentID/replies", commApi.GetReplies)
+		v1.GET("/comment/:commentID/revisions", middleware.RequireOwnerOrAdmin(commApi.CommentOwner), commApi.GetRevisions)
+		//评论审核 仅管理员
+		v1.GET("/admin/comments", middleware.RequireRole(models.RoleAdmin), commApi.GetModerationQueue)
+		v1.PUT("/admin/comment/:commentID/status", middleware.RequireRole(models.RoleAdmin), commApi.ModerateComment)
 
 		//点赞路由
 		v1.POST("/like", likeApi.Create)
 		v1.DELETE("/like", likeApi.Delete)
 		v1.GET("/likes/:postID", likeApi.GetByArticleID)
+		v1.GET("/likes/:postID/me", likeApi.GetMyState)
+		v1.PUT("/likes/:postID/me", likeApi.SetMyState)
 	}
 
 }
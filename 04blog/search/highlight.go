@@ -0,0 +1,102 @@
+package search
+
+import (
+	"html"
+	"strings"
+	"unicode/utf8"
+)
+
+// 高亮标签
+const (
+	highlightOpen  = "<em>"
+	highlightClose = "</em>"
+	ellipsis       = "..."
+)
+
+// snippetLead 摘要中第一个命中词之前最多保留的字数 不超过摘要长度的四分之一
+const snippetLead = 20
+
+type span struct{ start, end int }
+
+// Highlight 用 <em> 标记 text 中命中查询的片段 原文会做 HTML 转义
+// maxRunes 大于0时截取第一个命中位置附近的 maxRunes 个字作为摘要
+func Highlight(text, query string, maxRunes int) string {
+	queryTerms := make(map[string]struct{})
+	for _, term := range Terms(TokenizeQuery(query)) {
+		queryTerms[term] = struct{}{}
+	}
+	spans := matchSpans(text, queryTerms)
+
+	start, end := 0, len(text)
+	if maxRunes > 0 && utf8.RuneCountInString(text) > maxRunes {
+		anchor := 0
+		if len(spans) > 0 {
+			anchor = spans[0].start
+		}
+		start = backRunes(text, anchor, min(snippetLead, maxRunes/4))
+		end = forwardRunes(text, start, maxRunes)
+		// 命中位置靠近结尾时向前补足长度
+		if end == len(text) {
+			start = backRunes(text, end, maxRunes)
+		}
+	}
+
+	var b strings.Builder
+	if start > 0 {
+		b.WriteString(ellipsis)
+	}
+	pos := start
+	for _, s := range spans {
+		if s.end <= start || s.start >= end {
+			continue
+		}
+		from, to := max(s.start, start), min(s.end, end)
+		b.WriteString(html.EscapeString(text[pos:from]))
+		b.WriteString(highlightOpen)
+		b.WriteString(html.EscapeString(text[from:to]))
+		b.WriteString(highlightClose)
+		pos = to
+	}
+	b.WriteString(html.EscapeString(text[pos:end]))
+	if end < len(text) {
+		b.WriteString(ellipsis)
+	}
+	return b.String()
+}
+
+// matchSpans 返回命中词在原文中的位置 相邻或重叠的位置会合并
+func matchSpans(text string, terms map[string]struct{}) []span {
+	var spans []span
+	for _, t := range TokenizeDocument(text) {
+		if _, ok := terms[t.Term]; !ok {
+			continue
+		}
+		// 分词结果按起始位置有序 只需与最后一段比较
+		if n := len(spans); n > 0 && t.Start <= spans[n-1].end {
+			if t.End > spans[n-1].end {
+				spans[n-1].end = t.End
+			}
+			continue
+		}
+		spans = append(spans, span{start: t.Start, end: t.End})
+	}
+	return spans
+}
+
+// backRunes 从字节位置 pos 向前移动 n 个字
+func backRunes(text string, pos, n int) int {
+	for ; n > 0 && pos > 0; n-- {
+		_, size := utf8.DecodeLastRuneInString(text[:pos])
+		pos -= size
+	}
+	return pos
+}
+
+// forwardRunes 从字节位置 pos 向后移动 n 个字
+func forwardRunes(text string, pos, n int) int {
+	for ; n > 0 && pos < len(text); n-- {
+		_, size := utf8.DecodeRuneInString(text[pos:])
+		pos += size
+	}
+	return pos
+}
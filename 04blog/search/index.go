@@ -0,0 +1,236 @@
+package search
+
+import (
+	"math"
+	"sort"
+	"sync"
+)
+
+// BM25 参数
+const (
+	bm25K1 = 1.2
+	bm25B  = 0.75
+	// titleBoost 标题中出现的词权重更高
+	titleBoost = 2.0
+)
+
+// Document 待索引的文档
+type Document struct {
+	ID      int64
+	Title   string
+	Content string
+}
+
+// Hit 搜索命中结果
+type Hit struct {
+	Document
+	Score float64
+}
+
+// indexedDoc 索引中保存的文档 记录词频用于删除
+type indexedDoc struct {
+	doc    Document
+	terms  map[string]float64
+	length float64
+}
+
+// Index 内存倒排索引 并发安全
+type Index struct {
+	mu       sync.RWMutex
+	postings map[string]map[int64]float64 //词项 -> 文档ID -> 加权词频
+	docs     map[int64]*indexedDoc
+	totalLen float64
+	// 进行中的重建 期间的写入同时记录到每个重建中
+	rebuilds map[*Rebuild]struct{}
+}
+
+// Rebuild 一次全量重建 由 BeginRebuild 创建
+// 读取文档期间索引照常更新 Commit 时把这些写入重放到新索引 不会丢失
+type Rebuild struct {
+	idx *Index
+	ops []rebuildOp
+}
+
+// rebuildOp 重建期间的一次写入 entry 为 nil 表示删除
+type rebuildOp struct {
+	id    int64
+	entry *indexedDoc
+}
+
+// NewIndex 创建空索引
+func NewIndex() *Index {
+	return &Index{
+		postings: make(map[string]map[int64]float64),
+		docs:     make(map[int64]*indexedDoc),
+		rebuilds: make(map[*Rebuild]struct{}),
+	}
+}
+
+// Len 已索引的文档数量
+func (idx *Index) Len() int {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+	return len(idx.docs)
+}
+
+// Add 添加或更新文档
+func (idx *Index) Add(doc Document) {
+	entry := analyze(doc)
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	idx.remove(doc.ID)
+	idx.add(entry)
+	idx.record(rebuildOp{id: doc.ID, entry: entry})
+}
+
+// Remove 删除文档 文档不存在时忽略
+func (idx *Index) Remove(id int64) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	idx.remove(id)
+	idx.record(rebuildOp{id: id})
+}
+
+// BeginRebuild 开始全量重建 必须在读取文档之前调用 结束时调用 Commit 或 Abort
+func (idx *Index) BeginRebuild() *Rebuild {
+	r := &Rebuild{idx: idx}
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	idx.rebuilds[r] = struct{}{}
+	return r
+}
+
+// Commit 用读取到的文档整体替换索引内容 重建期间查询仍使用旧数据
+// 替换前按顺序重放 BeginRebuild 之后的写入 已经 Commit 或 Abort 时忽略
+func (r *Rebuild) Commit(docs []Document) {
+	fresh := NewIndex()
+	for _, doc := range docs {
+		fresh.add(analyze(doc))
+	}
+	idx := r.idx
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	if _, ok := idx.rebuilds[r]; !ok {
+		return
+	}
+	delete(idx.rebuilds, r)
+	for _, op := range r.ops {
+		fresh.remove(op.id)
+		if op.entry != nil {
+			fresh.add(op.entry)
+		}
+	}
+	idx.postings, idx.docs, idx.totalLen = fresh.postings, fresh.docs, fresh.totalLen
+}
+
+// Abort 放弃重建 索引保持不变
+func (r *Rebuild) Abort() {
+	r.idx.mu.Lock()
+	defer r.idx.mu.Unlock()
+	delete(r.idx.rebuilds, r)
+}
+
+// Search 按 BM25 得分降序返回第 offset 条起的 limit 条结果和命中总数
+func (idx *Index) Search(query string, offset, limit int) ([]Hit, int) {
+	terms := Terms(TokenizeQuery(query))
+	if len(terms) == 0 {
+		return nil, 0
+	}
+
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+	n := float64(len(idx.docs))
+	if n == 0 {
+		return nil, 0
+	}
+	avgLen := idx.totalLen / n
+
+	scores := make(map[int64]float64)
+	for _, term := range terms {
+		posting := idx.postings[term]
+		if len(posting) == 0 {
+			continue
+		}
+		df := float64(len(posting))
+		idf := math.Log(1 + (n-df+0.5)/(df+0.5))
+		for id, tf := range posting {
+			norm := bm25K1 * (1 - bm25B + bm25B*idx.docs[id].length/avgLen)
+			scores[id] += idf * tf * (bm25K1 + 1) / (tf + norm)
+		}
+	}
+
+	hits := make([]Hit, 0, len(scores))
+	for id, score := range scores {
+		hits = append(hits, Hit{Document: idx.docs[id].doc, Score: score})
+	}
+	// 得分相同时新文章在前 保证分页稳定
+	sort.Slice(hits, func(i, j int) bool {
+		if hits[i].Score != hits[j].Score {
+			return hits[i].Score > hits[j].Score
+		}
+		return hits[i].ID > hits[j].ID
+	})
+
+	total := len(hits)
+	if offset >= total {
+		return nil, total
+	}
+	end := offset + limit
+	if limit <= 0 || end > total {
+		end = total
+	}
+	return hits[offset:end], total
+}
+
+// analyze 对文档分词并统计加权词频
+func analyze(doc Document) *indexedDoc {
+	entry := &indexedDoc{doc: doc, terms: make(map[string]float64)}
+	for _, t := range TokenizeDocument(doc.Title) {
+		entry.terms[t.Term] += titleBoost
+		entry.length += titleBoost
+	}
+	for _, t := range TokenizeDocument(doc.Content) {
+		entry.terms[t.Term]++
+		entry.length++
+	}
+	return entry
+}
+
+// add 调用方需持有写锁
+func (idx *Index) add(entry *indexedDoc) {
+	id := entry.doc.ID
+	for term, tf := range entry.terms {
+		posting, ok := idx.postings[term]
+		if !ok {
+			posting = make(map[int64]float64)
+			idx.postings[term] = posting
+		}
+		posting[id] = tf
+	}
+	idx.docs[id] = entry
+	idx.totalLen += entry.length
+}
+
+// record 把写入记录到进行中的重建 调用方需持有写锁
+func (idx *Index) record(op rebuildOp) {
+	for r := range idx.rebuilds {
+		r.ops = append(r.ops, op)
+	}
+}
+
+// remove 调用方需持有写锁
+func (idx *Index) remove(id int64) {
+	entry, ok := idx.docs[id]
+	if !ok {
+		return
+	}
+	for term := range entry.terms {
+		posting := idx.postings[term]
+		delete(posting, id)
+		if len(posting) == 0 {
+			delete(idx.postings, term)
+		}
+	}
+	delete(idx.docs, id)
+	idx.totalLen -= entry.length
+}
@@ -0,0 +1,145 @@
+package search
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Token 分词结果 Start/End 为原文中的字节偏移
+type Token struct {
+	Term  string
+	Start int
+	End   int
+}
+
+// maxTermLen 超长单词截断后的最大字节数 避免异常数据撑大索引
+const maxTermLen = 64
+
+// stopWords 常见的无意义词 不进入索引
+var stopWords = map[string]struct{}{
+	"a": {}, "an": {}, "and": {}, "are": {}, "as": {}, "at": {}, "be": {}, "by": {},
+	"for": {}, "in": {}, "is": {}, "it": {}, "of": {}, "on": {}, "or": {}, "the": {},
+	"to": {}, "was": {}, "with": {},
+	"的": {}, "了": {}, "是": {}, "在": {}, "和": {}, "也": {}, "就": {}, "都": {},
+}
+
+// isCJK 中日韩文字 没有空格分隔 需要按字切分
+func isCJK(r rune) bool {
+	return unicode.Is(unicode.Han, r) ||
+		unicode.Is(unicode.Hiragana, r) ||
+		unicode.Is(unicode.Katakana, r) ||
+		unicode.Is(unicode.Hangul, r)
+}
+
+// isWordRune 英文、数字等以空格分隔的文字
+func isWordRune(r rune) bool {
+	return !isCJK(r) && (unicode.IsLetter(r) || unicode.IsDigit(r))
+}
+
+// TokenizeDocument 文档分词
+// 英文按单词切分并转小写 中文连续片段同时输出单字和二元组
+// 单字保证单个汉字的查询也能命中 二元组保证多字查询的精确度
+func TokenizeDocument(text string) []Token {
+	return tokenize(text, true)
+}
+
+// TokenizeQuery 查询分词
+// 中文连续片段只输出二元组 只有一个字时输出单字
+func TokenizeQuery(text string) []Token {
+	return tokenize(text, false)
+}
+
+// Terms 返回去重后的词项
+func Terms(tokens []Token) []string {
+	seen := make(map[string]struct{}, len(tokens))
+	terms := make([]string, 0, len(tokens))
+	for _, t := range tokens {
+		if _, ok := seen[t.Term]; ok {
+			continue
+		}
+		seen[t.Term] = struct{}{}
+		terms = append(terms, t.Term)
+	}
+	return terms
+}
+
+// cjkRune 中文片段中的单个字及其偏移
+type cjkRune struct {
+	r     rune
+	start int
+	end   int
+}
+
+func tokenize(text string, withUnigrams bool) []Token {
+	var tokens []Token
+	wordStart := -1
+	var run []cjkRune
+
+	flushWord := func(end int) {
+		if wordStart < 0 {
+			return
+		}
+		term := strings.ToLower(text[wordStart:end])
+		if len(term) > maxTermLen {
+			//在字符边界截断 非 ASCII 的字母是多字节的
+			cut := maxTermLen
+			for cut > 0 && !utf8.RuneStart(term[cut]) {
+				cut--
+			}
+			term = term[:cut]
+		}
+		if _, stop := stopWords[term]; !stop {
+			tokens = append(tokens, Token{Term: term, Start: wordStart, End: end})
+		}
+		wordStart = -1
+	}
+	flushRun := func() {
+		switch {
+		case len(run) == 0:
+			return
+		case len(run) == 1:
+			term := string(run[0].r)
+			if _, stop := stopWords[term]; !stop {
+				tokens = append(tokens, Token{Term: term, Start: run[0].start, End: run[0].end})
+			}
+		default:
+			for i := range run {
+				if withUnigrams {
+					term := string(run[i].r)
+					if _, stop := stopWords[term]; !stop {
+						tokens = append(tokens, Token{Term: term, Start: run[i].start, End: run[i].end})
+					}
+				}
+				if i+1 < len(run) {
+					tokens = append(tokens, Token{
+						Term:  string([]rune{run[i].r, run[i+1].r}),
+						Start: run[i].start,
+						End:   run[i+1].end,
+					})
+				}
+			}
+		}
+		run = run[:0]
+	}
+
+	for i, r := range text {
+		size := utf8.RuneLen(r)
+		switch {
+		case isCJK(r):
+			flushWord(i)
+			run = append(run, cjkRune{r: r, start: i, end: i + size})
+		case isWordRune(r):
+			flushRun()
+			if wordStart < 0 {
+				wordStart = i
+			}
+		default:
+			flushWord(i)
+			flushRun()
+		}
+	}
+	flushWord(len(text))
+	flushRun()
+	return tokens
+}
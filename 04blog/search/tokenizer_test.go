@@ -0,0 +1,38 @@
+package search
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestTokenizeTruncatesLongWordsOnRuneBoundary(t *testing.T) {
+	tests := []struct {
+		name string
+		word string
+		want string
+	}{
+		{name: "ascii", word: strings.Repeat("a", 100), want: strings.Repeat("a", maxTermLen)},
+		{name: "cyrillic", word: strings.Repeat("ж", 40), want: strings.Repeat("ж", maxTermLen/2)},
+		{name: "accented latin", word: "a" + strings.Repeat("é", 40), want: "a" + strings.Repeat("é", (maxTermLen-1)/2)},
+		{name: "short word", word: "Привет", want: "привет"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tokens := TokenizeDocument(tt.word)
+			if len(tokens) != 1 {
+				t.Fatalf("got %d tokens, want 1", len(tokens))
+			}
+			term := tokens[0].Term
+			if !utf8.ValidString(term) || len(term) > maxTermLen {
+				t.Errorf("term %q is not a valid term of at most %d bytes", term, maxTermLen)
+			}
+			if term != tt.want {
+				t.Errorf("term = %q, want %q", term, tt.want)
+			}
+			if tokens[0].End != len(tt.word) {
+				t.Errorf("end = %d, want %d", tokens[0].End, len(tt.word))
+			}
+		})
+	}
+}
@@ -4,47 +4,152 @@ import (
 	"04blog/constant"
 	"04blog/models"
 	"04blog/repositories"
+	"errors"
 	"fmt"
+	"log"
 
 	"github.com/go-redis/redis/v8"
+	"gorm.io/gorm"
 )
 
+// 评论相关错误
+var (
+	ErrInvalidParentComment = errors.New("回复的评论不存在或不属于该文章")
+	ErrInvalidCommentStatus = errors.New("无效的评论状态")
+)
+
+// threadReplyPreview 评论列表中每个楼层预览的回复数
+const threadReplyPreview = 3
+
+// incrCommentCountScript 评论数缓存存在时才增减 不存在时由 GetCommentCount 从数据库重建
+var incrCommentCountScript = redis.NewScript(`
+if redis.call('EXISTS', KEYS[1]) == 0 then
+	return false
+end
+return redis.call('INCRBY', KEYS[1], ARGV[1])
+`)
+
 type CommentService interface {
-	// CreateComment 创建评论
-	CreateComment(comment *models.Comment) error
+	// CreateComment 创建评论或回复
+	CreateComment(comment *models.Comment, byAdmin bool) error
 	// GetCommentByID 根据评论ID获取评论信息
 	GetCommentByID(commentID int64) (*models.Comment, error)
-	// GetCommentsByPostID 根据文章ID获取评论列表
-	GetCommentsByPostID(postID int64) ([]*models.Comment, error)
-	// UpdateComment 更新评论
-	UpdateComment(comment *models.Comment) error
-	// DeleteComment 删除评论
+	// GetThreads 分页获取文章的评论楼层 只包含已通过审核的评论
+	GetThreads(postID int64, page, pageSize int) (*CommentThreadPage, error)
+	// GetReplies 分页获取楼层中已通过审核的回复 楼层不可见时返回 gorm.ErrRecordNotFound
+	GetReplies(rootID int64, page, pageSize int) (*repositories.CommentPage, error)
+	// EditComment 编辑评论 保存编辑历史
+	EditComment(commentID, editorID int64, content string, byAdmin bool) (*models.Comment, error)
+	// GetRevisions 获取评论编辑历史
+	GetRevisions(commentID int64) ([]*models.CommentRevision, error)
+	// Moderate 修改评论审核状态
+	Moderate(commentID int64, status models.CommentStatus) (*models.Comment, error)
+	// GetModerationQueue 按审核状态分页获取评论
+	GetModerationQueue(status models.CommentStatus, page, pageSize int) (*repositories.CommentPage, error)
+	// DeleteComment 删除评论及其下的回复
 	DeleteComment(commentID int64) error
+	// GetCommentCount 获取文章可见的评论数 不包括未通过审核的楼层中的回复
+	GetCommentCount(postID int64) (int64, error)
+}
+
+// CommentThread 评论楼层 顶层评论和部分回复
+type CommentThread struct {
+	*models.Comment
+	ReplyCount int64             `json:"reply_count"`
+	Replies    []*models.Comment `json:"replies"`
+}
+
+// CommentThreadPage 评论楼层分页结果
+type CommentThreadPage struct {
+	Items    []*CommentThread
+	Total    int64
+	Page     int
+	PageSize int
 }
 
 type CommentServiceImpl struct {
 	commentDao  repositories.CommentRepository
 	redisClient *redis.Client
+	// 为 true 时普通用户的新评论和编辑后的评论需要管理员审核
+	requireApproval bool
 }
 
-func NewCommentService(commentDao repositories.CommentRepository, redisClient *redis.Client) CommentService {
-	return &CommentServiceImpl{commentDao: commentDao, redisClient: redisClient}
+func NewCommentService(commentDao repositories.CommentRepository, redisClient *redis.Client, requireApproval bool) CommentService {
+	return &CommentServiceImpl{commentDao: commentDao, redisClient: redisClient, requireApproval: requireApproval}
 }
 
-// CreateComment 创建评论
-func (c *CommentServiceImpl) CreateComment(comment *models.Comment) error {
-	// 新增评论后，需要更新文章的评论数
-	if comment.PostID > 0 {
-		// 使用PostID构建正确的缓存键
-		c.redisClient.Incr(c.redisClient.Context(), fmt.Sprintf(constant.RedisKeyPostComments, comment.PostID))
+// initialStatus 新增或编辑后的审核状态
+func (c *CommentServiceImpl) initialStatus(byAdmin bool) models.CommentStatus {
+	if c.requireApproval && !byAdmin {
+		return models.CommentStatusPending
+	}
+	return models.CommentStatusApproved
+}
+
+// CreateComment 创建评论 回复时继承所在楼层
+func (c *CommentServiceImpl) CreateComment(comment *models.Comment, byAdmin bool) error {
+	comment.RootID = 0
+	if comment.ParentID > 0 {
+		parent, err := c.commentDao.GetCommentByID(comment.ParentID)
+		// 只能回复同一文章下已通过审核的评论
+		if err != nil || parent.PostID != comment.PostID || parent.Status != models.CommentStatusApproved {
+			return ErrInvalidParentComment
+		}
+		comment.RootID = parent.RootID
+		if comment.RootID == 0 {
+			comment.RootID = parent.ID
+		}
+	}
+	comment.Status = c.initialStatus(byAdmin)
+	if err := c.commentDao.CreateComment(comment); err != nil {
+		return err
+	}
+	// 评论数只统计可见的评论 新评论按从待审核变为当前状态计算
+	c.adjustCountForTransition(comment, models.CommentStatusPending)
+	return nil
+}
+
+// GetThreads 分页获取评论楼层 每个楼层附带最早的几条回复
+func (c *CommentServiceImpl) GetThreads(postID int64, page, pageSize int) (*CommentThreadPage, error) {
+	roots, err := c.commentDao.ListThreads(postID, models.CommentStatusApproved, page, pageSize)
+	if err != nil {
+		return nil, err
+	}
+	rootIDs := make([]int64, len(roots.Items))
+	for i, root := range roots.Items {
+		rootIDs[i] = root.ID
+	}
+	replyCounts, err := c.commentDao.CountReplies(rootIDs, models.CommentStatusApproved)
+	if err != nil {
+		return nil, err
 	}
 
-	return c.commentDao.CreateComment(comment)
+	result := &CommentThreadPage{Items: make([]*CommentThread, 0, len(roots.Items)), Total: roots.Total, Page: roots.Page, PageSize: roots.PageSize}
+	for _, root := range roots.Items {
+		thread := &CommentThread{Comment: root, ReplyCount: replyCounts[root.ID], Replies: []*models.Comment{}}
+		if thread.ReplyCount > 0 {
+			replies, err := c.commentDao.ListReplies(root.ID, models.CommentStatusApproved, 1, threadReplyPreview)
+			if err != nil {
+				return nil, err
+			}
+			thread.Replies = replies.Items
+		}
+		result.Items = append(result.Items, thread)
+	}
+	return result, nil
 }
 
-// GetCommentsByPostID 根据文章ID获取评论列表
-func (c *CommentServiceImpl) GetCommentsByPostID(postID int64) ([]*models.Comment, error) {
-	return c.commentDao.GetCommentsByPostID(postID)
+// GetReplies 分页获取楼层中的回复
+// 顶层评论不存在或未通过审核时返回 gorm.ErrRecordNotFound 与评论数一致 楼层中的回复都不可见
+func (c *CommentServiceImpl) GetReplies(rootID int64, page, pageSize int) (*repositories.CommentPage, error) {
+	root, err := c.commentDao.GetCommentByID(rootID)
+	if err != nil {
+		return nil, err
+	}
+	if root.ParentID != 0 || root.Status != models.CommentStatusApproved {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return c.commentDao.ListReplies(rootID, models.CommentStatusApproved, page, pageSize)
 }
 
 // GetCommentByID 根据评论ID获取评论信息
@@ -52,19 +157,194 @@ func (c *CommentServiceImpl) GetCommentByID(commentID int64) (*models.Comment, e
 	return c.commentDao.GetCommentByID(commentID)
 }
 
-// UpdateComment 更新评论
-func (c *CommentServiceImpl) UpdateComment(comment *models.Comment) error {
-	return c.commentDao.UpdateComment(comment)
+// EditComment 编辑评论 需要审核时普通用户编辑已通过的评论后重新进入待审核状态
+// 编辑不会放宽审核状态 待审核和已隐藏的评论编辑后保持原状态
+func (c *CommentServiceImpl) EditComment(commentID, editorID int64, content string, byAdmin bool) (*models.Comment, error) {
+	comment, err := c.commentDao.GetCommentByID(commentID)
+	if err != nil {
+		return nil, err
+	}
+	if comment.Content == content {
+		return comment, nil
+	}
+	from := comment.Status
+	revision := &models.CommentRevision{CommentID: comment.ID, Content: comment.Content, EditorID: editorID}
+	comment.Content = content
+	if from == models.CommentStatusApproved {
+		comment.Status = c.initialStatus(byAdmin)
+	}
+	if err := c.commentDao.EditComment(comment, from, revision); err != nil {
+		return nil, err
+	}
+	c.adjustCountForTransition(comment, from)
+	return comment, nil
+}
+
+// GetRevisions 获取评论编辑历史
+func (c *CommentServiceImpl) GetRevisions(commentID int64) ([]*models.CommentRevision, error) {
+	return c.commentDao.GetRevisions(commentID)
+}
+
+// Moderate 修改审核状态 并发修改时只有一次生效
+func (c *CommentServiceImpl) Moderate(commentID int64, status models.CommentStatus) (*models.Comment, error) {
+	if !status.Valid() {
+		return nil, ErrInvalidCommentStatus
+	}
+	comment, err := c.commentDao.GetCommentByID(commentID)
+	if err != nil {
+		return nil, err
+	}
+	if comment.Status == status {
+		return comment, nil
+	}
+	from := comment.Status
+	changed, err := c.commentDao.UpdateStatus(commentID, from, status)
+	if err != nil {
+		return nil, err
+	}
+	if !changed {
+		return nil, repositories.ErrCommentStatusChanged
+	}
+	comment.Status = status
+	c.adjustCountForTransition(comment, from)
+	return comment, nil
+}
+
+// GetModerationQueue 按审核状态分页获取评论
+func (c *CommentServiceImpl) GetModerationQueue(status models.CommentStatus, page, pageSize int) (*repositories.CommentPage, error) {
+	if !status.Valid() {
+		return nil, ErrInvalidCommentStatus
+	}
+	return c.commentDao.ListByStatus(status, page, pageSize)
 }
 
-// DeleteComment 删除评论
+// DeleteComment 删除评论 该评论下的回复一并删除
 func (c *CommentServiceImpl) DeleteComment(commentID int64) error {
-	// 先获取评论信息，以便获取PostID
 	comment, err := c.commentDao.GetCommentByID(commentID)
-	if err == nil && comment != nil && comment.PostID > 0 {
-		// 使用PostID构建正确的缓存键
-		c.redisClient.Decr(c.redisClient.Context(), fmt.Sprintf(constant.RedisKeyPostComments, comment.PostID))
+	if err != nil {
+		return err
+	}
+	rootID := comment.RootID
+	if rootID == 0 {
+		rootID = comment.ID
+	}
+	thread, err := c.commentDao.GetThreadComments(rootID)
+	if err != nil {
+		return err
+	}
+	removed := collectSubtree(thread, comment.ID)
+
+	// 顶层评论未通过审核时整个楼层都不可见 没有计入评论数
+	rootVisible := false
+	for _, item := range thread {
+		if item.ID == rootID {
+			rootVisible = item.Status == models.CommentStatusApproved
+		}
+	}
+	ids := make([]int64, 0, len(removed))
+	var approved int64
+	for _, item := range removed {
+		ids = append(ids, item.ID)
+		if rootVisible && item.Status == models.CommentStatusApproved {
+			approved++
+		}
+	}
+	if err := c.commentDao.DeleteComments(ids); err != nil {
+		return err
+	}
+	if approved > 0 {
+		c.adjustCount(comment.PostID, -approved)
+	}
+	return nil
+}
+
+// collectSubtree 返回楼层中以 commentID 为根的所有评论
+func collectSubtree(thread []*models.Comment, commentID int64) []*models.Comment {
+	children := make(map[int64][]*models.Comment)
+	var target *models.Comment
+	for _, item := range thread {
+		children[item.ParentID] = append(children[item.ParentID], item)
+		if item.ID == commentID {
+			target = item
+		}
 	}
+	if target == nil {
+		return nil
+	}
+	result := []*models.Comment{target}
+	for i := 0; i < len(result); i++ {
+		result = append(result, children[result[i].ID]...)
+	}
+	return result
+}
+
+// GetCommentCount 获取评论数 缓存不存在时从数据库统计
+func (c *CommentServiceImpl) GetCommentCount(postID int64) (int64, error) {
+	ctx := c.redisClient.Context()
+	key := fmt.Sprintf(constant.RedisKeyPostComments, postID)
+	count, err := c.redisClient.Get(ctx, key).Int64()
+	if !errors.Is(err, redis.Nil) {
+		return count, err
+	}
+	count, err = c.commentDao.CountVisibleByPostID(postID)
+	if err != nil {
+		return 0, err
+	}
+	// SETNX 避免覆盖并发写入的值
+	if err := c.redisClient.SetNX(ctx, key, count, 0).Err(); err != nil {
+		log.Printf("重建文章 %d 评论数缓存失败: %v", postID, err)
+	}
+	return count, nil
+}
+
+// adjustCountForTransition 评论的审核状态从 from 变为 comment.Status 时调整评论数
+// 顶层评论决定整个楼层是否可见 可见性变化时连同楼层中已通过审核的回复一起增减
+// 回复只在顶层评论已通过审核时计数 统计失败时删除缓存 下次读取时重建
+func (c *CommentServiceImpl) adjustCountForTransition(comment *models.Comment, from models.CommentStatus) {
+	wasApproved, isApproved := from == models.CommentStatusApproved, comment.Status == models.CommentStatusApproved
+	if wasApproved == isApproved {
+		return
+	}
+	delta := int64(1)
+	if comment.RootID == 0 {
+		replies, err := c.commentDao.CountReplies([]int64{comment.ID}, models.CommentStatusApproved)
+		if err != nil {
+			log.Printf("统计评论 %d 的回复数失败: %v", comment.ID, err)
+			c.dropCount(comment.PostID)
+			return
+		}
+		delta += replies[comment.ID]
+	} else {
+		root, err := c.commentDao.GetCommentByID(comment.RootID)
+		if err != nil {
+			log.Printf("读取评论 %d 所在楼层失败: %v", comment.ID, err)
+			c.dropCount(comment.PostID)
+			return
+		}
+		if root.Status != models.CommentStatusApproved {
+			return
+		}
+	}
+	if !isApproved {
+		delta = -delta
+	}
+	c.adjustCount(comment.PostID, delta)
+}
+
+// adjustCount 增减评论数缓存 失败时删除缓存 下次读取时重建
+func (c *CommentServiceImpl) adjustCount(postID int64, delta int64) {
+	ctx := c.redisClient.Context()
+	key := fmt.Sprintf(constant.RedisKeyPostComments, postID)
+	err := incrCommentCountScript.Run(ctx, c.redisClient, []string{key}, delta).Err()
+	if err == nil || errors.Is(err, redis.Nil) {
+		return
+	}
+	log.Printf("更新文章 %d 评论数失败: %v", postID, err)
+	c.dropCount(postID)
+}
 
-	return c.commentDao.DeleteComment(commentID)
+// dropCount 删除评论数缓存 下次读取时从数据库重建
+func (c *CommentServiceImpl) dropCount(postID int64) {
+	ctx := c.redisClient.Context()
+	c.redisClient.Del(ctx, fmt.Sprintf(constant.RedisKeyPostComments, postID))
 }
@@ -0,0 +1,86 @@
+package servers
+
+import (
+	"04blog/models"
+	"04blog/repositories"
+	"errors"
+	"testing"
+
+	"gorm.io/gorm"
+)
+
+// fakeCommentRepository 只实现 GetReplies 用到的方法
+type fakeCommentRepository struct {
+	repositories.CommentRepository
+	comments map[int64]*models.Comment
+}
+
+func (f *fakeCommentRepository) GetCommentByID(commentID int64) (*models.Comment, error) {
+	comment, ok := f.comments[commentID]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return comment, nil
+}
+
+func (f *fakeCommentRepository) ListReplies(rootID int64, status models.CommentStatus, page, pageSize int) (*repositories.CommentPage, error) {
+	result := &repositories.CommentPage{Items: []*models.Comment{}, Page: page, PageSize: pageSize}
+	for id := int64(1); id <= int64(len(f.comments)); id++ {
+		comment := f.comments[id]
+		if comment.RootID == rootID && comment.Status == status {
+			result.Items = append(result.Items, comment)
+		}
+	}
+	result.Total = int64(len(result.Items))
+	return result, nil
+}
+
+func TestGetRepliesOnlyForVisibleThreads(t *testing.T) {
+	newComment := func(id, parentID, rootID int64, status models.CommentStatus) *models.Comment {
+		comment := &models.Comment{PostID: 1, ParentID: parentID, RootID: rootID, Status: status}
+		comment.ID = id
+		return comment
+	}
+	repo := &fakeCommentRepository{comments: map[int64]*models.Comment{
+		1: newComment(1, 0, 0, models.CommentStatusApproved),
+		2: newComment(2, 1, 1, models.CommentStatusApproved),
+		3: newComment(3, 0, 0, models.CommentStatusHidden),
+		4: newComment(4, 3, 3, models.CommentStatusApproved),
+		5: newComment(5, 0, 0, models.CommentStatusPending),
+		6: newComment(6, 5, 5, models.CommentStatusApproved),
+	}}
+	service := NewCommentService(repo, nil, false)
+
+	tests := []struct {
+		name      string
+		rootID    int64
+		wantCount int
+		wantErr   error
+	}{
+		{name: "approved root", rootID: 1, wantCount: 1},
+		{name: "hidden root", rootID: 3, wantErr: gorm.ErrRecordNotFound},
+		{name: "pending root", rootID: 5, wantErr: gorm.ErrRecordNotFound},
+		{name: "reply is not a root", rootID: 2, wantErr: gorm.ErrRecordNotFound},
+		{name: "missing root", rootID: 99, wantErr: gorm.ErrRecordNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			replies, err := service.GetReplies(tt.rootID, 1, 10)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("err = %v, want %v", err, tt.wantErr)
+				}
+				if replies != nil {
+					t.Errorf("replies = %v, want none", replies.Items)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(replies.Items) != tt.wantCount {
+				t.Errorf("got %d replies, want %d", len(replies.Items), tt.wantCount)
+			}
+		})
+	}
+}
@@ -0,0 +1,302 @@
+package servers
+
+import (
+	"04blog/constant"
+	"04blog/repositories"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// incrIfExistsScript 计数键存在时才自增 并把文章标记为待回写
+// 键不存在时返回 nil 由调用方从MySQL重建后重试 避免从0开始计数覆盖数据库中的值
+var incrIfExistsScript = redis.NewScript(`
+if redis.call('EXISTS', KEYS[1]) == 0 then
+	return false
+end
+local v = redis.call('INCRBY', KEYS[1], ARGV[1])
+redis.call('SADD', KEYS[2], ARGV[2])
+return v
+`)
+
+// CounterOptions 计数器回写配置
+type CounterOptions struct {
+	FlushInterval     time.Duration //回写间隔
+	BatchSize         int           //每批回写的文章数
+	ReconcileInterval time.Duration //校正点赞数的间隔
+}
+
+// CounterService 文章阅读量、点赞数计数服务
+// 计数先写Redis 由 Run 定期批量回写MySQL
+type CounterService interface {
+	// InitPost 新文章初始化计数
+	InitPost(ctx context.Context, postID int64) error
+	// RemovePost 删除文章计数
+	RemovePost(ctx context.Context, postID int64) error
+	// IncrViews 阅读量+1 返回最新阅读量
+	IncrViews(ctx context.Context, postID int64) (int64, error)
+	// AddLikes 点赞数增加 delta 返回最新点赞数
+	AddLikes(ctx context.Context, postID int64, delta int64) (int64, error)
+	// GetLikes 获取点赞数
+	GetLikes(ctx context.Context, postID int64) (int64, error)
+	// Flush 把有变化的计数回写MySQL 返回回写的文章数
+	Flush(ctx context.Context) (int, error)
+	// ReconcileLikes 按 likes 表校正点赞数 返回修正的文章数
+	ReconcileLikes(ctx context.Context) (int, error)
+	// Run 定期回写和校正 ctx 结束时做最后一次回写后返回
+	Run(ctx context.Context)
+}
+
+type CounterServiceImpl struct {
+	repo        repositories.PostRepository
+	redisClient *redis.Client
+	opts        CounterOptions
+}
+
+// NewCounterService 创建计数服务
+func NewCounterService(repo repositories.PostRepository, redisClient *redis.Client, opts CounterOptions) CounterService {
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = 500
+	}
+	return &CounterServiceImpl{repo: repo, redisClient: redisClient, opts: opts}
+}
+
+func viewsKey(postID int64) string { return fmt.Sprintf(constant.RedisKeyPostViews, postID) }
+func likesKey(postID int64) string { return fmt.Sprintf(constant.RedisKeyPostLikes, postID) }
+
+// InitPost 新文章计数从0开始
+func (s *CounterServiceImpl) InitPost(ctx context.Context, postID int64) error {
+	_, err := s.redisClient.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.SetNX(ctx, viewsKey(postID), 0, 0)
+		pipe.SetNX(ctx, likesKey(postID), 0, 0)
+		return nil
+	})
+	return err
+}
+
+// RemovePost 删除计数键和点赞用户集合 不再回写
+func (s *CounterServiceImpl) RemovePost(ctx context.Context, postID int64) error {
+	_, err := s.redisClient.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Del(ctx, viewsKey(postID), likesKey(postID),
+			fmt.Sprintf(constant.RedisKeyPostLikers, postID), fmt.Sprintf(constant.RedisKeyPostLikersVersion, postID))
+		pipe.SRem(ctx, constant.RedisKeyDirtyPostCounters, postID)
+		return nil
+	})
+	return err
+}
+
+// IncrViews 阅读量+1
+func (s *CounterServiceImpl) IncrViews(ctx context.Context, postID int64) (int64, error) {
+	return s.incr(ctx, postID, viewsKey(postID), 1)
+}
+
+// AddLikes 点赞数增加 delta
+func (s *CounterServiceImpl) AddLikes(ctx context.Context, postID int64, delta int64) (int64, error) {
+	return s.incr(ctx, postID, likesKey(postID), delta)
+}
+
+// GetLikes 获取点赞数 缓存不存在时从MySQL重建
+func (s *CounterServiceImpl) GetLikes(ctx context.Context, postID int64) (int64, error) {
+	likes, err := s.redisClient.Get(ctx, likesKey(postID)).Int64()
+	if errors.Is(err, redis.Nil) {
+		if err := s.rebuild(ctx, postID); err != nil {
+			return 0, err
+		}
+		return s.redisClient.Get(ctx, likesKey(postID)).Int64()
+	}
+	return likes, err
+}
+
+// incr 计数自增 键不存在时先从MySQL重建
+func (s *CounterServiceImpl) incr(ctx context.Context, postID int64, key string, delta int64) (int64, error) {
+	keys := []string{key, constant.RedisKeyDirtyPostCounters}
+	v, err := incrIfExistsScript.Run(ctx, s.redisClient, keys, delta, postID).Int64()
+	if !errors.Is(err, redis.Nil) {
+		return v, err
+	}
+	if err := s.rebuild(ctx, postID); err != nil {
+		return 0, err
+	}
+	return incrIfExistsScript.Run(ctx, s.redisClient, keys, delta, postID).Int64()
+}
+
+// rebuild 用MySQL中的值重建计数键 SETNX 保证并发重建时不会覆盖已经自增的值
+func (s *CounterServiceImpl) rebuild(ctx context.Context, postID int64) error {
+	post, err := s.repo.GetPost(postID)
+	if err != nil {
+		return err
+	}
+	_, err = s.redisClient.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.SetNX(ctx, viewsKey(postID), post.ViewCount, 0)
+		pipe.SetNX(ctx, likesKey(postID), post.LikeCount, 0)
+		return nil
+	})
+	return err
+}
+
+// Flush 分批取出待回写的文章 读取计数后写入MySQL 失败时放回待回写集合
+func (s *CounterServiceImpl) Flush(ctx context.Context) (int, error) {
+	flushed := 0
+	for {
+		members, err := s.redisClient.SPopN(ctx, constant.RedisKeyDirtyPostCounters, int64(s.opts.BatchSize)).Result()
+		if err != nil {
+			return flushed, err
+		}
+		if len(members) == 0 {
+			return flushed, nil
+		}
+		counters, err := s.readCounters(ctx, members)
+		if err == nil {
+			err = s.repo.UpdateCounters(counters)
+		}
+		if err != nil {
+			//回写失败 重新标记 下次再试
+			ids := make([]interface{}, len(members))
+			for i, m := range members {
+				ids[i] = m
+			}
+			if restoreErr := s.redisClient.SAdd(ctx, constant.RedisKeyDirtyPostCounters, ids...).Err(); restoreErr != nil {
+				log.Printf("计数回写失败后恢复待回写集合失败: %v", restoreErr)
+			}
+			return flushed, err
+		}
+		flushed += len(counters)
+		if len(members) < s.opts.BatchSize {
+			return flushed, nil
+		}
+	}
+}
+
+// readCounters 批量读取计数 键已不存在的字段不回写
+func (s *CounterServiceImpl) readCounters(ctx context.Context, members []string) ([]repositories.PostCounters, error) {
+	ids := make([]int64, 0, len(members))
+	keys := make([]string, 0, len(members)*2)
+	for _, m := range members {
+		id, err := strconv.ParseInt(m, 10, 64)
+		if err != nil {
+			continue
+		}
+		ids = append(ids, id)
+		keys = append(keys, viewsKey(id), likesKey(id))
+	}
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	values, err := s.redisClient.MGet(ctx, keys...).Result()
+	if err != nil {
+		return nil, err
+	}
+	counters := make([]repositories.PostCounters, 0, len(ids))
+	for i, id := range ids {
+		c := repositories.PostCounters{PostID: id, ViewCount: parseCounter(values[2*i]), LikeCount: parseCounter(values[2*i+1])}
+		if c.ViewCount != nil || c.LikeCount != nil {
+			counters = append(counters, c)
+		}
+	}
+	return counters, nil
+}
+
+func parseCounter(v interface{}) *int64 {
+	str, ok := v.(string)
+	if !ok {
+		return nil
+	}
+	n, err := strconv.ParseInt(str, 10, 64)
+	if err != nil {
+		return nil
+	}
+	return &n
+}
+
+// ReconcileLikes 先回写再按 likes 表重新统计 修正Redis和MySQL中不一致的点赞数
+// 统计与修正之间发生的点赞可能被覆盖 下一次校正会再次修正
+func (s *CounterServiceImpl) ReconcileLikes(ctx context.Context) (int, error) {
+	if _, err := s.Flush(ctx); err != nil {
+		return 0, err
+	}
+	fixed := 0
+	var afterID int64
+	for {
+		counts, err := s.repo.RecountLikes(afterID, s.opts.BatchSize)
+		if err != nil {
+			return fixed, err
+		}
+		if len(counts) == 0 {
+			return fixed, nil
+		}
+		keys := make([]string, len(counts))
+		for i, c := range counts {
+			keys[i] = likesKey(c.PostID)
+		}
+		cached, err := s.redisClient.MGet(ctx, keys...).Result()
+		if err != nil {
+			return fixed, err
+		}
+
+		var updates []repositories.PostCounters
+		pipe := s.redisClient.Pipeline()
+		for i, c := range counts {
+			cachedLikes := parseCounter(cached[i])
+			cacheWrong := cachedLikes != nil && *cachedLikes != c.Actual
+			if !cacheWrong && c.Stored == c.Actual {
+				continue
+			}
+			actual := c.Actual
+			updates = append(updates, repositories.PostCounters{PostID: c.PostID, LikeCount: &actual})
+			if cacheWrong {
+				pipe.Set(ctx, keys[i], actual, 0)
+			}
+		}
+		if len(updates) > 0 {
+			if err := s.repo.UpdateCounters(updates); err != nil {
+				return fixed, err
+			}
+			if _, err := pipe.Exec(ctx); err != nil {
+				return fixed, err
+			}
+			fixed += len(updates)
+		}
+		afterID = counts[len(counts)-1].PostID
+		if len(counts) < s.opts.BatchSize {
+			return fixed, nil
+		}
+	}
+}
+
+// Run 定期回写和校正 ctx 结束后用新的上下文做最后一次回写 避免丢失最后一段时间的计数
+// 调用方应在不再有请求修改计数后再结束 ctx
+func (s *CounterServiceImpl) Run(ctx context.Context) {
+	flushTicker := time.NewTicker(s.opts.FlushInterval)
+	defer flushTicker.Stop()
+	reconcileTicker := time.NewTicker(s.opts.ReconcileInterval)
+	defer reconcileTicker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			finalCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			defer cancel()
+			if n, err := s.Flush(finalCtx); err != nil {
+				log.Printf("退出前回写计数失败: %v", err)
+			} else {
+				log.Printf("退出前回写计数完成，共 %d 篇文章", n)
+			}
+			return
+		case <-flushTicker.C:
+			if _, err := s.Flush(ctx); err != nil {
+				log.Printf("回写计数失败: %v", err)
+			}
+		case <-reconcileTicker.C:
+			if n, err := s.ReconcileLikes(ctx); err != nil {
+				log.Printf("校正点赞数失败: %v", err)
+			} else if n > 0 {
+				log.Printf("校正点赞数完成，修正 %d 篇文章", n)
+			}
+		}
+	}
+}
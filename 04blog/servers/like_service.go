@@ -5,68 +5,174 @@ import (
 	"04blog/models"
 	"04blog/repositories"
 	"context"
-	"errors"
 	"fmt"
+	"log"
+	"time"
 
 	"github.com/go-redis/redis/v8"
 )
 
+// likersPlaceholder 点赞用户集合中的占位成员 保证没有人点赞时集合也存在
+const likersPlaceholder = 0
+
+// likersTTL 点赞用户集合的过期时间 过期后从数据库重新加载 修正可能的偏差
+const likersTTL = 24 * time.Hour
+
+// updateLikersScript 集合存在时才更新 避免在缓存不存在时创建出只有部分成员的集合
+// 同时递增版本号 让此前开始的 loadLikersScript 放弃写入
+// KEYS[1] 集合 KEYS[2] 版本号 ARGV[1] SADD/SREM ARGV[2] 用户ID ARGV[3] 版本号过期时间(秒)
+var updateLikersScript = redis.NewScript(`
+redis.call('INCR', KEYS[2])
+redis.call('EXPIRE', KEYS[2], ARGV[3])
+if redis.call('EXISTS', KEYS[1]) == 0 then
+	return 0
+end
+return redis.call(ARGV[1], KEYS[1], ARGV[2])
+`)
+
+// loadLikersScript 集合不存在且版本号与读取数据库前一致时才写入集合
+// 读取数据库期间有点赞或取消点赞时数据可能已过期 放弃写入 下次查询重新加载
+// KEYS[1] 集合 KEYS[2] 版本号 ARGV[1] 读取数据库前的版本号 ARGV[2] 过期时间(秒) ARGV[3:] 成员
+var loadLikersScript = redis.NewScript(`
+if redis.call('EXISTS', KEYS[1]) == 1 then
+	return 0
+end
+if (redis.call('GET', KEYS[2]) or '') ~= ARGV[1] then
+	return 0
+end
+for i = 3, #ARGV do
+	redis.call('SADD', KEYS[1], ARGV[i])
+end
+redis.call('EXPIRE', KEYS[1], ARGV[2])
+return 1
+`)
+
 type LikeService interface {
-	// Create 创建点赞
-	Create(like *models.Like) error
-	// Delete 删除点赞
-	Delete(like *models.Like) error
+	// Like 点赞 重复点赞不报错
+	Like(ctx context.Context, userID, postID int64) (*LikeState, error)
+	// Unlike 取消点赞 未点赞时不报错
+	Unlike(ctx context.Context, userID, postID int64) (*LikeState, error)
+	// SetLiked 把点赞状态设置为 liked
+	SetLiked(ctx context.Context, userID, postID int64, liked bool) (*LikeState, error)
+	// GetState 查询用户对文章的点赞状态
+	GetState(ctx context.Context, userID, postID int64) (*LikeState, error)
 	// GetByPostID 根据帖子ID获取点赞列表
 	GetByPostID(postID int64) ([]*models.Like, error)
 }
 
+// LikeState 用户对文章的点赞状态
+type LikeState struct {
+	PostID    int64 `json:"post_id"`
+	Liked     bool  `json:"liked"`
+	LikeCount int64 `json:"like_count"`
+}
+
 type LikeServiceImpl struct {
 	repo        repositories.LikeRepository
+	counter     CounterService
 	redisClient *redis.Client
 }
 
 // NewLikeServiceImpl 创建点赞服务实现
-func NewLikeServiceImpl(repo repositories.LikeRepository, redisClient *redis.Client) *LikeServiceImpl {
-	return &LikeServiceImpl{repo: repo, redisClient: redisClient}
+func NewLikeServiceImpl(repo repositories.LikeRepository, counter CounterService, redisClient *redis.Client) *LikeServiceImpl {
+	return &LikeServiceImpl{repo: repo, counter: counter, redisClient: redisClient}
 }
 
-// Create 创建点赞
-func (s *LikeServiceImpl) Create(like *models.Like) error {
-	// 检查是否已点赞
-	existingLike, err := s.repo.GetByUserIDAndPostID(like.UserID, like.PostID)
-	if err == nil && existingLike != nil {
-		return errors.New("user has already liked this post")
+// Like 点赞
+func (s *LikeServiceImpl) Like(ctx context.Context, userID, postID int64) (*LikeState, error) {
+	return s.SetLiked(ctx, userID, postID, true)
+}
+
+// Unlike 取消点赞
+func (s *LikeServiceImpl) Unlike(ctx context.Context, userID, postID int64) (*LikeState, error) {
+	return s.SetLiked(ctx, userID, postID, false)
+}
+
+// SetLiked 点赞状态以数据库唯一索引为准 只有状态真正改变时才调整点赞数
+// 并发的重复请求中只有一个会改变状态 其余请求直接返回最新状态
+func (s *LikeServiceImpl) SetLiked(ctx context.Context, userID, postID int64, liked bool) (*LikeState, error) {
+	// 先读取点赞数 文章不存在时返回 gorm.ErrRecordNotFound
+	if _, err := s.counter.GetLikes(ctx, postID); err != nil {
+		return nil, err
+	}
+
+	var changed bool
+	var err error
+	if liked {
+		changed, err = s.repo.Like(userID, postID)
+	} else {
+		changed, err = s.repo.Unlike(userID, postID)
 	}
-	//reids 缓存 点赞+1
-	redisKey := fmt.Sprintf(constant.RedisKeyPostLikes, like.PostID)
-	err = s.redisClient.Incr(context.Background(), redisKey).Err()
 	if err != nil {
-		return err
+		return nil, err
 	}
-	return s.repo.Create(like)
-}
 
-// Delete 删除点赞
-func (s *LikeServiceImpl) Delete(like *models.Like) error {
-	// 检查是否已点赞
-	existingLike, err := s.repo.GetByUserIDAndPostID(like.UserID, like.PostID)
-	if err != nil || existingLike == nil {
-		return errors.New("user has not liked this post")
+	if changed {
+		// 数据库已提交 缓存更新失败只记录日志 由定期校正和集合过期修复
+		delta, command := int64(1), "SADD"
+		if !liked {
+			delta, command = -1, "SREM"
+		}
+		if _, err := s.counter.AddLikes(ctx, postID, delta); err != nil {
+			log.Printf("更新文章 %d 点赞数失败: %v", postID, err)
+		}
+		keys := []string{fmt.Sprintf(constant.RedisKeyPostLikers, postID), fmt.Sprintf(constant.RedisKeyPostLikersVersion, postID)}
+		if err := updateLikersScript.Run(ctx, s.redisClient, keys, command, userID, int64(likersTTL/time.Second)).Err(); err != nil {
+			log.Printf("更新文章 %d 点赞用户缓存失败: %v", postID, err)
+		}
 	}
 
-	// 删除数据库中的点赞记录
-	err = s.repo.Delete(existingLike)
+	count, err := s.counter.GetLikes(ctx, postID)
 	if err != nil {
-		return err
+		return nil, err
+	}
+	return &LikeState{PostID: postID, Liked: liked, LikeCount: count}, nil
+}
+
+// GetState 查询点赞状态 优先读取Redis中的点赞用户集合
+func (s *LikeServiceImpl) GetState(ctx context.Context, userID, postID int64) (*LikeState, error) {
+	count, err := s.counter.GetLikes(ctx, postID)
+	if err != nil {
+		return nil, err
+	}
+	liked, err := s.isLiked(ctx, userID, postID)
+	if err != nil {
+		return nil, err
+	}
+	return &LikeState{PostID: postID, Liked: liked, LikeCount: count}, nil
+}
+
+// isLiked 集合不存在时从数据库加载
+func (s *LikeServiceImpl) isLiked(ctx context.Context, userID, postID int64) (bool, error) {
+	key := fmt.Sprintf(constant.RedisKeyPostLikers, postID)
+	versionKey := fmt.Sprintf(constant.RedisKeyPostLikersVersion, postID)
+	pipe := s.redisClient.Pipeline()
+	exists := pipe.Exists(ctx, key)
+	member := pipe.SIsMember(ctx, key, userID)
+	version := pipe.Get(ctx, versionKey)
+	if _, err := pipe.Exec(ctx); err != nil && err != redis.Nil {
+		return false, err
+	}
+	if exists.Val() == 1 {
+		return member.Val(), nil
 	}
 
-	// 更新Redis缓存，点赞数-1
-	redisKey := fmt.Sprintf(constant.RedisKeyPostLikes, like.PostID)
-	err = s.redisClient.Decr(context.Background(), redisKey).Err()
+	userIDs, err := s.repo.GetUserIDsByPostID(postID)
 	if err != nil {
-		return err
+		return false, err
+	}
+	args := make([]interface{}, 0, len(userIDs)+3)
+	args = append(args, version.Val(), int64(likersTTL/time.Second), likersPlaceholder)
+	liked := false
+	for _, id := range userIDs {
+		args = append(args, id)
+		liked = liked || id == userID
+	}
+	if err := loadLikersScript.Run(ctx, s.redisClient, []string{key, versionKey}, args...).Err(); err != nil {
+		// 缓存写入失败不影响查询结果
+		log.Printf("加载文章 %d 点赞用户缓存失败: %v", postID, err)
 	}
-	return nil
+	return liked, nil
 }
 
 // GetByPostID 根据帖子ID获取点赞列表
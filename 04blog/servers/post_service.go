@@ -1,12 +1,10 @@
 package servers
 
 import (
-	"04blog/constant"
 	"04blog/models"
 	"04blog/repositories"
-	"fmt"
-
-	"github.com/go-redis/redis/v8"
+	"context"
+	"log"
 )
 
 type PostService interface {
@@ -14,21 +12,31 @@ type PostService interface {
 	CreatePost(post *models.Post) error
 	// 获取文章
 	GetPost(postID int64) (*models.Post, error)
-	//多条件查询文章 不传入就是查询所有
-	GetPostsByConditions(conditions map[string]interface{}) ([]*models.Post, error)
+	// 获取文章作者ID 不计入阅读量
+	GetPostAuthorID(postID int64) (int64, error)
+	// 分页查询文章 支持过滤、排序、偏移分页和游标分页
+	ListPosts(query *repositories.PostQuery) (*repositories.PostPage, error)
 	// 删除文章
 	DeletePost(postID int64) error
 	// 更新文章
 	UpdatePost(post *models.Post) error
 }
 type PostServiceImpl struct {
-	repo        repositories.PostRepository
-	redisClient *redis.Client
+	repo    repositories.PostRepository
+	counter CounterService
+	search  SearchService
 }
 
 // DeletePost 删除文章
 func (p *PostServiceImpl) DeletePost(postID int64) error {
-	return p.repo.DeletePost(postID)
+	if err := p.repo.DeletePost(postID); err != nil {
+		return err
+	}
+	if err := p.counter.RemovePost(context.Background(), postID); err != nil {
+		log.Printf("删除文章 %d 计数失败: %v", postID, err)
+	}
+	p.search.RemovePost(postID)
+	return nil
 }
 
 // GetPost 获取文章
@@ -39,14 +47,15 @@ func (p *PostServiceImpl) GetPost(postID int64) (*models.Post, error) {
 		return nil, err
 	}
 
-	//从redis中获取文章阅读量进行+1 赋值
-	views, err := p.redisClient.Incr(p.redisClient.Context(), fmt.Sprintf(constant.RedisKeyPostViews, postID)).Result()
+	//阅读量+1 计数以Redis为准 缓存丢失时从数据库重建
+	ctx := context.Background()
+	views, err := p.counter.IncrViews(ctx, postID)
 	if err != nil {
 		return nil, err
 	}
 	post.ViewCount = int(views)
-	//从redis中获取文章点赞量
-	likes, err := p.redisClient.Get(p.redisClient.Context(), fmt.Sprintf(constant.RedisKeyPostLikes, postID)).Int64()
+	//点赞量
+	likes, err := p.counter.GetLikes(ctx, postID)
 	if err != nil {
 		return nil, err
 	}
@@ -55,26 +64,49 @@ func (p *PostServiceImpl) GetPost(postID int64) (*models.Post, error) {
 	return post, nil
 }
 
-// GetPostsByConditions 获取多条件查询文章
-func (p *PostServiceImpl) GetPostsByConditions(conditions map[string]interface{}) ([]*models.Post, error) {
-	return p.repo.GetPostsByConditions(conditions)
+// GetPostAuthorID 获取文章作者ID
+func (p *PostServiceImpl) GetPostAuthorID(postID int64) (int64, error) {
+	post, err := p.repo.GetPost(postID)
+	if err != nil {
+		return 0, err
+	}
+	return post.UserID, nil
+}
+
+// ListPosts 分页查询文章
+func (p *PostServiceImpl) ListPosts(query *repositories.PostQuery) (*repositories.PostPage, error) {
+	return p.repo.ListPosts(query)
 }
 
 // UpdatePost 更新文章
 func (p *PostServiceImpl) UpdatePost(post *models.Post) error {
-	return p.repo.UpdatePost(post)
+	if err := p.repo.UpdatePost(post); err != nil {
+		return err
+	}
+	//请求中只有修改的字段 重新读取保存后的文章再索引 否则未修改的标题或正文会被索引为空
+	stored, err := p.repo.GetPost(post.ID)
+	if err != nil {
+		log.Printf("读取文章 %d 更新索引失败: %v", post.ID, err)
+		return nil
+	}
+	p.search.IndexPost(stored)
+	return nil
 }
 
 // NewPostService 创建文章服务
-func NewPostService(repo repositories.PostRepository, redisClient *redis.Client) PostService {
-	return &PostServiceImpl{repo: repo, redisClient: redisClient}
+func NewPostService(repo repositories.PostRepository, counter CounterService, search SearchService) PostService {
+	return &PostServiceImpl{repo: repo, counter: counter, search: search}
 }
 
 // CreatePost 新增文章
 func (p *PostServiceImpl) CreatePost(post *models.Post) error {
-	//初始阅读量 到redis
-	p.redisClient.Set(p.redisClient.Context(), fmt.Sprintf(constant.RedisKeyPostViews, post.ID), 0, 0)
-	//初始点赞量 到redis
-	p.redisClient.Set(p.redisClient.Context(), fmt.Sprintf(constant.RedisKeyPostLikes, post.ID), 0, 0)
-	return p.repo.CreatePost(post)
+	if err := p.repo.CreatePost(post); err != nil {
+		return err
+	}
+	//保存后才有文章ID 初始化阅读量、点赞量 失败时首次访问会从数据库重建
+	if err := p.counter.InitPost(context.Background(), post.ID); err != nil {
+		log.Printf("初始化文章 %d 计数失败: %v", post.ID, err)
+	}
+	p.search.IndexPost(post)
+	return nil
 }
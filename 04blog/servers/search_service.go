@@ -0,0 +1,113 @@
+package servers
+
+import (
+	"04blog/models"
+	"04blog/repositories"
+	"04blog/search"
+	"context"
+)
+
+// 摘要长度（字数）
+const searchSnippetRunes = 120
+
+// SearchService 文章全文搜索服务
+type SearchService interface {
+	// Search 搜索文章 按相关度排序
+	Search(query string, page, pageSize int) (*SearchResult, error)
+	// IndexPost 新增或更新文章索引
+	IndexPost(post *models.Post)
+	// RemovePost 删除文章索引
+	RemovePost(postID int64)
+	// Rebuild 从数据库全量重建索引 返回索引的文章数量
+	Rebuild(ctx context.Context) (int, error)
+}
+
+// SearchHit 搜索结果条目 标题和摘要中命中的词用 <em> 标记
+type SearchHit struct {
+	ID      int64   `json:"id"`
+	Title   string  `json:"title"`
+	Snippet string  `json:"snippet"`
+	Score   float64 `json:"score"`
+}
+
+// SearchResult 搜索结果
+type SearchResult struct {
+	Hits     []SearchHit
+	Total    int
+	Page     int
+	PageSize int
+}
+
+type SearchServiceImpl struct {
+	repo  repositories.PostRepository
+	index *search.Index
+}
+
+// NewSearchService 创建搜索服务 索引为空 需要调用 Rebuild 加载已有文章
+func NewSearchService(repo repositories.PostRepository) SearchService {
+	return &SearchServiceImpl{repo: repo, index: search.NewIndex()}
+}
+
+// Search 搜索文章
+func (s *SearchServiceImpl) Search(query string, page, pageSize int) (*SearchResult, error) {
+	if page <= 0 {
+		page = 1
+	}
+	if pageSize <= 0 {
+		pageSize = repositories.DefaultPageSize
+	}
+	if pageSize > repositories.MaxPageSize {
+		pageSize = repositories.MaxPageSize
+	}
+	hits, total := s.index.Search(query, (page-1)*pageSize, pageSize)
+	result := &SearchResult{Hits: make([]SearchHit, 0, len(hits)), Total: total, Page: page, PageSize: pageSize}
+	for _, hit := range hits {
+		result.Hits = append(result.Hits, SearchHit{
+			ID:      hit.ID,
+			Title:   search.Highlight(hit.Title, query, 0),
+			Snippet: search.Highlight(hit.Content, query, searchSnippetRunes),
+			Score:   hit.Score,
+		})
+	}
+	return result, nil
+}
+
+// IndexPost 新增或更新文章索引
+func (s *SearchServiceImpl) IndexPost(post *models.Post) {
+	s.index.Add(postDocument(post))
+}
+
+// RemovePost 删除文章索引
+func (s *SearchServiceImpl) RemovePost(postID int64) {
+	s.index.Remove(postID)
+}
+
+// Rebuild 分批读取全部文章后整体替换索引 读取期间新增、修改和删除的文章不会丢失
+func (s *SearchServiceImpl) Rebuild(ctx context.Context) (int, error) {
+	rebuild := s.index.BeginRebuild()
+	defer rebuild.Abort()
+	var docs []search.Document
+	query := &repositories.PostQuery{SortBy: repositories.PostSortCreatedAt, PageSize: repositories.MaxPageSize}
+	for {
+		if err := ctx.Err(); err != nil {
+			return 0, err
+		}
+		page, err := s.repo.ListPosts(query)
+		if err != nil {
+			return 0, err
+		}
+		for _, post := range page.Items {
+			docs = append(docs, postDocument(post))
+		}
+		if !page.HasMore {
+			break
+		}
+		query.Cursor = page.NextCursor
+	}
+	rebuild.Commit(docs)
+	return len(docs), nil
+}
+
+func postDocument(post *models.Post) search.Document {
+	return search.Document{ID: post.ID, Title: post.Title, Content: post.Content}
+}
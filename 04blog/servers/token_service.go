@@ -0,0 +1,105 @@
+package servers
+
+import (
+	"04blog/constant"
+	"04blog/repositories"
+	"04blog/utils"
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrTokenRevoked 令牌已被吊销
+	ErrTokenRevoked = errors.New("token has been revoked")
+	// ErrTokenUserNotFound 令牌所属的用户已不存在
+	ErrTokenUserNotFound = errors.New("token user no longer exists")
+)
+
+// TokenService 令牌刷新与吊销服务
+type TokenService interface {
+	// Refresh 使用刷新令牌换取新的令牌对 旧的刷新令牌随即失效
+	Refresh(ctx context.Context, refreshToken string) (*utils.TokenPair, error)
+	// Revoke 吊销令牌 直到其自然过期
+	Revoke(ctx context.Context, claims *utils.JWTClaims) error
+	// IsRevoked 判断令牌是否已被吊销
+	IsRevoked(ctx context.Context, claims *utils.JWTClaims) (bool, error)
+}
+
+type tokenService struct {
+	redisClient *redis.Client
+	userDao     repositories.UserRepository
+}
+
+// NewTokenService 创建令牌服务 吊销列表保存在Redis中 刷新时从数据库读取用户的当前角色
+func NewTokenService(redisClient *redis.Client, userDao repositories.UserRepository) TokenService {
+	return &tokenService{redisClient: redisClient, userDao: userDao}
+}
+
+// Refresh 使用刷新令牌换取新的令牌对
+// 新令牌使用数据库中的用户名和角色 被降级的管理员刷新后不再拥有管理员权限 用户已删除时拒绝刷新
+func (t *tokenService) Refresh(ctx context.Context, refreshToken string) (*utils.TokenPair, error) {
+	claims, err := utils.ParseRefreshToken(refreshToken)
+	if err != nil {
+		return nil, err
+	}
+	user, err := t.userDao.GetUserByID(claims.UserID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrTokenUserNotFound
+		}
+		return nil, err
+	}
+	//刷新令牌只能使用一次 SETNX 保证并发刷新时只有一个请求成功
+	first, err := t.revoke(ctx, claims)
+	if err != nil {
+		return nil, err
+	}
+	if !first {
+		return nil, ErrTokenRevoked
+	}
+	return utils.GenerateTokenPair(user.ID, user.Username, user.Role)
+}
+
+// Revoke 吊销令牌
+func (t *tokenService) Revoke(ctx context.Context, claims *utils.JWTClaims) error {
+	//早期签发的令牌没有jti 只能等待自然过期
+	if claims.ID == "" {
+		return nil
+	}
+	_, err := t.revoke(ctx, claims)
+	return err
+}
+
+// revoke 把令牌的jti写入吊销列表 过期时间为令牌剩余有效期
+// 返回 false 表示该令牌此前已被吊销
+func (t *tokenService) revoke(ctx context.Context, claims *utils.JWTClaims) (bool, error) {
+	if claims.ID == "" {
+		return false, errors.New("token has no jti")
+	}
+	ttl := time.Minute
+	if claims.ExpiresAt != nil {
+		ttl = time.Until(claims.ExpiresAt.Time)
+	}
+	if ttl <= 0 {
+		return false, nil
+	}
+	return t.redisClient.SetNX(ctx, fmt.Sprintf(constant.RedisKeyTokenDenylist, claims.ID), 1, ttl).Result()
+}
+
+// IsRevoked 判断令牌是否在吊销列表中
+func (t *tokenService) IsRevoked(ctx context.Context, claims *utils.JWTClaims) (bool, error) {
+	//早期签发的令牌没有jti 无法吊销
+	if claims.ID == "" {
+		return false, nil
+	}
+	n, err := t.redisClient.Exists(ctx, fmt.Sprintf(constant.RedisKeyTokenDenylist, claims.ID)).Result()
+	if err != nil {
+		return false, err
+	}
+	return n > 0, nil
+}
@@ -5,6 +5,7 @@ import (
 	"04blog/repositories"
 	"04blog/utils"
 	"errors"
+	"log"
 
 	"github.com/go-redis/redis/v8"
 )
@@ -15,7 +16,7 @@ type UserService interface {
 	// RegisterUser 注册用户
 	RegisterUser(user *models.User) error
 	// LoginUser 登录用户
-	LoginUser(username, password string) (string, *models.User, error)
+	LoginUser(username, password string) (*utils.TokenPair, *models.User, error)
 	// GetUserByID 根据用户ID获取用户信息
 	GetUserByID(userID int64) (*models.User, error)
 }
@@ -47,7 +48,11 @@ func (u *userService) RegisterUser(user *models.User) error {
 		}
 	}
 	//密码加密
-	user.Password = utils.GetMD5Hash(user.Password)
+	hash, err := utils.HashPassword(user.Password)
+	if err != nil {
+		return err
+	}
+	user.Password = hash
 
 	//2.创建用户
 	return u.userDao.CreateUser(user)
@@ -55,26 +60,37 @@ func (u *userService) RegisterUser(user *models.User) error {
 
 //用户登录
 
-func (u *userService) LoginUser(usernameOrMobile string, password string) (string, *models.User, error) {
+func (u *userService) LoginUser(usernameOrMobile string, password string) (*utils.TokenPair, *models.User, error) {
 	//1.检查用户是否存在（先尝试用户名）
 	existingUser, err := u.userDao.GetUserByUsername(usernameOrMobile)
 	if err != nil || existingUser == nil {
 		//尝试手机号
 		existingUser, err = u.userDao.GetUserByMobile(usernameOrMobile)
 		if err != nil || existingUser == nil {
-			return "", nil, errors.New("用户名或密码错误")
+			return nil, nil, errors.New("用户名或密码错误")
 		}
 	}
 	//2.检查密码是否正确
-	if !utils.CheckPasswordHash(password, existingUser.Password) {
-		return "", nil, errors.New("密码错误")
+	ok, needsRehash, err := utils.VerifyPassword(password, existingUser.Password)
+	if err != nil || !ok {
+		return nil, nil, errors.New("密码错误")
+	}
+	//旧算法（如MD5）或旧参数的哈希 在登录成功后透明升级
+	if needsRehash {
+		if hash, err := utils.HashPassword(password); err != nil {
+			log.Printf("用户 %d 密码重新哈希失败: %v", existingUser.ID, err)
+		} else if err := u.userDao.UpdatePassword(existingUser.ID, hash); err != nil {
+			log.Printf("用户 %d 密码哈希升级失败: %v", existingUser.ID, err)
+		} else {
+			existingUser.Password = hash
+		}
 	}
 	//3.生成token
-	token, err := utils.GenerateToken(existingUser.ID, existingUser.Username)
+	tokens, err := utils.GenerateTokenPair(existingUser.ID, existingUser.Username, existingUser.Role)
 	if err != nil {
-		return "", nil, err
+		return nil, nil, err
 	}
-	return token, existingUser, nil
+	return tokens, existingUser, nil
 }
 
 // GetUserByID implements UserService.
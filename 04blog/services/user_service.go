@@ -6,6 +6,7 @@ import (
 	"04blog/utils"
 	"context"
 	"errors"
+	"log"
 )
 
 // UserInfo 用户信息结构体
@@ -53,7 +54,11 @@ func (s *userService) RegisterUser(ctx context.Context, user *models.User) error
 		}
 	}
 	//密码加密
-	user.Password = utils.GetMD5Hash(user.Password)
+	hash, err := utils.HashPassword(user.Password)
+	if err != nil {
+		return err
+	}
+	user.Password = hash
 
 	//2.创建用户
 	return s.userRepo.CreateUser(user)
@@ -71,11 +76,22 @@ func (s *userService) LoginUser(ctx context.Context, usernameOrMobile, password
 		}
 	}
 	//2.检查密码是否正确
-	if !utils.CheckPasswordHash(password, existingUser.Password) {
+	ok, needsRehash, err := utils.VerifyPassword(password, existingUser.Password)
+	if err != nil || !ok {
 		return "", nil, errors.New("用户名或密码错误")
 	}
+	//旧算法（如MD5）或旧参数的哈希 在登录成功后透明升级
+	if needsRehash {
+		if hash, err := utils.HashPassword(password); err != nil {
+			log.Printf("用户 %d 密码重新哈希失败: %v", existingUser.ID, err)
+		} else if err := s.userRepo.UpdatePassword(existingUser.ID, hash); err != nil {
+			log.Printf("用户 %d 密码哈希升级失败: %v", existingUser.ID, err)
+		} else {
+			existingUser.Password = hash
+		}
+	}
 	//3.生成token
-	token, err := utils.GenerateToken(existingUser.ID, existingUser.Username)
+	token, err := utils.GenerateToken(existingUser.ID, existingUser.Username, existingUser.Role)
 	if err != nil {
 		return "", nil, err
 	}
@@ -1,59 +1,170 @@
 package utils
 
 import (
+	"crypto/rand"
+	"encoding/hex"
 	"errors"
+	"fmt"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
 )
 
-// JWT_SECRET 密钥
-const JWT_SECRET = "moon_zhang"
+// 令牌类型
+const (
+	TokenTypeAccess  = "access"
+	TokenTypeRefresh = "refresh"
+)
+
+// JWTOptions JWT签名配置
+type JWTOptions struct {
+	KeyID       string            //当前签名密钥ID 写入令牌头部 kid
+	Secret      string            //当前签名密钥
+	RetiredKeys map[string]string //已轮换下线的密钥 kid -> secret 只用于校验未过期的旧令牌
+	AccessTTL   time.Duration     //访问令牌有效期
+	RefreshTTL  time.Duration     //刷新令牌有效期
+}
+
+// jwtOptions 由 InitJWT 根据配置设置
+var jwtOptions = JWTOptions{
+	AccessTTL:  2 * time.Hour,
+	RefreshTTL: 7 * 24 * time.Hour,
+}
+
+// InitJWT 设置JWT签名密钥和令牌有效期
+func InitJWT(opts JWTOptions) {
+	if opts.AccessTTL <= 0 {
+		opts.AccessTTL = jwtOptions.AccessTTL
+	}
+	if opts.RefreshTTL <= 0 {
+		opts.RefreshTTL = jwtOptions.RefreshTTL
+	}
+	jwtOptions = opts
+}
 
 type JWTClaims struct {
-	UserID   int64  `json:"user_id"`
-	Username string `json:"username"`
+	UserID    int64  `json:"user_id"`
+	Username  string `json:"username"`
+	Role      int    `json:"role"`
+	TokenType string `json:"token_type"`
 	jwt.RegisteredClaims
 }
 
-//生产令牌
+// TokenPair 访问令牌和刷新令牌
+type TokenPair struct {
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token"`
+	// 访问令牌剩余有效秒数
+	ExpiresIn int64 `json:"expires_in"`
+}
 
-func GenerateToken(userID int64, username string) (string, error) {
-	//设置过期时间
-	expirationTime := time.Now().Add(24 * time.Hour)
-	//设置secret key
+// GenerateTokenPair 生成访问令牌和刷新令牌
+func GenerateTokenPair(userID int64, username string, role int) (*TokenPair, error) {
+	access, err := generateToken(userID, username, role, TokenTypeAccess, jwtOptions.AccessTTL)
+	if err != nil {
+		return nil, err
+	}
+	refresh, err := generateToken(userID, username, role, TokenTypeRefresh, jwtOptions.RefreshTTL)
+	if err != nil {
+		return nil, err
+	}
+	return &TokenPair{
+		AccessToken:  access,
+		RefreshToken: refresh,
+		ExpiresIn:    int64(jwtOptions.AccessTTL / time.Second),
+	}, nil
+}
+
+// GenerateToken 生成访问令牌
+func GenerateToken(userID int64, username string, role int) (string, error) {
+	return generateToken(userID, username, role, TokenTypeAccess, jwtOptions.AccessTTL)
+}
+
+func generateToken(userID int64, username string, role int, tokenType string, ttl time.Duration) (string, error) {
+	if jwtOptions.Secret == "" {
+		return "", errors.New("jwt secret is not initialized")
+	}
+	jti, err := newTokenID()
+	if err != nil {
+		return "", err
+	}
+	now := time.Now()
 	claims := JWTClaims{
-		UserID:   userID,
-		Username: username,
+		UserID:    userID,
+		Username:  username,
+		Role:      role,
+		TokenType: tokenType,
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(expirationTime),
+			ID:        jti,
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(ttl)),
 			Subject:   username,
 			//设置签发人
 			Issuer: "blog",
 		},
 	}
-	//创建令牌
+	//创建令牌 头部记录密钥ID 便于轮换
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	//签名令牌
-	tokenString, err := token.SignedString([]byte(JWT_SECRET))
-	if err != nil {
-		return "", err
+	if jwtOptions.KeyID != "" {
+		token.Header["kid"] = jwtOptions.KeyID
 	}
-	return tokenString, nil
+	//签名令牌
+	return token.SignedString([]byte(jwtOptions.Secret))
 }
 
-// 解析令牌
+// ParseToken 解析访问令牌
 func ParseToken(tokenString string) (*JWTClaims, error) {
+	return parseToken(tokenString, TokenTypeAccess)
+}
+
+// ParseRefreshToken 解析刷新令牌
+func ParseRefreshToken(tokenString string) (*JWTClaims, error) {
+	return parseToken(tokenString, TokenTypeRefresh)
+}
+
+func parseToken(tokenString, tokenType string) (*JWTClaims, error) {
 	//解析令牌
-	token, err := jwt.ParseWithClaims(tokenString, &JWTClaims{}, func(token *jwt.Token) (interface{}, error) {
-		return []byte(JWT_SECRET), nil
-	})
+	token, err := jwt.ParseWithClaims(tokenString, &JWTClaims{}, keyFunc,
+		jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}))
 	if err != nil {
 		return nil, err
 	}
 	//验证令牌
-	if claims, ok := token.Claims.(*JWTClaims); ok && token.Valid {
-		return claims, nil
+	claims, ok := token.Claims.(*JWTClaims)
+	if !ok || !token.Valid {
+		return nil, errors.New("invalid token")
+	}
+	//早期签发的令牌没有 token_type 视为访问令牌
+	actual := claims.TokenType
+	if actual == "" {
+		actual = TokenTypeAccess
+	}
+	if actual != tokenType {
+		return nil, fmt.Errorf("unexpected token type %q", claims.TokenType)
+	}
+	return claims, nil
+}
+
+// keyFunc 根据令牌头部的 kid 选择校验密钥 没有 kid 的旧令牌使用当前密钥
+func keyFunc(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+	if kid == "" || kid == jwtOptions.KeyID {
+		if jwtOptions.Secret == "" {
+			return nil, errors.New("jwt secret is not initialized")
+		}
+		return []byte(jwtOptions.Secret), nil
+	}
+	if secret, ok := jwtOptions.RetiredKeys[kid]; ok {
+		return []byte(secret), nil
+	}
+	return nil, fmt.Errorf("unknown key id %q", kid)
+}
+
+// newTokenID 生成随机的令牌ID jti
+func newTokenID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
 	}
-	return nil, errors.New("invalid token")
+	return hex.EncodeToString(b), nil
 }
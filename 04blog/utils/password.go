@@ -0,0 +1,242 @@
+package utils
+
+import (
+	"crypto/md5"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// ErrUnknownHashFormat 无法识别的密码哈希格式
+var ErrUnknownHashFormat = errors.New("unknown password hash format")
+
+// PasswordHasher 密码哈希算法接口
+// 哈希结果为自描述格式，记录了算法和参数，例如：
+//
+//	bcrypt:   $2a$10$...
+//	argon2id: $argon2id$v=19$m=65536,t=3,p=2$<salt>$<hash>
+type PasswordHasher interface {
+	// Name 算法名称
+	Name() string
+	// Hash 计算密码哈希
+	Hash(password string) (string, error)
+	// Verify 校验密码是否与哈希匹配
+	Verify(password, encoded string) (bool, error)
+	// Recognizes 判断哈希是否由该算法生成
+	Recognizes(encoded string) bool
+	// NeedsRehash 判断哈希的参数是否落后于当前配置
+	NeedsRehash(encoded string) bool
+}
+
+// BcryptHasher bcrypt 实现
+type BcryptHasher struct {
+	Cost int
+}
+
+// NewBcryptHasher 创建 bcrypt 哈希器 cost 非法时使用默认值
+func NewBcryptHasher(cost int) *BcryptHasher {
+	if cost < bcrypt.MinCost || cost > bcrypt.MaxCost {
+		cost = bcrypt.DefaultCost
+	}
+	return &BcryptHasher{Cost: cost}
+}
+
+func (h *BcryptHasher) Name() string { return "bcrypt" }
+
+func (h *BcryptHasher) Hash(password string) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), h.Cost)
+	if err != nil {
+		return "", err
+	}
+	return string(hash), nil
+}
+
+func (h *BcryptHasher) Verify(password, encoded string) (bool, error) {
+	err := bcrypt.CompareHashAndPassword([]byte(encoded), []byte(password))
+	if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+func (h *BcryptHasher) Recognizes(encoded string) bool {
+	return strings.HasPrefix(encoded, "$2a$") || strings.HasPrefix(encoded, "$2b$") || strings.HasPrefix(encoded, "$2y$")
+}
+
+func (h *BcryptHasher) NeedsRehash(encoded string) bool {
+	cost, err := bcrypt.Cost([]byte(encoded))
+	return err != nil || cost != h.Cost
+}
+
+// Argon2idHasher argon2id 实现
+type Argon2idHasher struct {
+	Memory      uint32 //内存 KiB
+	Iterations  uint32 //迭代次数
+	Parallelism uint8  //并行度
+	SaltLength  uint32 //盐长度
+	KeyLength   uint32 //哈希长度
+}
+
+// NewArgon2idHasher 创建使用推荐参数的 argon2id 哈希器
+func NewArgon2idHasher() *Argon2idHasher {
+	return &Argon2idHasher{
+		Memory:      64 * 1024,
+		Iterations:  3,
+		Parallelism: 2,
+		SaltLength:  16,
+		KeyLength:   32,
+	}
+}
+
+func (h *Argon2idHasher) Name() string { return "argon2id" }
+
+func (h *Argon2idHasher) Hash(password string) (string, error) {
+	salt := make([]byte, h.SaltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+	key := argon2.IDKey([]byte(password), salt, h.Iterations, h.Memory, h.Parallelism, h.KeyLength)
+	return fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s",
+		argon2.Version, h.Memory, h.Iterations, h.Parallelism,
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(key)), nil
+}
+
+func (h *Argon2idHasher) Verify(password, encoded string) (bool, error) {
+	p, salt, key, err := decodeArgon2id(encoded)
+	if err != nil {
+		return false, err
+	}
+	other := argon2.IDKey([]byte(password), salt, p.Iterations, p.Memory, p.Parallelism, uint32(len(key)))
+	return subtle.ConstantTimeCompare(key, other) == 1, nil
+}
+
+func (h *Argon2idHasher) Recognizes(encoded string) bool {
+	return strings.HasPrefix(encoded, "$argon2id$")
+}
+
+func (h *Argon2idHasher) NeedsRehash(encoded string) bool {
+	p, salt, key, err := decodeArgon2id(encoded)
+	if err != nil {
+		return true
+	}
+	return p.Memory != h.Memory || p.Iterations != h.Iterations || p.Parallelism != h.Parallelism ||
+		uint32(len(salt)) != h.SaltLength || uint32(len(key)) != h.KeyLength
+}
+
+// decodeArgon2id 解析 $argon2id$v=19$m=65536,t=3,p=2$<salt>$<hash>
+func decodeArgon2id(encoded string) (*Argon2idHasher, []byte, []byte, error) {
+	parts := strings.Split(encoded, "$")
+	if len(parts) != 6 || parts[1] != "argon2id" {
+		return nil, nil, nil, ErrUnknownHashFormat
+	}
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil {
+		return nil, nil, nil, err
+	}
+	if version != argon2.Version {
+		return nil, nil, nil, fmt.Errorf("unsupported argon2 version %d", version)
+	}
+	p := &Argon2idHasher{}
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &p.Memory, &p.Iterations, &p.Parallelism); err != nil {
+		return nil, nil, nil, err
+	}
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	key, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return p, salt, key, nil
+}
+
+// legacyMD5Hasher 旧版无盐 MD5 只用于校验存量密码 校验通过后总是需要重新哈希
+type legacyMD5Hasher struct{}
+
+func (legacyMD5Hasher) Name() string { return "md5" }
+
+func (legacyMD5Hasher) Hash(password string) (string, error) {
+	return "", errors.New("md5 is only supported for verifying legacy passwords")
+}
+
+func (legacyMD5Hasher) Verify(password, encoded string) (bool, error) {
+	sum := md5.Sum([]byte(password))
+	return subtle.ConstantTimeCompare([]byte(hex.EncodeToString(sum[:])), []byte(strings.ToLower(encoded))) == 1, nil
+}
+
+func (legacyMD5Hasher) Recognizes(encoded string) bool {
+	if len(encoded) != md5.Size*2 {
+		return false
+	}
+	_, err := hex.DecodeString(encoded)
+	return err == nil
+}
+
+func (legacyMD5Hasher) NeedsRehash(encoded string) bool { return true }
+
+// passwordHasher 当前用于生成新哈希的算法
+var passwordHasher PasswordHasher = NewArgon2idHasher()
+
+// knownHashers 所有可用于校验的算法
+var knownHashers = []PasswordHasher{NewArgon2idHasher(), NewBcryptHasher(bcrypt.DefaultCost), legacyMD5Hasher{}}
+
+// SetPasswordHasher 设置生成新密码哈希使用的算法
+func SetPasswordHasher(h PasswordHasher) {
+	passwordHasher = h
+}
+
+// NewPasswordHasher 根据算法名称创建哈希器
+func NewPasswordHasher(algorithm string) (PasswordHasher, error) {
+	switch strings.ToLower(algorithm) {
+	case "", "argon2id":
+		return NewArgon2idHasher(), nil
+	case "bcrypt":
+		return NewBcryptHasher(bcrypt.DefaultCost), nil
+	default:
+		return nil, fmt.Errorf("unsupported password hash algorithm %q", algorithm)
+	}
+}
+
+// HashPassword 使用当前算法计算密码哈希
+func HashPassword(password string) (string, error) {
+	return passwordHasher.Hash(password)
+}
+
+// VerifyPassword 按哈希自身记录的算法校验密码
+// needsRehash 为 true 表示密码正确但哈希使用了旧算法或旧参数 应在登录成功后重新哈希
+func VerifyPassword(password, encoded string) (ok bool, needsRehash bool, err error) {
+	h := hasherFor(encoded)
+	if h == nil {
+		return false, false, ErrUnknownHashFormat
+	}
+	ok, err = h.Verify(password, encoded)
+	if err != nil || !ok {
+		return false, false, err
+	}
+	if h.Name() != passwordHasher.Name() {
+		return true, true, nil
+	}
+	return true, passwordHasher.NeedsRehash(encoded), nil
+}
+
+// hasherFor 找到能识别该哈希格式的算法
+func hasherFor(encoded string) PasswordHasher {
+	if passwordHasher.Recognizes(encoded) {
+		return passwordHasher
+	}
+	for _, h := range knownHashers {
+		if h.Recognizes(encoded) {
+			return h
+		}
+	}
+	return nil
+}
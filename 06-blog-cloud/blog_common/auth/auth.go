@@ -0,0 +1,57 @@
+package auth
+
+import (
+	"context"
+	"errors"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// 用户角色 数据库和 UserService 接口中都使用名称
+const (
+	RoleUser  = "user"
+	RoleAdmin = "admin"
+)
+
+// Claims 网关签发的令牌内容 与网关的 utils.JWTClaims 一致
+type Claims struct {
+	UserID   int64  `json:"user_id"`
+	Username string `json:"username"`
+	jwt.RegisteredClaims
+}
+
+// ParseToken 用 secret 校验 HS256 令牌 过期或签名不一致时返回错误
+func ParseToken(secret, tokenString string) (*Claims, error) {
+	claims := &Claims{}
+	token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
+		return []byte(secret), nil
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}))
+	if err != nil {
+		return nil, err
+	}
+	if !token.Valid || claims.UserID <= 0 {
+		return nil, errors.New("invalid token")
+	}
+	return claims, nil
+}
+
+// Caller 发起请求的用户 由 API 服务或 RPC 服务校验令牌后放入 ctx
+type Caller struct {
+	UserID   int64
+	Username string
+	// Token 原始令牌 调用 RPC 服务时通过 UnaryClientInterceptor 传递
+	Token string
+}
+
+type callerKey struct{}
+
+// WithCaller 把调用方放入 ctx
+func WithCaller(ctx context.Context, caller *Caller) context.Context {
+	return context.WithValue(ctx, callerKey{}, caller)
+}
+
+// CallerFrom 返回 ctx 中的调用方 未经过认证时返回 false
+func CallerFrom(ctx context.Context) (*Caller, bool) {
+	caller, ok := ctx.Value(callerKey{}).(*Caller)
+	return caller, ok && caller != nil
+}
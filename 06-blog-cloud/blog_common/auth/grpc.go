@@ -0,0 +1,57 @@
+package auth
+
+import (
+	"context"
+	"strings"
+
+	"blog-common/errorx"
+
+	"github.com/zeromicro/go-zero/core/logx"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// metadataAuthorization gRPC metadata 中的令牌 键必须小写
+// 传递的是网关签发的原始令牌 RPC 服务自己校验 不信任调用方声明的用户ID
+const metadataAuthorization = "authorization"
+
+// UnaryClientInterceptor 调用 zrpc 服务时把调用方的令牌写入 gRPC metadata
+// 通过 zrpc.WithUnaryClientInterceptor 注册 ctx 中没有调用方时不写入
+func UnaryClientInterceptor(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn,
+	invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+	if caller, ok := CallerFrom(ctx); ok && caller.Token != "" {
+		ctx = metadata.AppendToOutgoingContext(ctx, metadataAuthorization, "Bearer "+caller.Token)
+	}
+	return invoker(ctx, method, req, reply, cc, opts...)
+}
+
+// NewUnaryServerInterceptor zrpc 服务校验 gRPC metadata 中的令牌 通过后把调用方放入 ctx
+// 通过 s.AddUnaryInterceptors 注册 publicMethods 中的方法没有令牌时也可以调用 带了令牌仍然会校验
+func NewUnaryServerInterceptor(secret string, publicMethods ...string) grpc.UnaryServerInterceptor {
+	public := make(map[string]bool, len(publicMethods))
+	for _, m := range publicMethods {
+		public[m] = true
+	}
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		var tokenString string
+		if md, ok := metadata.FromIncomingContext(ctx); ok {
+			if values := md.Get(metadataAuthorization); len(values) > 0 {
+				tokenString, _ = strings.CutPrefix(values[0], "Bearer ")
+			}
+		}
+		if tokenString == "" {
+			if public[info.FullMethod] {
+				return handler(ctx, req)
+			}
+			return nil, errorx.New(errorx.CodeUnauthorized, "缺少令牌")
+		}
+
+		claims, err := ParseToken(secret, tokenString)
+		if err != nil {
+			logx.WithContext(ctx).Infof("令牌校验失败 %s: %v", info.FullMethod, err)
+			return nil, errorx.New(errorx.CodeUnauthorized, "无效的令牌")
+		}
+		ctx = WithCaller(ctx, &Caller{UserID: claims.UserID, Username: claims.Username, Token: tokenString})
+		return handler(ctx, req)
+	}
+}
@@ -0,0 +1,163 @@
+// Package convgen 根据结构体字段生成类型转换函数
+// 模型、proto 和 API 类型之间的转换都由它生成 字段名忽略大小写匹配
+// 两边的字段必须一一对应 不需要转换的字段要写在 Skip 中
+// 任何一边增删字段而没有处理时生成失败 这样各层的类型不会悄悄地不一致
+package convgen
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// Pair 一个转换函数 生成 func Func(in *From) *To
+type Pair struct {
+	// Func 生成的函数名
+	Func string
+	// From 源类型的零值 例如 models.User{}
+	From any
+	// To 目标类型的零值
+	To any
+	// Skip 不参与转换的字段名 源和目标类型中的都可以写
+	Skip []string
+}
+
+// field 结构体中可以直接访问的字段 嵌入结构体的字段已展开
+type field struct {
+	name string
+	typ  reflect.Type
+}
+
+// WriteFile 生成转换函数并写入 path
+func WriteFile(path, pkg string, pairs ...Pair) error {
+	src, err := Generate(pkg, pairs...)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, src, 0o644)
+}
+
+// Generate 生成包名为 pkg 的转换函数源码
+func Generate(pkg string, pairs ...Pair) ([]byte, error) {
+	imports := make(map[string]bool)
+	var body bytes.Buffer
+	for _, p := range pairs {
+		from, to := reflect.TypeOf(p.From), reflect.TypeOf(p.To)
+		if from == nil || to == nil || from.Kind() != reflect.Struct || to.Kind() != reflect.Struct {
+			return nil, fmt.Errorf("convgen: %s 的 From 和 To 必须是结构体", p.Func)
+		}
+		imports[from.PkgPath()] = true
+		imports[to.PkgPath()] = true
+		if err := writeFunc(&body, p, from, to); err != nil {
+			return nil, err
+		}
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString("// Code generated by blog-common/convgen. DO NOT EDIT.\n")
+	buf.WriteString("// 字段变化后执行 go generate 重新生成\n\n")
+	fmt.Fprintf(&buf, "package %s\n\n", pkg)
+	paths := make([]string, 0, len(imports))
+	for path := range imports {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	buf.WriteString("import (\n")
+	for _, path := range paths {
+		fmt.Fprintf(&buf, "\t%q\n", path)
+	}
+	buf.WriteString(")\n")
+	buf.Write(body.Bytes())
+
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("convgen: 格式化生成的代码失败: %w", err)
+	}
+	return src, nil
+}
+
+func writeFunc(w *bytes.Buffer, p Pair, from, to reflect.Type) error {
+	skip := make(map[string]bool, len(p.Skip))
+	for _, name := range p.Skip {
+		skip[strings.ToLower(name)] = true
+	}
+	sources := make(map[string]field)
+	for _, f := range fields(from) {
+		sources[strings.ToLower(f.name)] = f
+	}
+
+	fmt.Fprintf(w, "\n// %s %s 转换为 %s\n", p.Func, from, to)
+	fmt.Fprintf(w, "func %s(in *%s) *%s {\n", p.Func, from, to)
+	fmt.Fprintf(w, "if in == nil {\nreturn nil\n}\n")
+	fmt.Fprintf(w, "out := &%s{}\n", to)
+
+	used := make(map[string]bool)
+	for _, dst := range fields(to) {
+		key := strings.ToLower(dst.name)
+		if skip[key] {
+			continue
+		}
+		src, ok := sources[key]
+		if !ok {
+			return fmt.Errorf("convgen: %s: %s 的字段 %s 在 %s 中没有对应字段", p.Func, to, dst.name, from)
+		}
+		expr, err := convert("in."+src.name, src.typ, dst.typ)
+		if err != nil {
+			return fmt.Errorf("convgen: %s: 字段 %s: %w", p.Func, dst.name, err)
+		}
+		fmt.Fprintf(w, "out.%s = %s\n", dst.name, expr)
+		used[key] = true
+	}
+	for _, src := range fields(from) {
+		key := strings.ToLower(src.name)
+		if !skip[key] && !used[key] {
+			return fmt.Errorf("convgen: %s: %s 的字段 %s 在 %s 中没有对应字段", p.Func, from, src.name, to)
+		}
+	}
+
+	w.WriteString("return out\n}\n")
+	return nil
+}
+
+// fields 返回可以通过 t 直接访问的导出字段 嵌入的结构体展开为它的字段
+// proto 生成的 state sizeCache 等未导出字段不参与转换
+func fields(t reflect.Type) []field {
+	var out []field
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.Anonymous && f.Type.Kind() == reflect.Struct {
+			out = append(out, fields(f.Type)...)
+			continue
+		}
+		if !f.IsExported() {
+			continue
+		}
+		out = append(out, field{name: f.Name, typ: f.Type})
+	}
+	return out
+}
+
+// convert 返回把 from 类型的 expr 赋值给 to 类型的表达式 只处理相同类型和数字类型之间的转换
+func convert(expr string, from, to reflect.Type) (string, error) {
+	if from == to {
+		return expr, nil
+	}
+	if isNumber(from) && isNumber(to) && to.PkgPath() == "" {
+		return fmt.Sprintf("%s(%s)", to, expr), nil
+	}
+	return "", fmt.Errorf("不支持从 %s 转换为 %s", from, to)
+}
+
+func isNumber(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
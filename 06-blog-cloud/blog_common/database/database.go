@@ -0,0 +1,75 @@
+// Package database 打开 RPC 服务使用的 MySQL 连接
+package database
+
+import (
+	"log"
+	"os"
+	"time"
+
+	"blog-common/metrics"
+
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// Config 数据库配置 对应服务配置文件中的 MySQL
+type Config struct {
+	// 连接字符串格式："username:password@tcp(host:port)/dbname?charset=utf8mb4&parseTime=True&loc=Local"
+	DSN string
+	// 启动时执行未执行过的迁移
+	IsAutoMigrate bool `json:",optional"`
+	// 连接池 最大连接数、最大空闲连接数、连接最长使用时间和最长空闲时间
+	MaxOpenConns    int           `json:",default=100"`
+	MaxIdleConns    int           `json:",default=10"`
+	ConnMaxLifetime time.Duration `json:",default=1h"`
+	ConnMaxIdleTime time.Duration `json:",default=10m"`
+}
+
+// Open 打开数据库连接 设置连接池并注册 gorm 指标插件
+// 开启 TranslateError 唯一索引冲突返回 gorm.ErrDuplicatedKey
+func Open(c Config) (*gorm.DB, error) {
+	//数据库操作 日志设置
+	newLogger := logger.New(
+		log.New(os.Stdout, "\r\n", log.LstdFlags), // io writer
+		logger.Config{
+			SlowThreshold:             time.Second, // Slow SQL threshold
+			LogLevel:                  logger.Info, // Log level
+			IgnoreRecordNotFoundError: true,        // Ignore ErrRecordNotFound error for logger
+			ParameterizedQueries:      true,        // Don't include params in the SQL log
+			Colorful:                  true,        // Disable color
+		},
+	)
+
+	db, err := gorm.Open(mysql.Open(c.DSN), &gorm.Config{
+		Logger:         newLogger,
+		TranslateError: true,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, err
+	}
+	sqlDB.SetMaxOpenConns(c.MaxOpenConns)
+	sqlDB.SetMaxIdleConns(c.MaxIdleConns)
+	sqlDB.SetConnMaxLifetime(c.ConnMaxLifetime)
+	sqlDB.SetConnMaxIdleTime(c.ConnMaxIdleTime)
+
+	// 语句耗时和错误数 由 DevServer 的 /metrics 输出
+	if err := db.Use(metrics.NewGormPlugin()); err != nil {
+		return nil, err
+	}
+	return db, nil
+}
+
+// MustOpen 打开数据库连接 失败时退出
+func MustOpen(c Config) *gorm.DB {
+	db, err := Open(c)
+	if err != nil {
+		log.Fatalf("failed to connect database: %v", err)
+	}
+	return db
+}
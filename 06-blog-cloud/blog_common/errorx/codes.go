@@ -0,0 +1,29 @@
+package errorx
+
+// 错误码的前三位是 HTTP 状态码 后两位是业务序号 00 为通用错误
+const (
+	CodeOK = 0
+
+	CodeInvalidParam    = 40000
+	CodeUnauthorized    = 40100
+	CodeForbidden       = 40300
+	CodeNotFound        = 40400
+	CodeConflict        = 40900
+	CodeTooManyRequests = 42900
+	CodeInternal        = 50000
+	CodeNotImplemented  = 50100
+	CodeUnavailable     = 50300
+	CodeTimeout         = 50400
+)
+
+// 用户服务错误码
+const (
+	CodeInvalidCredentials = 40101
+	CodeUserNotFound       = 40401
+	CodeUserExists         = 40901
+)
+
+// 文章服务错误码
+const (
+	CodePostNotFound = 40411
+)
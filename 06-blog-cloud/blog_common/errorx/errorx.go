@@ -0,0 +1,151 @@
+package errorx
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/zeromicro/go-zero/core/logx"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// domain gRPC ErrorInfo 的 Domain 用来识别本项目服务返回的业务错误码
+const domain = "blog"
+
+// CodeError 带业务错误码的错误
+// zrpc 服务直接返回 CodeError 即可 grpc 通过 GRPCStatus 转换为对应的状态码 业务错误码放在 ErrorInfo 中传给调用方
+type CodeError struct {
+	Code    int
+	Message string
+}
+
+// New 创建业务错误
+func New(code int, message string) *CodeError {
+	return &CodeError{Code: code, Message: message}
+}
+
+// Newf 按格式创建业务错误
+func Newf(code int, format string, args ...interface{}) *CodeError {
+	return &CodeError{Code: code, Message: fmt.Sprintf(format, args...)}
+}
+
+// NewParamError 参数错误 用于包装 httpx.Parse 等返回的错误
+func NewParamError(err error) *CodeError {
+	return &CodeError{Code: CodeInvalidParam, Message: err.Error()}
+}
+
+// Error 实现error接口
+func (e *CodeError) Error() string {
+	return e.Message
+}
+
+// HTTPStatus 错误码对应的 HTTP 状态码
+func (e *CodeError) HTTPStatus() int {
+	if status := e.Code / 100; status >= 400 && status < 600 {
+		return status
+	}
+	return http.StatusInternalServerError
+}
+
+// GRPCStatus 实现 grpc status.FromError 识别的接口
+func (e *CodeError) GRPCStatus() *status.Status {
+	st := status.New(grpcCode(e.HTTPStatus()), e.Message)
+	if detailed, err := st.WithDetails(&errdetails.ErrorInfo{
+		Reason: strconv.Itoa(e.Code),
+		Domain: domain,
+	}); err == nil {
+		return detailed
+	}
+	return st
+}
+
+// FromError 把任意错误转换为 CodeError
+// gRPC 状态错误优先使用 ErrorInfo 中的业务错误码 其他服务返回的按状态码映射为通用错误码
+// 服务端错误不透出原始信息
+func FromError(err error) *CodeError {
+	var ce *CodeError
+	if errors.As(err, &ce) {
+		return ce
+	}
+	st, ok := status.FromError(err)
+	if !ok {
+		return New(CodeInternal, "服务内部错误")
+	}
+	for _, detail := range st.Details() {
+		if info, ok := detail.(*errdetails.ErrorInfo); ok && info.Domain == domain {
+			if code, err := strconv.Atoi(info.Reason); err == nil {
+				return New(code, st.Message())
+			}
+		}
+	}
+	switch st.Code() {
+	case codes.InvalidArgument, codes.FailedPrecondition, codes.OutOfRange:
+		return New(CodeInvalidParam, st.Message())
+	case codes.Unauthenticated:
+		return New(CodeUnauthorized, st.Message())
+	case codes.PermissionDenied:
+		return New(CodeForbidden, st.Message())
+	case codes.NotFound:
+		return New(CodeNotFound, st.Message())
+	case codes.AlreadyExists, codes.Aborted:
+		return New(CodeConflict, st.Message())
+	case codes.ResourceExhausted:
+		return New(CodeTooManyRequests, "请求过于频繁")
+	case codes.Unimplemented:
+		return New(CodeNotImplemented, "接口未实现")
+	case codes.Unavailable:
+		return New(CodeUnavailable, "服务暂不可用")
+	case codes.DeadlineExceeded:
+		return New(CodeTimeout, "服务响应超时")
+	default:
+		return New(CodeInternal, "服务内部错误")
+	}
+}
+
+// grpcCode HTTP 状态码对应的 gRPC 状态码
+func grpcCode(httpStatus int) codes.Code {
+	switch httpStatus {
+	case http.StatusBadRequest:
+		return codes.InvalidArgument
+	case http.StatusUnauthorized:
+		return codes.Unauthenticated
+	case http.StatusForbidden:
+		return codes.PermissionDenied
+	case http.StatusNotFound:
+		return codes.NotFound
+	case http.StatusConflict:
+		return codes.AlreadyExists
+	case http.StatusTooManyRequests:
+		return codes.ResourceExhausted
+	case http.StatusNotImplemented:
+		return codes.Unimplemented
+	case http.StatusServiceUnavailable:
+		return codes.Unavailable
+	case http.StatusGatewayTimeout:
+		return codes.DeadlineExceeded
+	default:
+		return codes.Internal
+	}
+}
+
+// Response 错误响应 与网关的错误响应格式一致
+type Response struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// ErrorHandler go-zero 的错误处理器 通过 httpx.SetErrorHandlerCtx 注册
+// handler 中 httpx.ErrorCtx 的错误都会经过这里 按错误码设置 HTTP 状态码
+func ErrorHandler(ctx context.Context, err error) (int, any) {
+	var ce *CodeError
+	if !errors.As(err, &ce) {
+		// logic 没有转换的错误 原始信息只记录在日志中
+		logx.WithContext(ctx).Errorf("请求失败: %v", err)
+	}
+	e := FromError(err)
+	return e.HTTPStatus(), &Response{Code: e.Code, Message: e.Message}
+}
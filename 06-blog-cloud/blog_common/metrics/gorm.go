@@ -0,0 +1,89 @@
+package metrics
+
+import (
+	"errors"
+	"time"
+
+	"github.com/zeromicro/go-zero/core/metric"
+	"gorm.io/gorm"
+)
+
+// gormStartKey 语句开始时间在 gorm 实例中的键
+const gormStartKey = "metrics:start_time"
+
+var (
+	gormDuration = metric.NewHistogramVec(&metric.HistogramVecOpts{
+		Namespace: "gorm",
+		Subsystem: "client",
+		Name:      "duration_ms",
+		Help:      "gorm client requests duration(ms).",
+		Labels:    []string{"table", "operation"},
+		Buckets:   []float64{1, 2, 5, 10, 25, 50, 100, 250, 500, 1000, 2500},
+	})
+	gormErrors = metric.NewCounterVec(&metric.CounterVecOpts{
+		Namespace: "gorm",
+		Subsystem: "client",
+		Name:      "error_total",
+		Help:      "gorm client requests error count.",
+		Labels:    []string{"table", "operation"},
+	})
+)
+
+// GormPlugin 统计 gorm 语句耗时和错误数 通过 db.Use 注册
+// 指标由 go-zero 的 DevServer 在 /metrics 输出
+type GormPlugin struct{}
+
+// NewGormPlugin 创建 gorm 指标插件
+func NewGormPlugin() *GormPlugin {
+	return &GormPlugin{}
+}
+
+// Name 实现 gorm.Plugin 接口
+func (p *GormPlugin) Name() string {
+	return "metrics"
+}
+
+// Initialize 实现 gorm.Plugin 接口 在每类语句前后注册回调
+func (p *GormPlugin) Initialize(db *gorm.DB) error {
+	cb := db.Callback()
+	return errors.Join(
+		cb.Create().Before("gorm:create").Register("metrics:before_create", before),
+		cb.Create().After("gorm:create").Register("metrics:after_create", after("create")),
+		cb.Query().Before("gorm:query").Register("metrics:before_query", before),
+		cb.Query().After("gorm:query").Register("metrics:after_query", after("query")),
+		cb.Update().Before("gorm:update").Register("metrics:before_update", before),
+		cb.Update().After("gorm:update").Register("metrics:after_update", after("update")),
+		cb.Delete().Before("gorm:delete").Register("metrics:before_delete", before),
+		cb.Delete().After("gorm:delete").Register("metrics:after_delete", after("delete")),
+		cb.Row().Before("gorm:row").Register("metrics:before_row", before),
+		cb.Row().After("gorm:row").Register("metrics:after_row", after("row")),
+		cb.Raw().Before("gorm:raw").Register("metrics:before_raw", before),
+		cb.Raw().After("gorm:raw").Register("metrics:after_raw", after("raw")),
+	)
+}
+
+func before(db *gorm.DB) {
+	db.InstanceSet(gormStartKey, time.Now())
+}
+
+func after(operation string) func(*gorm.DB) {
+	return func(db *gorm.DB) {
+		v, ok := db.InstanceGet(gormStartKey)
+		if !ok {
+			return
+		}
+		start, ok := v.(time.Time)
+		if !ok {
+			return
+		}
+		table := db.Statement.Table
+		if table == "" {
+			table = "unknown"
+		}
+		gormDuration.Observe(time.Since(start).Milliseconds(), table, operation)
+		// 查不到记录是正常的业务结果 不计入错误
+		if db.Error != nil && !errors.Is(db.Error, gorm.ErrRecordNotFound) {
+			gormErrors.Inc(table, operation)
+		}
+	}
+}
@@ -0,0 +1,87 @@
+// Package migrate 按版本执行数据库迁移
+// 已执行的版本记录在 schema_migrations 表中 每个版本只执行一次
+package migrate
+
+import (
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Migration 一次数据库结构变更
+// 发布后不能再修改 需要调整时追加新的版本
+// Up 中不要使用会变化的业务模型 应使用迁移内定义的结构体 否则旧版本的结果会随模型变化
+type Migration struct {
+	Version     int64
+	Description string
+	Up          func(tx *gorm.DB) error
+}
+
+// schemaMigration 已执行的迁移
+type schemaMigration struct {
+	Version     int64     `gorm:"primaryKey;autoIncrement:false"`
+	Description string    `gorm:"type:varchar(255);not null"`
+	AppliedAt   time.Time `gorm:"not null"`
+}
+
+func (schemaMigration) TableName() string {
+	return "schema_migrations"
+}
+
+// Run 依次执行未执行过的迁移 migrations 必须按版本递增
+// 数据库中有程序不认识的版本时返回错误 说明数据库已被更新的程序迁移过
+// 每个版本在一个事务中执行 注意 MySQL 的 DDL 会隐式提交 失败时需要人工检查
+func Run(db *gorm.DB, migrations []Migration) error {
+	known := make(map[int64]bool, len(migrations))
+	for i, m := range migrations {
+		if m.Version <= 0 || m.Up == nil {
+			return fmt.Errorf("迁移 %d 无效", m.Version)
+		}
+		if i > 0 && m.Version <= migrations[i-1].Version {
+			return fmt.Errorf("迁移版本必须递增: %d 在 %d 之后", m.Version, migrations[i-1].Version)
+		}
+		known[m.Version] = true
+	}
+
+	if err := db.AutoMigrate(&schemaMigration{}); err != nil {
+		return fmt.Errorf("创建 schema_migrations 失败: %w", err)
+	}
+	var applied []schemaMigration
+	if err := db.Order("version").Find(&applied).Error; err != nil {
+		return fmt.Errorf("查询已执行的迁移失败: %w", err)
+	}
+	done := make(map[int64]bool, len(applied))
+	var latest int64
+	for _, a := range applied {
+		if !known[a.Version] {
+			return fmt.Errorf("数据库已迁移到版本 %d 当前程序不认识 请升级程序", a.Version)
+		}
+		done[a.Version] = true
+		latest = a.Version
+	}
+
+	for _, m := range migrations {
+		if done[m.Version] {
+			continue
+		}
+		if m.Version < latest {
+			return fmt.Errorf("迁移 %d 早于已执行的版本 %d 新的迁移只能追加在最后", m.Version, latest)
+		}
+		err := db.Transaction(func(tx *gorm.DB) error {
+			if err := m.Up(tx); err != nil {
+				return err
+			}
+			return tx.Create(&schemaMigration{
+				Version:     m.Version,
+				Description: m.Description,
+				AppliedAt:   time.Now(),
+			}).Error
+		})
+		if err != nil {
+			return fmt.Errorf("迁移 %d(%s) 失败: %w", m.Version, m.Description, err)
+		}
+		latest = m.Version
+	}
+	return nil
+}
@@ -0,0 +1,96 @@
+package tracing
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+
+	"github.com/zeromicro/go-zero/core/logx"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// HeaderRequestID 请求ID头 由网关生成 与网关的 tracing.HeaderRequestID 一致
+const HeaderRequestID = "X-Request-ID"
+
+// metadataRequestID gRPC metadata 中的请求ID 键必须小写
+const metadataRequestID = "x-request-id"
+
+// maxRequestIDLen 请求ID最大长度
+const maxRequestIDLen = 64
+
+type requestIDKey struct{}
+
+// WithRequestID 把请求ID放入 ctx 并加入 logx 字段 logx.WithContext(ctx) 输出的日志都会带上 request_id
+// trace 和 span 由 go-zero 根据 Telemetry 配置自动加入
+func WithRequestID(ctx context.Context, id string) context.Context {
+	ctx = context.WithValue(ctx, requestIDKey{}, id)
+	return logx.ContextWithFields(ctx, logx.Field("request_id", id))
+}
+
+// RequestID 返回 ctx 中的请求ID 没有时返回空字符串
+func RequestID(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// RequestIDMiddleware rest 服务的请求ID中间件 通过 server.Use 注册
+func RequestIDMiddleware(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(HeaderRequestID)
+		if !validRequestID(id) {
+			// 没有经过网关的请求由服务自己生成 经过网关时响应头由网关设置
+			id = newRequestID()
+			w.Header().Set(HeaderRequestID, id)
+		}
+		next(w, r.WithContext(WithRequestID(r.Context(), id)))
+	}
+}
+
+// UnaryClientInterceptor 调用 zrpc 服务时把请求ID写入 gRPC metadata
+// 通过 zrpc.WithUnaryClientInterceptor 注册
+func UnaryClientInterceptor(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn,
+	invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+	if id := RequestID(ctx); id != "" {
+		ctx = metadata.AppendToOutgoingContext(ctx, metadataRequestID, id)
+	}
+	return invoker(ctx, method, req, reply, cc, opts...)
+}
+
+// UnaryServerInterceptor zrpc 服务从 gRPC metadata 中取出请求ID 没有时生成一个
+// 通过 s.AddUnaryInterceptors 注册
+func UnaryServerInterceptor(ctx context.Context, req any, info *grpc.UnaryServerInfo,
+	handler grpc.UnaryHandler) (any, error) {
+	var id string
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if ids := md.Get(metadataRequestID); len(ids) > 0 && validRequestID(ids[0]) {
+			id = ids[0]
+		}
+	}
+	if id == "" {
+		id = newRequestID()
+	}
+	return handler(WithRequestID(ctx, id), req)
+}
+
+func newRequestID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// validRequestID 只接受字母、数字和 -_. 防止日志注入
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLen {
+		return false
+	}
+	for _, c := range id {
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9', c == '-', c == '_', c == '.':
+		default:
+			return false
+		}
+	}
+	return true
+}
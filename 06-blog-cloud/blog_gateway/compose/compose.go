@@ -0,0 +1,147 @@
+package compose
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"gateway/config"
+)
+
+// Composition 编译后的聚合路由
+type Composition struct {
+	Timeout time.Duration `json:"-"`
+	Parts   []*Part       `json:"parts"`
+}
+
+// Part 聚合路由的一个部分
+type Part struct {
+	Name     string   `json:"name"`
+	Upstream string   `json:"upstream"`
+	Path     string   `json:"path"`
+	Field    string   `json:"field,omitempty"`
+	Select   []string `json:"select,omitempty"`
+	Required bool     `json:"required,omitempty"`
+	// DependsOn 路径中引用的其他部分 这些部分完成后才发起请求
+	DependsOn []string `json:"depends_on,omitempty"`
+
+	template []token
+	deps     []int
+}
+
+// token 路径模板的组成部分 text、路由参数或其他部分结果中的字段
+type token struct {
+	text  string
+	param string
+	ref   int      // 引用的部分下标 -1 表示不是引用
+	field []string // 引用的字段路径
+}
+
+// New 编译聚合路由 params 为路由模式中的参数名
+// 检查路径模板引用的参数和部分是否存在 以及部分之间是否存在循环依赖
+func New(cfg config.CompositionConfig, params []string) (*Composition, error) {
+	c := &Composition{Timeout: time.Duration(cfg.Timeout) * time.Millisecond}
+	index := make(map[string]int, len(cfg.Parts))
+	for i, p := range cfg.Parts {
+		index[p.Name] = i
+	}
+	for _, p := range cfg.Parts {
+		part := &Part{
+			Name:     p.Name,
+			Upstream: p.Upstream,
+			Path:     p.Path,
+			Field:    p.Field,
+			Select:   p.Select,
+			Required: p.Required,
+		}
+		template, err := parseTemplate(p.Path, params, index)
+		if err != nil {
+			return nil, fmt.Errorf("聚合路由 %s 的部分 %s: %w", cfg.Path, p.Name, err)
+		}
+		part.template = template
+		for _, t := range template {
+			if t.ref >= 0 && !slices.Contains(part.deps, t.ref) {
+				part.deps = append(part.deps, t.ref)
+				part.DependsOn = append(part.DependsOn, cfg.Parts[t.ref].Name)
+			}
+		}
+		c.Parts = append(c.Parts, part)
+	}
+	if name := c.findCycle(); name != "" {
+		return nil, fmt.Errorf("聚合路由 %s 的部分 %s 存在循环依赖", cfg.Path, name)
+	}
+	return c, nil
+}
+
+// parseTemplate 解析路径模板 :name 必须占据完整的路径段 {part.field} 可以出现在任意位置
+func parseTemplate(path string, params []string, parts map[string]int) ([]token, error) {
+	var tokens []token
+	for i, seg := range strings.Split(path, "/") {
+		if i > 0 {
+			tokens = append(tokens, token{text: "/", ref: -1})
+		}
+		if strings.HasPrefix(seg, ":") {
+			name := seg[1:]
+			if !slices.Contains(params, name) {
+				return nil, fmt.Errorf("路径 %q 引用了不存在的参数 %q", path, name)
+			}
+			tokens = append(tokens, token{param: name, ref: -1})
+			continue
+		}
+		for seg != "" {
+			start := strings.IndexByte(seg, '{')
+			if start < 0 {
+				tokens = append(tokens, token{text: seg, ref: -1})
+				break
+			}
+			end := strings.IndexByte(seg[start:], '}')
+			if end < 0 {
+				return nil, fmt.Errorf("路径 %q 的 { 没有闭合", path)
+			}
+			if start > 0 {
+				tokens = append(tokens, token{text: seg[:start], ref: -1})
+			}
+			ref := strings.Split(seg[start+1:start+end], ".")
+			idx, ok := parts[ref[0]]
+			if !ok || len(ref) < 2 {
+				return nil, fmt.Errorf("路径 %q 的引用 %q 必须是 {部分名称.字段}", path, seg[start:start+end+1])
+			}
+			tokens = append(tokens, token{ref: idx, field: ref[1:]})
+			seg = seg[start+end+1:]
+		}
+	}
+	return tokens, nil
+}
+
+// findCycle 返回处于循环依赖中的部分名称 没有循环时返回空字符串
+func (c *Composition) findCycle() string {
+	const (
+		visiting = 1
+		visited  = 2
+	)
+	state := make([]int, len(c.Parts))
+	var visit func(i int) bool
+	visit = func(i int) bool {
+		switch state[i] {
+		case visiting:
+			return true
+		case visited:
+			return false
+		}
+		state[i] = visiting
+		for _, d := range c.Parts[i].deps {
+			if visit(d) {
+				return true
+			}
+		}
+		state[i] = visited
+		return false
+	}
+	for i, p := range c.Parts {
+		if visit(i) {
+			return p.Name
+		}
+	}
+	return ""
+}
@@ -0,0 +1,262 @@
+package compose
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"gateway/tracing"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Response 聚合路由的响应 成功的部分放在 data 中 失败的部分在 data 中为 null 错误放在 errors 中
+type Response struct {
+	Code    int                    `json:"code"`
+	Message string                 `json:"message"`
+	Data    map[string]interface{} `json:"data,omitempty"`
+	Errors  map[string]*PartError  `json:"errors,omitempty"`
+}
+
+// PartError 一个部分的错误 Code 为上游返回的状态码 或网关生成的状态码
+type PartError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// Resolver 根据上游名称返回转发请求的处理器 上游不存在时返回 nil
+type Resolver func(upstream string) http.Handler
+
+// result 一个部分的执行结果
+type result struct {
+	value interface{}
+	err   *PartError
+}
+
+// ServeHTTP 执行所有部分并合并结果 没有依赖关系的部分并行请求
+// 必需的部分失败时以该部分的状态码返回 其余部分失败时仍然返回 200
+func (c *Composition) ServeHTTP(w http.ResponseWriter, r *http.Request, param func(string) string, resolve Resolver) {
+	ctx, cancel := context.WithTimeout(r.Context(), c.Timeout)
+	defer cancel()
+
+	results := make([]result, len(c.Parts))
+	done := make([]chan struct{}, len(c.Parts))
+	for i := range done {
+		done[i] = make(chan struct{})
+	}
+	for i, part := range c.Parts {
+		go func() {
+			defer close(done[i])
+			for _, d := range part.deps {
+				<-done[d]
+				if results[d].err != nil {
+					results[i].err = &PartError{Code: http.StatusFailedDependency, Message: fmt.Sprintf("依赖的 %s 请求失败", c.Parts[d].Name)}
+					return
+				}
+			}
+			results[i] = part.fetch(ctx, r, param, results, resolve)
+		}()
+	}
+	for _, ch := range done {
+		<-ch
+	}
+
+	resp := Response{Code: http.StatusOK, Message: "ok", Data: make(map[string]interface{}, len(c.Parts))}
+	for i, part := range c.Parts {
+		res := results[i]
+		if res.err == nil {
+			resp.Data[part.Name] = res.value
+			continue
+		}
+		resp.Data[part.Name] = nil
+		if resp.Errors == nil {
+			resp.Errors = make(map[string]*PartError)
+		}
+		resp.Errors[part.Name] = res.err
+		if part.Required && resp.Code == http.StatusOK {
+			resp.Code = res.err.Code
+			if resp.Code < http.StatusBadRequest {
+				resp.Code = http.StatusBadGateway
+			}
+			resp.Message = fmt.Sprintf("%s 请求失败: %s", part.Name, res.err.Message)
+		}
+	}
+	if resp.Code != http.StatusOK {
+		resp.Data = nil
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(resp.Code)
+	json.NewEncoder(w).Encode(resp)
+}
+
+// fetch 以 GET 请求上游 沿用客户端的请求头 包括网关设置的用户信息、请求ID和 traceparent
+func (p *Part) fetch(ctx context.Context, r *http.Request, param func(string) string, results []result, resolve Resolver) result {
+	ctx, span := tracing.Tracer().Start(ctx, "compose "+p.Name, trace.WithAttributes(attribute.String("upstream.name", p.Upstream)))
+	defer span.End()
+	fail := func(code int, format string, args ...interface{}) result {
+		err := &PartError{Code: code, Message: fmt.Sprintf(format, args...)}
+		span.SetStatus(codes.Error, err.Message)
+		return result{err: err}
+	}
+
+	path, err := p.buildPath(param, results)
+	if err != nil {
+		return fail(http.StatusBadGateway, "%v", err)
+	}
+	handler := resolve(p.Upstream)
+	if handler == nil {
+		return fail(http.StatusServiceUnavailable, "上游 %s 不可用", p.Upstream)
+	}
+
+	sub := r.Clone(ctx)
+	sub.Method = http.MethodGet
+	sub.URL = &url.URL{Path: path}
+	sub.RequestURI = ""
+	sub.Body = http.NoBody
+	sub.ContentLength = 0
+	sub.Header.Del("Content-Length")
+	sub.Header.Del("Content-Type")
+	span.SetAttributes(attribute.String("url.path", path))
+
+	rec := &recorder{header: make(http.Header), status: http.StatusOK}
+	handler.ServeHTTP(rec, sub)
+	if ctx.Err() == context.DeadlineExceeded {
+		return fail(http.StatusGatewayTimeout, "请求超时")
+	}
+	if rec.status < 200 || rec.status >= 300 {
+		return fail(rec.status, "%s", errorMessage(rec.status, rec.body.Bytes()))
+	}
+
+	dec := json.NewDecoder(&rec.body)
+	dec.UseNumber()
+	var value interface{}
+	if err := dec.Decode(&value); err != nil {
+		return fail(http.StatusBadGateway, "上游返回的不是 JSON")
+	}
+	if p.Field != "" {
+		var ok bool
+		if value, ok = lookup(value, strings.Split(p.Field, ".")); !ok {
+			return fail(http.StatusBadGateway, "上游响应中没有字段 %s", p.Field)
+		}
+	}
+	if len(p.Select) > 0 {
+		value = selectFields(value, p.Select)
+	}
+	return result{value: value}
+}
+
+// buildPath 用路由参数和其他部分的结果填充路径模板
+func (p *Part) buildPath(param func(string) string, results []result) (string, error) {
+	var b strings.Builder
+	for _, t := range p.template {
+		switch {
+		case t.param != "":
+			b.WriteString(url.PathEscape(param(t.param)))
+		case t.ref >= 0:
+			v, ok := lookup(results[t.ref].value, t.field)
+			if !ok {
+				return "", fmt.Errorf("依赖的结果中没有字段 %s", strings.Join(t.field, "."))
+			}
+			s, ok := scalar(v)
+			if !ok {
+				return "", fmt.Errorf("依赖的字段 %s 不是字符串或数字", strings.Join(t.field, "."))
+			}
+			b.WriteString(url.PathEscape(s))
+		default:
+			b.WriteString(t.text)
+		}
+	}
+	return b.String(), nil
+}
+
+// lookup 按字段路径取出 JSON 对象中的值
+func lookup(v interface{}, field []string) (interface{}, bool) {
+	for _, key := range field {
+		obj, ok := v.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		if v, ok = obj[key]; !ok {
+			return nil, false
+		}
+	}
+	return v, true
+}
+
+func scalar(v interface{}) (string, bool) {
+	switch v := v.(type) {
+	case string:
+		return v, v != ""
+	case json.Number:
+		return v.String(), true
+	case bool:
+		return strconv.FormatBool(v), true
+	}
+	return "", false
+}
+
+// selectFields 只保留对象中的指定字段 数组中的每个对象分别处理
+func selectFields(v interface{}, keys []string) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(keys))
+		for _, key := range keys {
+			if fv, ok := v[key]; ok {
+				out[key] = fv
+			}
+		}
+		return out
+	case []interface{}:
+		for i := range v {
+			v[i] = selectFields(v[i], keys)
+		}
+	}
+	return v
+}
+
+// errorMessage 取上游错误响应中的 message、msg 或 error 字段 没有时使用状态码描述
+func errorMessage(status int, body []byte) string {
+	var resp struct {
+		Message string `json:"message"`
+		Msg     string `json:"msg"`
+		Error   string `json:"error"`
+	}
+	if json.Unmarshal(body, &resp) == nil {
+		for _, msg := range []string{resp.Message, resp.Msg, resp.Error} {
+			if msg != "" {
+				return msg
+			}
+		}
+	}
+	return http.StatusText(status)
+}
+
+// recorder 捕获上游响应
+type recorder struct {
+	header http.Header
+	body   bytes.Buffer
+	status int
+}
+
+// Header 实现http.ResponseWriter接口
+func (r *recorder) Header() http.Header {
+	return r.header
+}
+
+// Write 实现http.ResponseWriter接口
+func (r *recorder) Write(b []byte) (int, error) {
+	return r.body.Write(b)
+}
+
+// WriteHeader 实现http.ResponseWriter接口
+func (r *recorder) WriteHeader(status int) {
+	r.status = status
+}
@@ -1,6 +1,9 @@
 package config
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
 	"io/ioutil"
 	"log"
 
@@ -9,39 +12,211 @@ import (
 
 // Config 网关配置结构体
 type Config struct {
+	Name string    `yaml:"Name"`
+	Host string    `yaml:"Host"`
+	Port int       `yaml:"Port"`
+	Jwt  JwtConfig `yaml:"Jwt"`
+	// Login 网关登录 转发到用户服务校验用户名和密码 由网关签发令牌
+	Login LoginConfig `yaml:"Login"`
+	// RateLimitStore 限流状态存储 默认内存
+	RateLimitStore RateLimitStoreConfig `yaml:"RateLimitStore"`
+	// Admin 本地管理接口 Port 为0时不启动
+	Admin AdminConfig `yaml:"Admin"`
+	// Telemetry 链路追踪 修改后需要重启网关
+	Telemetry TelemetryConfig `yaml:"Telemetry"`
+	// Etcd 注册中心 上游配置了 Discovery 时使用
+	Etcd      EtcdConfig `yaml:"Etcd"`
+	Upstreams []Upstream `yaml:"Upstreams"`
+	// Compositions 聚合路由 并行请求多个上游并合并为一个响应 优先级与普通映射相同
+	Compositions []CompositionConfig `yaml:"Compositions"`
+
+	// Checksum 配置文件内容的摘要 用于判断文件是否变化
+	Checksum string `yaml:"-"`
+}
+
+// RateLimitStoreConfig 限流存储配置结构体
+type RateLimitStoreConfig struct {
+	// Type memory（默认 每个网关实例单独计数）或 redis（多个网关实例共享配额）
+	Type      string      `yaml:"Type"`
+	Redis     RedisConfig `yaml:"Redis"`
+	KeyPrefix string      `yaml:"KeyPrefix"` // 默认 gateway:ratelimit:
+}
+
+// RedisConfig Redis配置结构体
+type RedisConfig struct {
+	Addr     string `yaml:"Addr"`
+	Password string `yaml:"Password"`
+	DB       int    `yaml:"DB"`
+}
+
+// RateLimitConfig 限流规则配置结构体
+type RateLimitConfig struct {
+	// Algorithm token_bucket（默认）或 sliding_window
+	Algorithm string `yaml:"Algorithm"`
+	// Key 按什么计数 ip（默认）、user（X-User-ID 未登录时按IP）或 route（所有客户端共享）
+	Key string `yaml:"Key"`
+	// Limit 每个 Window 允许的请求数
+	Limit int `yaml:"Limit"`
+	// Window 单位毫秒 默认 1000
+	Window int `yaml:"Window"`
+	// Burst 令牌桶容量 默认等于 Limit
+	Burst int `yaml:"Burst"`
+}
+
+// TelemetryConfig 链路追踪配置结构体 字段与 go-zero 服务的 Telemetry 保持一致
+type TelemetryConfig struct {
+	// Name 服务名 默认使用网关的 Name
 	Name string `yaml:"Name"`
+	// Endpoint otlphttp 为采集器地址（如 localhost:4318） file 为文件路径（如 /dev/stdout） 为空时不导出
+	Endpoint string `yaml:"Endpoint"`
+	// Sampler 采样率 0 到 1 之间 默认 1
+	Sampler float64 `yaml:"Sampler"`
+	// Batcher otlphttp（默认）或 file
+	Batcher string `yaml:"Batcher"`
+	// OtlpHttpPath otlphttp 的上报路径 默认 /v1/traces
+	OtlpHttpPath string `yaml:"OtlpHttpPath"`
+	// Disabled 关闭后仍然生成并转发请求ID 但不再生成 traceparent
+	Disabled bool `yaml:"Disabled"`
+}
+
+// AdminConfig 管理接口配置结构体
+type AdminConfig struct {
+	// Host 默认只监听本机
 	Host string `yaml:"Host"`
 	Port int    `yaml:"Port"`
-	Jwt  JwtConfig  `yaml:"Jwt"`
-	Upstreams []Upstream `yaml:"Upstreams"`
+}
+
+// EtcdConfig etcd注册中心配置结构体
+type EtcdConfig struct {
+	Hosts []string `yaml:"Hosts"`
 }
 
 // JwtConfig JWT配置结构体
 type JwtConfig struct {
-	Secret       string   `yaml:"Secret"`
+	// Secret 签名密钥 上游服务校验令牌时使用同一个密钥
+	Secret string `yaml:"Secret"`
+	// AccessExpire 令牌有效期 单位秒 默认 86400
+	AccessExpire int64    `yaml:"AccessExpire"`
 	ExcludePaths []string `yaml:"ExcludePaths"`
 }
 
+// LoginConfig 登录配置结构体
+type LoginConfig struct {
+	// Upstream 校验用户名密码的上游 默认 userapi
+	Upstream string `yaml:"Upstream"`
+	// 窗口期内同一用户名、同一IP的最大失败次数 超过后拒绝登录直到窗口结束 默认 5 和 20
+	MaxFailuresPerUser int `yaml:"MaxFailuresPerUser"`
+	MaxFailuresPerIP   int `yaml:"MaxFailuresPerIP"`
+	// FailureWindow 失败计数窗口 单位毫秒 默认 900000
+	FailureWindow int `yaml:"FailureWindow"`
+}
+
 // Upstream 上游服务配置结构体
 type Upstream struct {
-	Name     string       `yaml:"Name"`
-	Http     HttpConfig   `yaml:"Http"`
-	Mappings []MappingRule `yaml:"Mappings"`
+	Name string     `yaml:"Name"`
+	Http HttpConfig `yaml:"Http"`
+	// Discovery 从注册中心发现实例 注册中心中没有实例时使用 Http.Target 和 Http.Targets
+	Discovery DiscoveryConfig `yaml:"Discovery"`
+	// RateLimit 上游所有映射默认的限流规则 映射可以单独覆盖
+	RateLimit *RateLimitConfig `yaml:"RateLimit"`
+	Mappings  []MappingRule    `yaml:"Mappings"`
+}
+
+// DiscoveryConfig 服务发现配置结构体
+type DiscoveryConfig struct {
+	// Key 服务在注册中心中的键 如 post.api
+	Key string `yaml:"Key"`
 }
 
 // HttpConfig HTTP配置结构体
 type HttpConfig struct {
-	Target  string `yaml:"Target"`
-	Timeout int    `yaml:"Timeout"`
+	// Target 单个上游地址
+	Target string `yaml:"Target"`
+	// Targets 多个上游地址 与 Target 合并使用
+	Targets []TargetConfig `yaml:"Targets"`
+	// Timeout 等待上游响应的超时时间 单位毫秒 默认 3000
+	Timeout int `yaml:"Timeout"`
+	// Balancer 负载均衡策略 round_robin（默认）、least_conn、weighted
+	Balancer string `yaml:"Balancer"`
+	// Retries 幂等请求（GET/HEAD/OPTIONS/PUT/DELETE）失败后换实例重试的最大次数
+	Retries        int                  `yaml:"Retries"`
+	HealthCheck    HealthCheckConfig    `yaml:"HealthCheck"`
+	CircuitBreaker CircuitBreakerConfig `yaml:"CircuitBreaker"`
+}
+
+// TargetConfig 上游地址配置结构体
+type TargetConfig struct {
+	Addr   string `yaml:"Addr"`
+	Weight int    `yaml:"Weight"` // weighted 策略使用 默认 1
+}
+
+// HealthCheckConfig 健康检查配置结构体 时间单位均为毫秒
+type HealthCheckConfig struct {
+	// 主动检查 Interval 为0时关闭 Path 为空时只检查TCP连接
+	Path               string `yaml:"Path"`
+	Interval           int    `yaml:"Interval"`
+	Timeout            int    `yaml:"Timeout"`
+	HealthyThreshold   int    `yaml:"HealthyThreshold"`
+	UnhealthyThreshold int    `yaml:"UnhealthyThreshold"`
+	// 被动检查 连续失败 MaxFails 次后摘除 FailTimeout 毫秒 MaxFails 为0时关闭
+	MaxFails    int `yaml:"MaxFails"`
+	FailTimeout int `yaml:"FailTimeout"`
+}
+
+// CircuitBreakerConfig 熔断配置结构体
+type CircuitBreakerConfig struct {
+	// FailureThreshold 连续失败多少次后熔断 为0时关闭
+	FailureThreshold int `yaml:"FailureThreshold"`
+	// OpenTimeout 熔断持续时间 单位毫秒 默认 10000
+	OpenTimeout int `yaml:"OpenTimeout"`
 }
 
 // MappingRule 映射规则结构体
+// Path 支持 :name 路径参数和末尾的 * 通配符 匹配优先级为 精确 > 参数 > 通配符
 type MappingRule struct {
 	Method string `yaml:"Method"`
 	Path   string `yaml:"Path"`
+	// Host 只匹配指定主机 支持 *.example.com
+	Host string `yaml:"Host"`
+	// Headers 请求头必须全部相等
+	Headers map[string]string `yaml:"Headers"`
+	// StripPrefix 转发前去掉的路径前缀
+	StripPrefix string `yaml:"StripPrefix"`
+	// Rewrite 转发路径模板 可引用 Path 中的 :name 和 * 如 /api/posts/:id
+	Rewrite string `yaml:"Rewrite"`
+	// RateLimit 限流规则 为空时使用上游的 RateLimit
+	RateLimit *RateLimitConfig `yaml:"RateLimit"`
+}
+
+// CompositionConfig 聚合路由配置结构体
+type CompositionConfig struct {
+	// Method 默认 GET 各部分总是以 GET 请求上游
+	Method string `yaml:"Method"`
+	// Path 支持 :name 路径参数 与映射规则使用同一张路由表 冲突时网关拒绝启动
+	Path string `yaml:"Path"`
+	// Timeout 所有部分的总超时 单位毫秒 默认 3000
+	Timeout int `yaml:"Timeout"`
+	// RateLimit 限流规则 为空时不限流
+	RateLimit *RateLimitConfig  `yaml:"RateLimit"`
+	Parts     []CompositionPart `yaml:"Parts"`
+}
+
+// CompositionPart 聚合路由的一个部分 结果放在响应 data 中以 Name 命名的字段
+type CompositionPart struct {
+	Name     string `yaml:"Name"`
+	Upstream string `yaml:"Upstream"`
+	// Path 请求上游的路径 :name 引用聚合路由的路径参数
+	// {part.field} 引用其他部分结果中的字段 被引用的部分完成后才发起请求 其余部分并行执行
+	Path string `yaml:"Path"`
+	// Field 只取上游响应中的某个字段 支持 a.b 形式
+	Field string `yaml:"Field"`
+	// Select 只保留结果中的这些字段 为空时保留全部
+	Select []string `yaml:"Select"`
+	// Required 失败时整个请求失败 否则该字段为 null 错误放在响应的 errors 中
+	Required bool `yaml:"Required"`
 }
 
-// LoadConfig 加载配置文件
+// LoadConfig 加载并校验配置文件
 func LoadConfig(filePath string) (*Config, error) {
 	// 读取文件内容
 	data, err := ioutil.ReadFile(filePath)
@@ -50,13 +225,85 @@ func LoadConfig(filePath string) (*Config, error) {
 		return nil, err
 	}
 
-	// 解析YAML配置
+	// 解析YAML配置 未知字段视为错误 避免拼写错误的配置被静默忽略
 	var config Config
-	err = yaml.Unmarshal(data, &config)
+	err = yaml.UnmarshalStrict(data, &config)
 	if err != nil {
 		log.Printf("解析配置文件失败: %v", err)
-		return nil, err
+		return nil, fmt.Errorf("解析配置文件 %s 失败: %w", filePath, err)
 	}
 
+	config.setDefaults()
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+	sum := sha256.Sum256(data)
+	config.Checksum = hex.EncodeToString(sum[:])[:12]
 	return &config, nil
 }
+
+// setDefaults 填充默认值
+func (c *Config) setDefaults() {
+	if c.RateLimitStore.Type == "" {
+		c.RateLimitStore.Type = "memory"
+	}
+	if c.RateLimitStore.KeyPrefix == "" {
+		c.RateLimitStore.KeyPrefix = "gateway:ratelimit:"
+	}
+	for i := range c.Upstreams {
+		u := &c.Upstreams[i]
+		u.RateLimit.setDefaults()
+		for j := range u.Mappings {
+			u.Mappings[j].RateLimit.setDefaults()
+		}
+	}
+	if c.Telemetry.Name == "" {
+		c.Telemetry.Name = c.Name
+	}
+	if c.Telemetry.Sampler == 0 {
+		c.Telemetry.Sampler = 1
+	}
+	if c.Telemetry.Batcher == "" {
+		c.Telemetry.Batcher = "otlphttp"
+	}
+	for i := range c.Compositions {
+		comp := &c.Compositions[i]
+		if comp.Method == "" {
+			comp.Method = "GET"
+		}
+		if comp.Timeout == 0 {
+			comp.Timeout = 3000
+		}
+		comp.RateLimit.setDefaults()
+	}
+	if c.Admin.Host == "" {
+		c.Admin.Host = "127.0.0.1"
+	}
+	if c.Login.Upstream == "" {
+		c.Login.Upstream = "userapi"
+	}
+	if c.Login.MaxFailuresPerUser == 0 {
+		c.Login.MaxFailuresPerUser = 5
+	}
+	if c.Login.MaxFailuresPerIP == 0 {
+		c.Login.MaxFailuresPerIP = 20
+	}
+	if c.Login.FailureWindow == 0 {
+		c.Login.FailureWindow = 900000
+	}
+}
+
+func (r *RateLimitConfig) setDefaults() {
+	if r == nil {
+		return
+	}
+	if r.Algorithm == "" {
+		r.Algorithm = "token_bucket"
+	}
+	if r.Key == "" {
+		r.Key = "ip"
+	}
+	if r.Window == 0 {
+		r.Window = 1000
+	}
+}
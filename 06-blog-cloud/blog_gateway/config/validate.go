@@ -0,0 +1,193 @@
+package config
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// ValidationError 配置校验错误 包含所有不合法的字段
+type ValidationError struct {
+	Errors []string
+}
+
+func (e *ValidationError) Error() string {
+	return "配置校验失败:\n  - " + strings.Join(e.Errors, "\n  - ")
+}
+
+// 支持的负载均衡策略 与 upstream 包保持一致
+var validBalancers = map[string]bool{"": true, "round_robin": true, "least_conn": true, "weighted": true}
+
+var validMethods = map[string]bool{
+	http.MethodGet: true, http.MethodHead: true, http.MethodPost: true, http.MethodPut: true,
+	http.MethodPatch: true, http.MethodDelete: true, http.MethodOptions: true,
+}
+
+// Validate 校验配置 一次返回所有错误
+// 路由冲突在编译路由表时检查
+func (c *Config) Validate() error {
+	var errs []string
+	add := func(format string, args ...interface{}) {
+		errs = append(errs, fmt.Sprintf(format, args...))
+	}
+
+	if c.Port <= 0 || c.Port > 65535 {
+		add("Port 必须在 1-65535 之间，当前为 %d", c.Port)
+	}
+	if c.Admin.Port < 0 || c.Admin.Port > 65535 {
+		add("Admin.Port 必须在 0-65535 之间，当前为 %d", c.Admin.Port)
+	} else if c.Admin.Port != 0 && c.Admin.Port == c.Port {
+		add("Admin.Port 不能与 Port 相同")
+	}
+	if c.Jwt.Secret == "" {
+		add("Jwt.Secret 不能为空")
+	}
+	if c.Jwt.AccessExpire < 0 {
+		add("Jwt.AccessExpire 不能为负数")
+	}
+	if c.Login.MaxFailuresPerUser < 0 || c.Login.MaxFailuresPerIP < 0 || c.Login.FailureWindow < 0 {
+		add("Login 的失败次数和窗口时间不能为负数")
+	}
+
+	switch c.RateLimitStore.Type {
+	case "memory":
+	case "redis":
+		if c.RateLimitStore.Redis.Addr == "" {
+			add("RateLimitStore.Type 为 redis 时 RateLimitStore.Redis.Addr 不能为空")
+		}
+	default:
+		add("RateLimitStore.Type %q 不支持，可选 memory、redis", c.RateLimitStore.Type)
+	}
+
+	if c.Telemetry.Sampler < 0 || c.Telemetry.Sampler > 1 {
+		add("Telemetry.Sampler 必须在 0-1 之间，当前为 %g", c.Telemetry.Sampler)
+	}
+	if c.Telemetry.Batcher != "otlphttp" && c.Telemetry.Batcher != "file" {
+		add("Telemetry.Batcher %q 不支持，可选 otlphttp、file", c.Telemetry.Batcher)
+	}
+
+	names := make(map[string]bool, len(c.Upstreams))
+	for i, u := range c.Upstreams {
+		prefix := fmt.Sprintf("Upstreams[%d]", i)
+		if u.Name == "" {
+			add("%s.Name 不能为空", prefix)
+		} else {
+			prefix = fmt.Sprintf("Upstreams[%d](%s)", i, u.Name)
+			if names[u.Name] {
+				add("%s.Name 重复", prefix)
+			}
+			names[u.Name] = true
+		}
+		c.validateUpstream(prefix, u, add)
+	}
+	if !names[c.Login.Upstream] {
+		add("Login.Upstream %q 不存在", c.Login.Upstream)
+	}
+	for i, comp := range c.Compositions {
+		validateComposition(fmt.Sprintf("Compositions[%d](%s)", i, comp.Path), comp, names, add)
+	}
+
+	if len(errs) > 0 {
+		return &ValidationError{Errors: errs}
+	}
+	return nil
+}
+
+func (c *Config) validateUpstream(prefix string, u Upstream, add func(string, ...interface{})) {
+	h := u.Http
+	if h.Target == "" && len(h.Targets) == 0 && u.Discovery.Key == "" {
+		add("%s 至少需要配置 Http.Target、Http.Targets 或 Discovery.Key 之一", prefix)
+	}
+	if u.Discovery.Key != "" && len(c.Etcd.Hosts) == 0 {
+		add("%s 配置了 Discovery.Key 但没有配置 Etcd.Hosts", prefix)
+	}
+	for j, t := range h.Targets {
+		if t.Addr == "" {
+			add("%s.Http.Targets[%d].Addr 不能为空", prefix, j)
+		}
+		if t.Weight < 0 {
+			add("%s.Http.Targets[%d].Weight 不能为负数", prefix, j)
+		}
+	}
+	if !validBalancers[h.Balancer] {
+		add("%s.Http.Balancer %q 不支持，可选 round_robin、least_conn、weighted", prefix, h.Balancer)
+	}
+	if h.Timeout < 0 || h.Retries < 0 {
+		add("%s.Http.Timeout 和 Http.Retries 不能为负数", prefix)
+	}
+	hc := h.HealthCheck
+	if hc.Interval < 0 || hc.Timeout < 0 || hc.HealthyThreshold < 0 || hc.UnhealthyThreshold < 0 || hc.MaxFails < 0 || hc.FailTimeout < 0 {
+		add("%s.Http.HealthCheck 的配置不能为负数", prefix)
+	}
+	if hc.Path != "" && !strings.HasPrefix(hc.Path, "/") {
+		add("%s.Http.HealthCheck.Path 必须以 / 开头", prefix)
+	}
+	if h.CircuitBreaker.FailureThreshold < 0 || h.CircuitBreaker.OpenTimeout < 0 {
+		add("%s.Http.CircuitBreaker 的配置不能为负数", prefix)
+	}
+
+	validateRateLimit(prefix+".RateLimit", u.RateLimit, add)
+	if len(u.Mappings) == 0 {
+		add("%s.Mappings 不能为空", prefix)
+	}
+	for j, m := range u.Mappings {
+		if !validMethods[strings.ToUpper(m.Method)] {
+			add("%s.Mappings[%d].Method %q 不是合法的HTTP方法", prefix, j, m.Method)
+		}
+		if !strings.HasPrefix(m.Path, "/") {
+			add("%s.Mappings[%d].Path %q 必须以 / 开头", prefix, j, m.Path)
+		}
+		validateRateLimit(fmt.Sprintf("%s.Mappings[%d].RateLimit", prefix, j), m.RateLimit, add)
+	}
+}
+
+// validateComposition 检查字段和上游 路径模板中的引用由路由在编译时检查
+func validateComposition(prefix string, comp CompositionConfig, upstreams map[string]bool, add func(string, ...interface{})) {
+	if !validMethods[strings.ToUpper(comp.Method)] {
+		add("%s.Method %q 不是合法的HTTP方法", prefix, comp.Method)
+	}
+	if !strings.HasPrefix(comp.Path, "/") {
+		add("%s.Path 必须以 / 开头", prefix)
+	}
+	if comp.Timeout < 0 {
+		add("%s.Timeout 不能为负数", prefix)
+	}
+	validateRateLimit(prefix+".RateLimit", comp.RateLimit, add)
+	if len(comp.Parts) == 0 {
+		add("%s.Parts 不能为空", prefix)
+	}
+	parts := make(map[string]bool, len(comp.Parts))
+	for j, part := range comp.Parts {
+		switch {
+		case part.Name == "":
+			add("%s.Parts[%d].Name 不能为空", prefix, j)
+		case parts[part.Name]:
+			add("%s.Parts[%d].Name %q 重复", prefix, j, part.Name)
+		}
+		parts[part.Name] = true
+		if !upstreams[part.Upstream] {
+			add("%s.Parts[%d].Upstream %q 不存在", prefix, j, part.Upstream)
+		}
+		if !strings.HasPrefix(part.Path, "/") {
+			add("%s.Parts[%d].Path 必须以 / 开头", prefix, j)
+		}
+	}
+}
+
+func validateRateLimit(prefix string, r *RateLimitConfig, add func(string, ...interface{})) {
+	if r == nil {
+		return
+	}
+	if r.Algorithm != "token_bucket" && r.Algorithm != "sliding_window" {
+		add("%s.Algorithm %q 不支持，可选 token_bucket、sliding_window", prefix, r.Algorithm)
+	}
+	if r.Key != "ip" && r.Key != "user" && r.Key != "route" {
+		add("%s.Key %q 不支持，可选 ip、user、route", prefix, r.Key)
+	}
+	if r.Limit <= 0 {
+		add("%s.Limit 必须大于0", prefix)
+	}
+	if r.Window < 0 || r.Burst < 0 {
+		add("%s.Window 和 Burst 不能为负数", prefix)
+	}
+}
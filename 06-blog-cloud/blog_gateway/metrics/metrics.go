@@ -0,0 +1,101 @@
+package metrics
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// namespace 网关指标前缀
+const namespace = "gateway"
+
+// 没有匹配到路由的请求使用的标签值 避免把原始路径作为标签
+const (
+	RouteUnmatched = "unmatched"
+	NoUpstream     = "none"
+)
+
+// latencyBuckets 请求耗时分桶 单位秒
+var latencyBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+var (
+	// RequestsTotal 网关处理的请求数 按路由规则、上游、方法和状态码统计
+	RequestsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "requests_total",
+		Help:      "Total number of requests handled by the gateway.",
+	}, []string{"route", "upstream", "method", "code"})
+
+	// RequestDuration 网关处理请求的耗时 包括中间件和转发
+	RequestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "request_duration_seconds",
+		Help:      "Latency of requests handled by the gateway.",
+		Buckets:   latencyBuckets,
+	}, []string{"route", "upstream", "method"})
+
+	// RequestsInFlight 正在转发的请求数
+	RequestsInFlight = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "requests_in_flight",
+		Help:      "Number of requests currently being proxied.",
+	}, []string{"route", "upstream"})
+
+	// UpstreamRequestsTotal 每次转发尝试的结果 result 为 success 或 failure 重试会计入多次
+	UpstreamRequestsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "upstream_requests_total",
+		Help:      "Total number of proxy attempts to upstream backends.",
+	}, []string{"upstream", "result"})
+
+	// UpstreamRequestDuration 每次转发尝试的耗时
+	UpstreamRequestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "upstream_request_duration_seconds",
+		Help:      "Latency of proxy attempts to upstream backends.",
+		Buckets:   latencyBuckets,
+	}, []string{"upstream"})
+
+	// UpstreamRejectedTotal 没有转发就直接拒绝的请求 reason 为 circuit_open 或 no_available_backend
+	UpstreamRejectedTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "upstream_rejected_total",
+		Help:      "Total number of requests rejected without reaching an upstream backend.",
+	}, []string{"upstream", "reason"})
+
+	// RateLimitedTotal 被限流拒绝的请求数
+	RateLimitedTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "ratelimit_rejected_total",
+		Help:      "Total number of requests rejected by rate limiting.",
+	}, []string{"route", "upstream"})
+)
+
+// Handler 返回 Prometheus 抓取接口
+func Handler() http.Handler {
+	return promhttp.Handler()
+}
+
+// RouteLabels 请求匹配到的路由 由处理器在匹配后填写 中间件在请求结束时读取
+type RouteLabels struct {
+	Route    string
+	Upstream string
+}
+
+type routeLabelsKey struct{}
+
+// WithRouteLabels 在 ctx 中放入待填写的路由标签
+func WithRouteLabels(ctx context.Context) (context.Context, *RouteLabels) {
+	labels := &RouteLabels{Route: RouteUnmatched, Upstream: NoUpstream}
+	return context.WithValue(ctx, routeLabelsKey{}, labels), labels
+}
+
+// SetRoute 记录请求匹配到的路由规则和上游
+func SetRoute(ctx context.Context, route, upstream string) {
+	if labels, ok := ctx.Value(routeLabelsKey{}).(*RouteLabels); ok {
+		labels.Route, labels.Upstream = route, upstream
+	}
+}
@@ -26,6 +26,10 @@ func NewJwtMiddleware(excludePaths []string) *JwtMiddleware {
 // Handle 实现中间件处理函数
 func (m *JwtMiddleware) Handle(next http.HandlerFunc) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
+		// 用户身份请求头只能由网关设置 丢弃客户端伪造的值
+		r.Header.Del("X-User-ID")
+		r.Header.Del("X-Username")
+
 		// 检查当前路径是否需要排除JWT验证
 		path := r.URL.Path
 		if m.shouldExclude(path) {
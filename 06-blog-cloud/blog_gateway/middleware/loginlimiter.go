@@ -0,0 +1,114 @@
+package middleware
+
+import (
+	"strings"
+	"sync"
+	"time"
+)
+
+// LoginLimiter 登录失败限流器
+// 同一用户名或同一IP在窗口期内失败次数达到上限后 拒绝登录直到窗口结束
+type LoginLimiter struct {
+	mu         sync.Mutex
+	maxPerUser int
+	maxPerIP   int
+	window     time.Duration
+	failures   map[string]*loginFailure
+}
+
+// loginFailure 窗口期内的失败次数 窗口从第一次失败开始计算
+type loginFailure struct {
+	count   int
+	resetAt time.Time
+}
+
+// maxTrackedFailures 记录数超过该值时清理已过期的记录
+const maxTrackedFailures = 10000
+
+// NewLoginLimiter 创建登录失败限流器 上限为0时不限制对应维度
+func NewLoginLimiter(maxPerUser, maxPerIP int, window time.Duration) *LoginLimiter {
+	return &LoginLimiter{
+		maxPerUser: maxPerUser,
+		maxPerIP:   maxPerIP,
+		window:     window,
+		failures:   make(map[string]*loginFailure),
+	}
+}
+
+// SetLimits 修改失败次数上限和窗口 已有的失败记录保留 配置重新加载时使用
+func (l *LoginLimiter) SetLimits(maxPerUser, maxPerIP int, window time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.maxPerUser, l.maxPerIP, l.window = maxPerUser, maxPerIP, window
+}
+
+// Allow 是否允许本次登录尝试 不允许时返回需要等待的时间
+func (l *LoginLimiter) Allow(username, ip string) (bool, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := time.Now()
+	var wait time.Duration
+	if d := l.blocked(userKey(username), l.maxPerUser, now); d > wait {
+		wait = d
+	}
+	if d := l.blocked(ipKey(ip), l.maxPerIP, now); d > wait {
+		wait = d
+	}
+	return wait == 0, wait
+}
+
+// Fail 记录一次登录失败
+func (l *LoginLimiter) Fail(username, ip string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := time.Now()
+	if len(l.failures) >= maxTrackedFailures {
+		for key, f := range l.failures {
+			if !now.Before(f.resetAt) {
+				delete(l.failures, key)
+			}
+		}
+	}
+	if l.maxPerUser > 0 {
+		l.record(userKey(username), now)
+	}
+	if l.maxPerIP > 0 {
+		l.record(ipKey(ip), now)
+	}
+}
+
+// Succeed 登录成功后清除该用户名的失败记录 IP记录保留 防止用一个账号掩护撞库
+func (l *LoginLimiter) Succeed(username string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	delete(l.failures, userKey(username))
+}
+
+func (l *LoginLimiter) blocked(key string, max int, now time.Time) time.Duration {
+	if max <= 0 {
+		return 0
+	}
+	f, ok := l.failures[key]
+	if !ok || !now.Before(f.resetAt) || f.count < max {
+		return 0
+	}
+	return f.resetAt.Sub(now)
+}
+
+func (l *LoginLimiter) record(key string, now time.Time) {
+	f, ok := l.failures[key]
+	if !ok || !now.Before(f.resetAt) {
+		l.failures[key] = &loginFailure{count: 1, resetAt: now.Add(l.window)}
+		return
+	}
+	f.count++
+}
+
+// 用户名不区分大小写 避免通过变换大小写绕过限制
+func userKey(username string) string {
+	return "user:" + strings.ToLower(username)
+}
+
+func ipKey(ip string) string {
+	return "ip:" + ip
+}
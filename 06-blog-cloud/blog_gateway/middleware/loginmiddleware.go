@@ -2,78 +2,115 @@ package middleware
 
 import (
 	"bytes"
-	"context"
 	"encoding/json"
+	"fmt"
 	"io"
+	"log"
+	"math"
+	"net"
 	"net/http"
-	"strconv"
+	"time"
 
 	"gateway/utils"
 )
 
-// GenerateTokenForLogin 生成登录用的JWT令牌
-func GenerateTokenForLogin(userID int64, username string) (string, error) {
-	return utils.GenerateToken(userID, username)
+// LoginRequest 用户登录请求结构
+type LoginRequest struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
 }
 
-// LoginResponse 用户登录响应结构
+// LoginResponse 用户服务校验通过后返回的用户信息
 type LoginResponse struct {
 	ID    int64  `json:"id"`
 	Name  string `json:"name"`
 	Email string `json:"email"`
 }
 
-// EnhancedLoginResponse 增强的登录响应结构
-type EnhancedLoginResponse struct {
-	ID    int64  `json:"id"`
-	Name  string `json:"name"`
-	Email string `json:"email"`
-	Token string `json:"token"`
+// LoginResult 网关登录响应结构 成功和失败使用相同的 code/message 结构
+type LoginResult struct {
+	Code    int        `json:"code"`
+	Message string     `json:"message"`
+	Data    *LoginData `json:"data,omitempty"`
 }
 
+// LoginData 登录成功返回的令牌和用户信息
+type LoginData struct {
+	Token     string        `json:"token"`
+	ExpiresIn int64         `json:"expires_in"` // 令牌有效秒数
+	User      LoginResponse `json:"user"`
+}
+
+// maxLoginBodyBytes 登录请求体上限
+const maxLoginBodyBytes = 1 << 20
+
 // LoginMiddleware 登录处理器中间件
+// next 把登录请求转发给用户服务校验用户名和密码 校验通过后由网关签发令牌
 type LoginMiddleware struct {
-	// 代理请求到的目标URL
-	TargetURL string
+	// 登录失败限流
+	Limiter *LoginLimiter
 }
 
 // NewLoginMiddleware 创建登录处理器中间件实例
-func NewLoginMiddleware(targetURL string) *LoginMiddleware {
+func NewLoginMiddleware(limiter *LoginLimiter) *LoginMiddleware {
 	return &LoginMiddleware{
-		TargetURL: targetURL,
+		Limiter: limiter,
 	}
 }
 
 // Handle 实现中间件处理函数
 func (m *LoginMiddleware) Handle(next http.HandlerFunc) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		// 只处理登录请求
-		if r.URL.Path != "/v1/user/login" || r.Method != http.MethodPost {
-			next(w, r)
+		// 只处理POST请求
+		if r.Method != http.MethodPost {
+			writeLoginResult(w, http.StatusMethodNotAllowed, LoginResult{Code: http.StatusMethodNotAllowed, Message: "Method not allowed"})
 			return
 		}
 
 		// 读取原始请求体
-		bodyBytes, err := io.ReadAll(r.Body)
+		bodyBytes, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxLoginBodyBytes))
 		if err != nil {
-			w.WriteHeader(http.StatusInternalServerError)
-			w.Write([]byte(`{"code": 500, "message": "读取请求体失败"}`))
+			writeLoginResult(w, http.StatusBadRequest, LoginResult{Code: http.StatusBadRequest, Message: "读取请求体失败"})
+			return
+		}
+		var req LoginRequest
+		if err := json.Unmarshal(bodyBytes, &req); err != nil || req.Username == "" || req.Password == "" {
+			writeLoginResult(w, http.StatusBadRequest, LoginResult{Code: http.StatusBadRequest, Message: "用户名和密码不能为空"})
+			return
+		}
+
+		// 失败次数过多时直接拒绝 不再请求用户服务
+		ip := ClientIP(r)
+		if ok, wait := m.Limiter.Allow(req.Username, ip); !ok {
+			w.Header().Set("Retry-After", fmt.Sprint(int(math.Ceil(wait.Seconds()))))
+			writeLoginResult(w, http.StatusTooManyRequests, LoginResult{Code: http.StatusTooManyRequests, Message: "登录失败次数过多，请稍后重试"})
 			return
 		}
 
 		// 恢复请求体，以便后续使用
-		r.Body = io.NopCloser(bytes.NewBuffer(bodyBytes))
+		r.Body = io.NopCloser(bytes.NewReader(bodyBytes))
+		r.ContentLength = int64(len(bodyBytes))
 
 		// 创建一个自定义的ResponseWriter来捕获上游服务的响应
 		recorder := &responseRecorder{
-			header: make(http.Header),
+			header:     make(http.Header),
+			statusCode: http.StatusOK,
 		}
 
-		// 调用下一个处理器（通常是代理到用户API服务）
+		// 调用下一个处理器（代理到用户API服务）
 		next(recorder, r)
 
-		// 如果上游服务返回的不是200 OK，直接返回原始响应
-		if recorder.statusCode != http.StatusOK {
+		switch {
+		case recorder.statusCode == http.StatusUnauthorized:
+			m.Limiter.Fail(req.Username, ip)
+			log.Printf("[LOGIN] 用户 %s 登录失败 IP: %s", req.Username, ip)
+			writeLoginResult(w, http.StatusUnauthorized, LoginResult{Code: http.StatusUnauthorized, Message: "用户名或密码错误"})
+			return
+		case recorder.statusCode != http.StatusOK:
+			// 其他错误原样返回 不计入登录失败
+			for k, v := range recorder.header {
+				w.Header()[k] = v
+			}
 			w.WriteHeader(recorder.statusCode)
 			w.Write(recorder.body)
 			return
@@ -81,40 +118,30 @@ func (m *LoginMiddleware) Handle(next http.HandlerFunc) http.HandlerFunc {
 
 		// 解析上游服务返回的用户信息
 		var loginResp LoginResponse
-		if err := json.Unmarshal(recorder.body, &loginResp); err != nil {
-			w.WriteHeader(http.StatusInternalServerError)
-			w.Write([]byte(`{"code": 500, "message": "解析用户信息失败"}`))
+		if err := json.Unmarshal(recorder.body, &loginResp); err != nil || loginResp.ID == 0 {
+			log.Printf("[LOGIN] 解析用户信息失败: %v，响应: %s", err, recorder.body)
+			writeLoginResult(w, http.StatusBadGateway, LoginResult{Code: http.StatusBadGateway, Message: "解析用户信息失败"})
 			return
 		}
+		m.Limiter.Succeed(req.Username)
 
 		// 生成JWT Token
 		token, err := utils.GenerateToken(loginResp.ID, loginResp.Name)
 		if err != nil {
-			w.WriteHeader(http.StatusInternalServerError)
-			w.Write([]byte(`{"code": 500, "message": "生成Token失败"}`))
+			log.Printf("[LOGIN] 生成Token失败: %v", err)
+			writeLoginResult(w, http.StatusInternalServerError, LoginResult{Code: http.StatusInternalServerError, Message: "生成Token失败"})
 			return
 		}
 
-		// 创建增强的响应，包含Token
-		enhancedResp := EnhancedLoginResponse{
-			ID:    loginResp.ID,
-			Name:  loginResp.Name,
-			Email: loginResp.Email,
-			Token: token,
-		}
-
-		// 将用户ID添加到上下文中（如果后续中间件需要使用）
-		ctx := r.Context()
-		ctx = context.WithValue(ctx, "user_id", loginResp.ID)
-		r = r.WithContext(ctx)
-
-		// 将用户ID添加到请求头中
-		r.Header.Set("X-User-ID", strconv.FormatInt(loginResp.ID, 10))
-
-		// 返回增强的响应
-		w.Header().Set("Content-Type", "application/json")
-		respData, _ := json.Marshal(enhancedResp)
-		w.Write(respData)
+		writeLoginResult(w, http.StatusOK, LoginResult{
+			Code:    http.StatusOK,
+			Message: "登录成功",
+			Data: &LoginData{
+				Token:     token,
+				ExpiresIn: int64(utils.TokenExpire() / time.Second),
+				User:      loginResp,
+			},
+		})
 	}
 }
 
@@ -127,6 +154,20 @@ func (m *LoginMiddleware) MiddlewareFunc() func(http.Handler) http.Handler {
 	}
 }
 
+func writeLoginResult(w http.ResponseWriter, status int, result LoginResult) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(result)
+}
+
+// ClientIP 客户端IP 网关直接面向客户端 不信任 X-Forwarded-For
+func ClientIP(r *http.Request) string {
+	if host, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+		return host
+	}
+	return r.RemoteAddr
+}
+
 // responseRecorder 用于捕获HTTP响应的自定义ResponseWriter
 type responseRecorder struct {
 	header     http.Header
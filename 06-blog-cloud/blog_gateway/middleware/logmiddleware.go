@@ -4,6 +4,8 @@ import (
 	"log"
 	"net/http"
 	"time"
+
+	"gateway/tracing"
 )
 
 // LogMiddleware 日志中间件
@@ -36,13 +38,15 @@ func (m *LogMiddleware) MiddlewareFunc() func(http.Handler) http.Handler {
 			// 计算响应时间
 			duration := time.Since(startTime)
 
-			// 记录日志
-			log.Printf("[GATEWAY] %s %s %d %s %s",
+			// 记录日志 带上请求ID和 trace ID 便于与上游服务的日志关联
+			log.Printf("[GATEWAY] %s %s %d %s %s request_id=%s trace_id=%s",
 				r.Method,
 				r.URL.Path,
 				wrapper.statusCode,
 				duration,
 				clientIP,
+				tracing.RequestID(r.Context()),
+				tracing.TraceID(r.Context()),
 			)
 		})
 	}
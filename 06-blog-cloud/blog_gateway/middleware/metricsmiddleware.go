@@ -0,0 +1,37 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"gateway/metrics"
+)
+
+// MetricsMiddleware 请求指标中间件 统计所有请求 包括被鉴权和限流拒绝的请求
+// 路由和上游标签由处理器匹配路由后通过 metrics.SetRoute 填写
+type MetricsMiddleware struct{}
+
+// NewMetricsMiddleware 创建请求指标中间件实例
+func NewMetricsMiddleware() *MetricsMiddleware {
+	return &MetricsMiddleware{}
+}
+
+// MiddlewareFunc 返回中间件函数
+func (m *MetricsMiddleware) MiddlewareFunc() func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			startTime := time.Now()
+			ctx, labels := metrics.WithRouteLabels(r.Context())
+
+			wrapper := &responseWrapper{
+				ResponseWriter: w,
+				statusCode:     http.StatusOK,
+			}
+			next.ServeHTTP(wrapper, r.WithContext(ctx))
+
+			metrics.RequestsTotal.WithLabelValues(labels.Route, labels.Upstream, r.Method, strconv.Itoa(wrapper.statusCode)).Inc()
+			metrics.RequestDuration.WithLabelValues(labels.Route, labels.Upstream, r.Method).Observe(time.Since(startTime).Seconds())
+		})
+	}
+}
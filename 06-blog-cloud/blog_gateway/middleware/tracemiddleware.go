@@ -0,0 +1,64 @@
+package middleware
+
+import (
+	"net/http"
+
+	"gateway/tracing"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// TraceMiddleware 链路追踪中间件 放在处理链最外层
+// 为每个请求生成请求ID和网关 span 并写入请求头 由代理转发给上游服务
+type TraceMiddleware struct{}
+
+// NewTraceMiddleware 创建链路追踪中间件实例
+func NewTraceMiddleware() *TraceMiddleware {
+	return &TraceMiddleware{}
+}
+
+// MiddlewareFunc 返回中间件函数
+func (m *TraceMiddleware) MiddlewareFunc() func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			// 沿用客户端传入的合法请求ID 否则重新生成
+			requestID := r.Header.Get(tracing.HeaderRequestID)
+			if !tracing.ValidRequestID(requestID) {
+				requestID = tracing.NewRequestID()
+			}
+
+			// 客户端带了 traceparent 时作为父 span 匹配到路由后再把 span 名改为路由规则
+			ctx := tracing.Extract(r.Context(), r.Header)
+			ctx, span := tracing.Tracer().Start(ctx, r.Method,
+				trace.WithSpanKind(trace.SpanKindServer),
+				trace.WithAttributes(
+					attribute.String("http.request.method", r.Method),
+					attribute.String("url.path", r.URL.Path),
+					attribute.String("client.address", ClientIP(r)),
+					attribute.String("request.id", requestID),
+				),
+			)
+			defer span.End()
+			ctx = tracing.WithRequestID(ctx, requestID)
+
+			// 覆盖客户端的值 上游看到的是网关 span
+			r = r.WithContext(ctx)
+			r.Header.Set(tracing.HeaderRequestID, requestID)
+			tracing.Inject(ctx, r.Header)
+			w.Header().Set(tracing.HeaderRequestID, requestID)
+
+			wrapper := &responseWrapper{
+				ResponseWriter: w,
+				statusCode:     http.StatusOK,
+			}
+			next.ServeHTTP(wrapper, r)
+
+			span.SetAttributes(attribute.Int("http.response.status_code", wrapper.statusCode))
+			if wrapper.statusCode >= http.StatusInternalServerError {
+				span.SetStatus(codes.Error, http.StatusText(wrapper.statusCode))
+			}
+		})
+	}
+}
@@ -0,0 +1,94 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// maxMemoryKeys 超过该数量时清理长时间未访问的 key
+const maxMemoryKeys = 100000
+
+// MemoryStore 进程内存储 多个网关实例之间不共享配额
+type MemoryStore struct {
+	mu      sync.Mutex
+	buckets map[string]*bucketState
+	windows map[string]*windowState
+}
+
+type bucketState struct {
+	tokens float64
+	last   time.Time
+	refill time.Duration //从空桶补满需要的时间
+}
+
+type windowState struct {
+	start time.Time //当前窗口开始时间
+	prev  int64
+	cur   int64
+	rule  time.Duration
+}
+
+// NewMemoryStore 创建内存存储
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		buckets: make(map[string]*bucketState),
+		windows: make(map[string]*windowState),
+	}
+}
+
+// Allow 实现 Store 接口
+func (s *MemoryStore) Allow(_ context.Context, key string, rule Rule, now time.Time) (Result, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.buckets)+len(s.windows) > maxMemoryKeys {
+		s.cleanup(now)
+	}
+
+	if rule.Algorithm == AlgorithmTokenBucket {
+		b, ok := s.buckets[key]
+		if !ok {
+			b = &bucketState{tokens: float64(rule.capacity()), last: now}
+			s.buckets[key] = b
+		}
+		b.refill = rule.refillTime()
+		res, tokens := tokenBucket(rule, b.tokens, now.Sub(b.last))
+		b.tokens, b.last = tokens, now
+		return res, nil
+	}
+
+	w, ok := s.windows[key]
+	if !ok {
+		w = &windowState{start: now.Truncate(rule.Window)}
+		s.windows[key] = w
+	}
+	w.rule = rule.Window
+	// 滚动窗口 跳过多个窗口时上一窗口计数为0
+	if start := now.Truncate(rule.Window); !start.Equal(w.start) {
+		if start.Sub(w.start) == rule.Window {
+			w.prev = w.cur
+		} else {
+			w.prev = 0
+		}
+		w.start, w.cur = start, 0
+	}
+	res := slidingWindow(rule, w.prev, w.cur, now.Sub(w.start))
+	if res.Allowed {
+		w.cur++
+	}
+	return res, nil
+}
+
+// cleanup 删除已经完全恢复的状态
+func (s *MemoryStore) cleanup(now time.Time) {
+	for key, b := range s.buckets {
+		if now.Sub(b.last) > b.refill {
+			delete(s.buckets, key)
+		}
+	}
+	for key, w := range s.windows {
+		if now.Sub(w.start) > 2*w.rule {
+			delete(s.windows, key)
+		}
+	}
+}
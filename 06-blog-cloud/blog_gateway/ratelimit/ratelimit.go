@@ -0,0 +1,110 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+)
+
+// 限流算法
+const (
+	// AlgorithmTokenBucket 令牌桶 每 Window 补充 Limit 个令牌 最多累积 Burst 个 允许短时突发
+	AlgorithmTokenBucket = "token_bucket"
+	// AlgorithmSlidingWindow 滑动窗口 任意 Window 时长内最多 Limit 个请求
+	// 按当前窗口计数加上一窗口按剩余比例折算的计数近似 不需要保存每个请求的时间
+	AlgorithmSlidingWindow = "sliding_window"
+)
+
+// Rule 限流规则
+type Rule struct {
+	Algorithm string        `json:"algorithm"`
+	Limit     int           `json:"limit"`
+	Window    time.Duration `json:"window"`
+	Burst     int           `json:"burst,omitempty"` //令牌桶容量 为0时等于 Limit
+}
+
+// Validate 校验规则
+func (r Rule) Validate() error {
+	switch r.Algorithm {
+	case AlgorithmTokenBucket, AlgorithmSlidingWindow:
+	default:
+		return fmt.Errorf("不支持的限流算法 %q，可选 %s、%s", r.Algorithm, AlgorithmTokenBucket, AlgorithmSlidingWindow)
+	}
+	if r.Limit <= 0 || r.Window <= 0 {
+		return fmt.Errorf("限流规则的 Limit 和 Window 必须大于0")
+	}
+	if r.Burst < 0 {
+		return fmt.Errorf("限流规则的 Burst 不能为负数")
+	}
+	return nil
+}
+
+// capacity 令牌桶容量 滑动窗口为 Limit
+func (r Rule) capacity() int {
+	if r.Algorithm == AlgorithmTokenBucket && r.Burst > 0 {
+		return r.Burst
+	}
+	return r.Limit
+}
+
+// refillTime 令牌桶从空到满需要的时间
+func (r Rule) refillTime() time.Duration {
+	return time.Duration(float64(r.Window) * float64(r.capacity()) / float64(r.Limit))
+}
+
+// Result 一次限流判断的结果 用于生成 RateLimit-* 响应头
+type Result struct {
+	Allowed    bool
+	Limit      int           //配额
+	Remaining  int           //剩余配额
+	Reset      time.Duration //配额完全恢复（令牌桶）或当前窗口结束（滑动窗口）的时间
+	RetryAfter time.Duration //被拒绝时建议的等待时间
+}
+
+// Store 限流状态存储 同一个 key 的判断必须是原子的
+type Store interface {
+	Allow(ctx context.Context, key string, rule Rule, now time.Time) (Result, error)
+}
+
+// tokenBucket 令牌桶计算 tokens 为上次请求后剩余的令牌数 elapsed 为距上次请求的时间
+func tokenBucket(rule Rule, tokens float64, elapsed time.Duration) (Result, float64) {
+	capacity := float64(rule.capacity())
+	rate := float64(rule.Limit) / float64(rule.Window) //每纳秒补充的令牌数
+	tokens = math.Min(capacity, tokens+float64(elapsed)*rate)
+
+	res := Result{Limit: rule.capacity()}
+	if tokens >= 1 {
+		tokens--
+		res.Allowed = true
+	} else {
+		res.RetryAfter = time.Duration((1 - tokens) / rate)
+	}
+	res.Remaining = int(tokens)
+	res.Reset = time.Duration((capacity - tokens) / rate)
+	return res, tokens
+}
+
+// slidingWindow 滑动窗口计算 elapsed 为当前窗口已经过的时间
+func slidingWindow(rule Rule, prev, cur int64, elapsed time.Duration) Result {
+	weight := 1 - float64(elapsed)/float64(rule.Window)
+	estimate := float64(prev)*weight + float64(cur)
+
+	res := Result{Limit: rule.Limit, Reset: rule.Window - elapsed}
+	if estimate+1 <= float64(rule.Limit) {
+		res.Allowed = true
+		estimate++
+	} else {
+		// 上一窗口的折算计数随时间减少 等到 estimate+1 不超过 Limit
+		// 当前窗口已满时只能等到下一个窗口
+		need := float64(rule.Limit) - 1 - float64(cur)
+		if prev > 0 && need >= 0 {
+			res.RetryAfter = time.Duration((1-need/float64(prev))*float64(rule.Window)) - elapsed
+		}
+		if res.RetryAfter <= 0 {
+			res.RetryAfter = res.Reset
+		}
+	}
+	res.Remaining = int(math.Max(0, float64(rule.Limit)-math.Ceil(estimate)))
+	return res
+}
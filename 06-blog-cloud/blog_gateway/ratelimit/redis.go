@@ -0,0 +1,97 @@
+package ratelimit
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// tokenBucketScript 令牌桶 状态保存在 hash 中 与 tokenBucket 的计算一致
+// KEYS[1] 桶 ARGV: 容量 每毫秒补充的令牌数 当前毫秒时间戳 过期毫秒数
+// 返回 {是否放行, 剩余令牌数*1000}
+var tokenBucketScript = redis.NewScript(`
+local capacity = tonumber(ARGV[1])
+local rate = tonumber(ARGV[2])
+local now = tonumber(ARGV[3])
+local state = redis.call("HMGET", KEYS[1], "tokens", "ts")
+local tokens = tonumber(state[1])
+local ts = tonumber(state[2])
+if tokens == nil then
+  tokens = capacity
+  ts = now
+end
+tokens = math.min(capacity, tokens + math.max(0, now - ts) * rate)
+local allowed = 0
+if tokens >= 1 then
+  tokens = tokens - 1
+  allowed = 1
+end
+redis.call("HSET", KEYS[1], "tokens", tostring(tokens), "ts", now)
+redis.call("PEXPIRE", KEYS[1], ARGV[4])
+return {allowed, math.floor(tokens * 1000)}
+`)
+
+// slidingWindowScript 滑动窗口 每个窗口一个计数器
+// KEYS[1] 当前窗口 KEYS[2] 上一窗口 ARGV: 上限 窗口内已过毫秒数 窗口毫秒数
+// 返回 {是否放行, 上一窗口计数, 当前窗口计数（不含本次）}
+var slidingWindowScript = redis.NewScript(`
+local limit = tonumber(ARGV[1])
+local elapsed = tonumber(ARGV[2])
+local window = tonumber(ARGV[3])
+local cur = tonumber(redis.call("GET", KEYS[1]) or "0")
+local prev = tonumber(redis.call("GET", KEYS[2]) or "0")
+local estimate = prev * (1 - elapsed / window) + cur
+if estimate + 1 > limit then
+  return {0, prev, cur}
+end
+redis.call("INCR", KEYS[1])
+redis.call("PEXPIRE", KEYS[1], window * 2)
+return {1, prev, cur}
+`)
+
+// RedisStore Redis 存储 多个网关实例共享配额
+// 使用网关的时间计算 多个实例之间的时钟误差会使配额略有偏差
+type RedisStore struct {
+	client *redis.Client
+	prefix string
+}
+
+// NewRedisStore 创建 Redis 存储 prefix 为 key 前缀
+func NewRedisStore(client *redis.Client, prefix string) *RedisStore {
+	return &RedisStore{client: client, prefix: prefix}
+}
+
+// Allow 实现 Store 接口
+func (s *RedisStore) Allow(ctx context.Context, key string, rule Rule, now time.Time) (Result, error) {
+	key = s.prefix + key
+	nowMs := now.UnixMilli()
+	windowMs := rule.Window.Milliseconds()
+
+	if rule.Algorithm == AlgorithmTokenBucket {
+		rate := float64(rule.Limit) / float64(windowMs)
+		ttl := rule.refillTime().Milliseconds() + 1000
+		values, err := tokenBucketScript.Run(ctx, s.client, []string{key}, rule.capacity(), rate, nowMs, ttl).Int64Slice()
+		if err != nil {
+			return Result{}, err
+		}
+		// 根据剩余令牌重新计算响应头 与内存实现保持一致
+		tokens := float64(values[1]) / 1000
+		if values[0] == 1 {
+			tokens++
+		}
+		res, _ := tokenBucket(rule, tokens, 0)
+		return res, nil
+	}
+
+	start := nowMs - nowMs%windowMs
+	curKey := key + ":" + strconv.FormatInt(start, 10)
+	prevKey := key + ":" + strconv.FormatInt(start-windowMs, 10)
+	elapsed := nowMs - start
+	values, err := slidingWindowScript.Run(ctx, s.client, []string{curKey, prevKey}, rule.Limit, elapsed, windowMs).Int64Slice()
+	if err != nil {
+		return Result{}, err
+	}
+	return slidingWindow(rule, values[1], values[2], time.Duration(elapsed)*time.Millisecond), nil
+}
@@ -0,0 +1,176 @@
+package registry
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	clientv3 "go.etcd.io/etcd/client/v3"
+)
+
+// 重新订阅的退避时间
+const (
+	etcdInitialBackoff = time.Second
+	etcdMaxBackoff     = 30 * time.Second
+)
+
+// etcd 请求超时时间
+const (
+	etcdDialTimeout    = 5 * time.Second
+	etcdRequestTimeout = 5 * time.Second
+)
+
+// EtcdRegistry 基于 etcd 的注册中心
+// 与 go-zero 的注册方式一致 服务 key 下的实例键为 key/租约ID 值为实例地址
+type EtcdRegistry struct {
+	client *clientv3.Client
+
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+// NewEtcdRegistry 创建 etcd 注册中心 endpoints 形如 127.0.0.1:2379
+// 不等待连接建立 etcd 暂时不可用时由 Watch 在后台重试
+func NewEtcdRegistry(endpoints []string) (*EtcdRegistry, error) {
+	if len(endpoints) == 0 {
+		return nil, errors.New("etcd endpoints 不能为空")
+	}
+	client, err := clientv3.New(clientv3.Config{
+		Endpoints:   endpoints,
+		DialTimeout: etcdDialTimeout,
+	})
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	return &EtcdRegistry{
+		client: client,
+		ctx:    ctx,
+		cancel: cancel,
+	}, nil
+}
+
+// Watch 读取当前实例后在后台监听变化
+// 连接断开时客户端从最后收到的 revision 继续监听 历史版本被压缩或 watch 被取消时退避后重新读取全部实例
+// etcd 暂时不可用时不返回错误 后台持续重试 读取成功后再回调
+func (r *EtcdRegistry) Watch(ctx context.Context, service string, onChange func([]Instance)) error {
+	ctx, cancel := context.WithCancel(ctx)
+	context.AfterFunc(r.ctx, cancel)
+
+	state, rev, err := r.list(ctx, service)
+	if err != nil {
+		log.Printf("[REGISTRY] 读取服务 %s 实例失败: %v，后台重试", service, err)
+		state = nil
+	} else {
+		onChange(state.instances())
+	}
+
+	go func() {
+		defer cancel()
+		backoff := etcdInitialBackoff
+		for {
+			if state != nil {
+				err := r.watch(ctx, service, rev+1, state, onChange)
+				if ctx.Err() != nil {
+					return
+				}
+				log.Printf("[REGISTRY] 监听服务 %s 中断: %v，%s 后重试", service, err, backoff)
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(backoff):
+			}
+
+			// 重新读取全部实例 中断期间的变化不会丢失
+			fresh, freshRev, err := r.list(ctx, service)
+			if err != nil {
+				backoff = min(backoff*2, etcdMaxBackoff)
+				continue
+			}
+			backoff = etcdInitialBackoff
+			if state == nil || !fresh.equal(state) {
+				onChange(fresh.instances())
+			}
+			state, rev = fresh, freshRev
+		}
+	}()
+	return nil
+}
+
+// Close 停止所有订阅并关闭 etcd 客户端
+func (r *EtcdRegistry) Close() error {
+	r.cancel()
+	return r.client.Close()
+}
+
+// etcdState 服务当前实例 键 -> 地址
+type etcdState map[string]string
+
+func (s etcdState) instances() []Instance {
+	instances := make([]Instance, 0, len(s))
+	for key, addr := range s {
+		instances = append(instances, Instance{ID: key, Addr: addr})
+	}
+	return sortInstances(instances)
+}
+
+func (s etcdState) equal(other etcdState) bool {
+	if len(s) != len(other) {
+		return false
+	}
+	for k, v := range s {
+		if other[k] != v {
+			return false
+		}
+	}
+	return true
+}
+
+// servicePrefix 服务 key 下所有实例键的前缀
+func servicePrefix(service string) string {
+	return service + "/"
+}
+
+// list 读取服务当前全部实例和对应的 revision
+func (r *EtcdRegistry) list(ctx context.Context, service string) (etcdState, int64, error) {
+	ctx, cancel := context.WithTimeout(ctx, etcdRequestTimeout)
+	defer cancel()
+	resp, err := r.client.Get(ctx, servicePrefix(service), clientv3.WithPrefix())
+	if err != nil {
+		return nil, 0, err
+	}
+	state := make(etcdState, len(resp.Kvs))
+	for _, kv := range resp.Kvs {
+		state[string(kv.Key)] = string(kv.Value)
+	}
+	return state, resp.Header.Revision, nil
+}
+
+// watch 从 startRev 开始监听 每批事件应用到 state 后回调
+// 历史版本已被压缩、watch 被服务端取消或节点与集群失联时返回
+func (r *EtcdRegistry) watch(ctx context.Context, service string, startRev int64, state etcdState, onChange func([]Instance)) error {
+	ctx, cancel := context.WithCancel(clientv3.WithRequireLeader(ctx))
+	defer cancel()
+	watchCh := r.client.Watch(ctx, servicePrefix(service),
+		clientv3.WithPrefix(), clientv3.WithRev(startRev), clientv3.WithProgressNotify())
+	for resp := range watchCh {
+		if err := resp.Err(); err != nil {
+			return err
+		}
+		// 没有变化时服务端定期发送进度通知
+		if resp.IsProgressNotify() || len(resp.Events) == 0 {
+			continue
+		}
+		for _, event := range resp.Events {
+			if event.Type == clientv3.EventTypeDelete {
+				delete(state, string(event.Kv.Key))
+			} else {
+				state[string(event.Kv.Key)] = string(event.Kv.Value)
+			}
+		}
+		onChange(state.instances())
+	}
+	return errors.New("watch 已关闭")
+}
@@ -0,0 +1,97 @@
+package registry
+
+import (
+	"context"
+	"sync"
+)
+
+// MemoryRegistry 内存注册中心 用于本地开发和测试
+type MemoryRegistry struct {
+	mu        sync.Mutex
+	services  map[string]map[string]Instance
+	watchers  map[string]map[int]func([]Instance)
+	nextWatch int
+	closed    bool
+}
+
+// NewMemoryRegistry 创建内存注册中心
+func NewMemoryRegistry() *MemoryRegistry {
+	return &MemoryRegistry{
+		services: make(map[string]map[string]Instance),
+		watchers: make(map[string]map[int]func([]Instance)),
+	}
+}
+
+// Register 注册实例 ID 相同时覆盖
+func (m *MemoryRegistry) Register(service string, instance Instance) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.services[service] == nil {
+		m.services[service] = make(map[string]Instance)
+	}
+	m.services[service][instance.ID] = instance
+	m.notify(service)
+}
+
+// Deregister 注销实例
+func (m *MemoryRegistry) Deregister(service, id string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.services[service][id]; !ok {
+		return
+	}
+	delete(m.services[service], id)
+	m.notify(service)
+}
+
+// Watch 订阅服务实例变化
+func (m *MemoryRegistry) Watch(ctx context.Context, service string, onChange func([]Instance)) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.closed {
+		return context.Canceled
+	}
+	if m.watchers[service] == nil {
+		m.watchers[service] = make(map[int]func([]Instance))
+	}
+	id := m.nextWatch
+	m.nextWatch++
+	m.watchers[service][id] = onChange
+	onChange(m.instances(service))
+
+	go func() {
+		<-ctx.Done()
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		delete(m.watchers[service], id)
+	}()
+	return nil
+}
+
+// Close 停止所有订阅
+func (m *MemoryRegistry) Close() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.closed = true
+	m.watchers = make(map[string]map[int]func([]Instance))
+	return nil
+}
+
+// notify 调用方需持有锁 回调在锁内执行以保证串行
+func (m *MemoryRegistry) notify(service string) {
+	if len(m.watchers[service]) == 0 {
+		return
+	}
+	instances := m.instances(service)
+	for _, onChange := range m.watchers[service] {
+		onChange(instances)
+	}
+}
+
+func (m *MemoryRegistry) instances(service string) []Instance {
+	instances := make([]Instance, 0, len(m.services[service]))
+	for _, instance := range m.services[service] {
+		instances = append(instances, instance)
+	}
+	return sortInstances(instances)
+}
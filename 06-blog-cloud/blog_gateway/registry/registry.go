@@ -0,0 +1,29 @@
+package registry
+
+import (
+	"context"
+	"sort"
+)
+
+// Instance 服务实例
+type Instance struct {
+	// ID 实例在注册中心中的唯一标识 etcd 中为完整的键
+	ID string
+	// Addr 实例地址 host:port
+	Addr string
+}
+
+// Registry 服务注册中心
+// 同一次 Watch 的回调串行执行 回调参数为服务当前的全部实例
+type Registry interface {
+	// Watch 订阅服务实例变化 订阅成功后立即回调一次当前实例 ctx 结束时停止订阅
+	Watch(ctx context.Context, service string, onChange func([]Instance)) error
+	// Close 关闭注册中心 停止所有订阅
+	Close() error
+}
+
+// sortInstances 按ID排序 保证同样的实例集合回调参数顺序一致
+func sortInstances(instances []Instance) []Instance {
+	sort.Slice(instances, func(i, j int) bool { return instances[i].ID < instances[j].ID })
+	return instances
+}
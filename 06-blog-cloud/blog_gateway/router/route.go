@@ -0,0 +1,391 @@
+package router
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"gateway/compose"
+	"gateway/config"
+	"gateway/ratelimit"
+)
+
+// Route 编译后的路由规则
+type Route struct {
+	Upstream string `json:"upstream,omitempty"` //聚合路由为空
+	Method   string `json:"method"`
+	Pattern  string `json:"path"`
+	// 匹配条件 为空表示不限制
+	Host    string            `json:"host,omitempty"`    //精确主机名或 *.example.com
+	Headers map[string]string `json:"headers,omitempty"` //请求头必须全部相等
+	// 转发路径 Rewrite 优先于 StripPrefix
+	StripPrefix string `json:"strip_prefix,omitempty"`
+	Rewrite     string `json:"rewrite,omitempty"`
+	// 限流规则 为空时不限流
+	RateLimit *RouteLimit `json:"rate_limit,omitempty"`
+	// Compose 聚合路由 不为空时由网关请求各部分的上游并合并结果
+	Compose *compose.Composition `json:"compose,omitempty"`
+
+	params []string //路由模式中的参数名 按出现顺序
+	order  int      //配置文件中的顺序 条件相同的路由按顺序匹配
+}
+
+// 限流计数维度
+const (
+	LimitByIP    = "ip"
+	LimitByUser  = "user"
+	LimitByRoute = "route"
+)
+
+// RouteLimit 路由的限流规则
+type RouteLimit struct {
+	ratelimit.Rule
+	By string `json:"by"` //ip、user 或 route
+}
+
+// Param 路径参数
+type Param struct {
+	Key   string
+	Value string
+}
+
+// Match 路由匹配结果
+type Match struct {
+	Route  *Route
+	Params []Param
+	// Path 转发给上游的路径 已处理前缀剥离和重写
+	Path string
+}
+
+// Param 获取路径参数 通配符参数名为 * 或模式中 * 之后的名称
+func (m *Match) Param(key string) string {
+	for _, p := range m.Params {
+		if p.Key == key {
+			return p.Value
+		}
+	}
+	return ""
+}
+
+// RouteTable 路由表 每个请求方法一棵基数树
+type RouteTable struct {
+	trees  map[string]*node
+	routes []*Route //按配置顺序
+}
+
+// NewRouteTable 编译所有上游的映射规则和聚合路由
+// 方法、路径结构、主机和请求头条件完全相同的两条规则视为冲突
+func NewRouteTable(upstreams []config.Upstream, compositions []config.CompositionConfig) (*RouteTable, error) {
+	t := &RouteTable{trees: make(map[string]*node)}
+	order := 0
+	for _, u := range upstreams {
+		for _, mapping := range u.Mappings {
+			route, err := newRoute(u, mapping, order)
+			if err != nil {
+				return nil, err
+			}
+			if err := t.add(route); err != nil {
+				return nil, err
+			}
+			order++
+		}
+	}
+	for _, comp := range compositions {
+		if err := t.addComposition(comp, order); err != nil {
+			return nil, err
+		}
+		order++
+	}
+	return t, nil
+}
+
+// addComposition 聚合路由与映射规则共用路由树 路径参数在编译各部分的路径模板时使用
+func (t *RouteTable) addComposition(comp config.CompositionConfig, order int) error {
+	route := &Route{
+		Method:    strings.ToUpper(comp.Method),
+		Pattern:   comp.Path,
+		RateLimit: newRouteLimit(comp.RateLimit),
+		order:     order,
+	}
+	if route.RateLimit != nil {
+		if err := route.RateLimit.Validate(); err != nil {
+			return fmt.Errorf("聚合路由 %s %s: %w", route.Method, comp.Path, err)
+		}
+	}
+	if err := t.add(route); err != nil {
+		return err
+	}
+	composition, err := compose.New(comp, route.params)
+	if err != nil {
+		return err
+	}
+	route.Compose = composition
+	return nil
+}
+
+func newRoute(u config.Upstream, mapping config.MappingRule, order int) (*Route, error) {
+	upstreamName := u.Name
+	method := strings.ToUpper(mapping.Method)
+	if method == "" {
+		return nil, fmt.Errorf("上游 %s 的路由 %s 缺少 Method", upstreamName, mapping.Path)
+	}
+	if mapping.Rewrite != "" && mapping.StripPrefix != "" {
+		return nil, fmt.Errorf("上游 %s 的路由 %s %s 不能同时配置 Rewrite 和 StripPrefix", upstreamName, method, mapping.Path)
+	}
+	route := &Route{
+		Upstream:    upstreamName,
+		Method:      method,
+		Pattern:     mapping.Path,
+		Host:        strings.ToLower(mapping.Host),
+		StripPrefix: mapping.StripPrefix,
+		Rewrite:     mapping.Rewrite,
+		order:       order,
+	}
+	// 映射没有配置限流时使用上游的默认规则
+	limit := mapping.RateLimit
+	if limit == nil {
+		limit = u.RateLimit
+	}
+	if route.RateLimit = newRouteLimit(limit); route.RateLimit != nil {
+		if err := route.RateLimit.Validate(); err != nil {
+			return nil, fmt.Errorf("上游 %s 的路由 %s %s: %w", upstreamName, method, mapping.Path, err)
+		}
+	}
+	if len(mapping.Headers) > 0 {
+		route.Headers = make(map[string]string, len(mapping.Headers))
+		for k, v := range mapping.Headers {
+			route.Headers[http.CanonicalHeaderKey(k)] = v
+		}
+	}
+	return route, nil
+}
+
+func newRouteLimit(limit *config.RateLimitConfig) *RouteLimit {
+	if limit == nil {
+		return nil
+	}
+	return &RouteLimit{
+		Rule: ratelimit.Rule{
+			Algorithm: limit.Algorithm,
+			Limit:     limit.Limit,
+			Window:    time.Duration(limit.Window) * time.Millisecond,
+			Burst:     limit.Burst,
+		},
+		By: limit.Key,
+	}
+}
+
+// Target 指标中使用的转发目标 聚合路由为 compose
+func (route *Route) Target() string {
+	if route.Compose != nil {
+		return "compose"
+	}
+	return route.Upstream
+}
+
+// source 错误信息中路由的来源
+func (route *Route) source() string {
+	if route.Upstream == "" {
+		return "聚合路由"
+	}
+	return "上游 " + route.Upstream
+}
+
+// add 插入路由并检查冲突
+func (t *RouteTable) add(route *Route) error {
+	segments, params, err := parsePattern(route.Pattern)
+	if err != nil {
+		return fmt.Errorf("%s: %w", route.source(), err)
+	}
+	route.params = params
+	if err := checkRewrite(route); err != nil {
+		return err
+	}
+
+	root := t.trees[route.Method]
+	if root == nil {
+		root = &node{}
+		t.trees[route.Method] = root
+	}
+	leaf := root.insert(segments)
+	for _, existing := range leaf.routes {
+		if sameConditions(existing, route) {
+			return fmt.Errorf("路由冲突: %s %s（%s）与 %s %s（%s）",
+				route.Method, route.Pattern, route.source(), existing.Method, existing.Pattern, existing.source())
+		}
+	}
+	leaf.routes = append(leaf.routes, route)
+	t.routes = append(t.routes, route)
+	// 条件越具体越先匹配
+	sort.SliceStable(leaf.routes, func(i, j int) bool {
+		a, b := leaf.routes[i], leaf.routes[j]
+		if ra, rb := hostRank(a.Host), hostRank(b.Host); ra != rb {
+			return ra > rb
+		}
+		if len(a.Headers) != len(b.Headers) {
+			return len(a.Headers) > len(b.Headers)
+		}
+		return a.order < b.order
+	})
+	return nil
+}
+
+// Routes 所有路由 按配置顺序
+func (t *RouteTable) Routes() []*Route {
+	return t.routes
+}
+
+// Lookup 查找请求对应的路由
+func (t *RouteTable) Lookup(r *http.Request) (*Match, bool) {
+	root := t.trees[r.Method]
+	if root == nil {
+		return nil, false
+	}
+	var buf [4]string
+	route, values := root.find(r.URL.Path, buf[:0], requestHost(r), r.Header)
+	if route == nil {
+		return nil, false
+	}
+	m := &Match{Route: route, Path: r.URL.Path}
+	if len(values) > 0 {
+		m.Params = make([]Param, len(values))
+		for i, v := range values {
+			m.Params[i] = Param{Key: route.params[i], Value: v}
+		}
+	}
+	m.Path = route.targetPath(m)
+	return m, true
+}
+
+// matches 判断主机和请求头条件
+func (route *Route) matches(host string, header http.Header) bool {
+	if route.Host != "" {
+		if strings.HasPrefix(route.Host, "*.") {
+			if !strings.HasSuffix(host, route.Host[1:]) {
+				return false
+			}
+		} else if host != route.Host {
+			return false
+		}
+	}
+	for k, v := range route.Headers {
+		if header.Get(k) != v {
+			return false
+		}
+	}
+	return true
+}
+
+// targetPath 计算转发给上游的路径
+func (route *Route) targetPath(m *Match) string {
+	if route.Rewrite != "" {
+		return rewritePath(route.Rewrite, m)
+	}
+	if route.StripPrefix != "" && strings.HasPrefix(m.Path, route.StripPrefix) {
+		path := m.Path[len(route.StripPrefix):]
+		if !strings.HasPrefix(path, "/") {
+			path = "/" + path
+		}
+		return path
+	}
+	return m.Path
+}
+
+// rewritePath 用路径参数替换重写模板中的 :name 和 *name 段
+// 通配符为空时去掉该段 如 /api/* 在没有剩余路径时得到 /api
+func rewritePath(template string, m *Match) string {
+	parts := strings.Split(template, "/")
+	out := parts[:0]
+	for i, part := range parts {
+		switch {
+		case strings.HasPrefix(part, ":"):
+			part = m.Param(part[1:])
+		case strings.HasPrefix(part, "*"):
+			name := part[1:]
+			if name == "" {
+				name = "*"
+			}
+			part = m.Param(name)
+			if part == "" && i == len(parts)-1 {
+				continue
+			}
+		}
+		out = append(out, part)
+	}
+	path := strings.Join(out, "/")
+	if path == "" {
+		return "/"
+	}
+	return path
+}
+
+// checkRewrite 重写模板只能引用路由模式中存在的参数
+func checkRewrite(route *Route) error {
+	if route.Rewrite == "" {
+		return nil
+	}
+	if !strings.HasPrefix(route.Rewrite, "/") {
+		return fmt.Errorf("上游 %s 的路由 %s 的 Rewrite %q 必须以 / 开头", route.Upstream, route.Pattern, route.Rewrite)
+	}
+	for _, part := range strings.Split(route.Rewrite, "/") {
+		var name string
+		switch {
+		case strings.HasPrefix(part, ":"):
+			name = part[1:]
+		case strings.HasPrefix(part, "*"):
+			name = part[1:]
+			if name == "" {
+				name = "*"
+			}
+		default:
+			continue
+		}
+		found := false
+		for _, p := range route.params {
+			if p == name {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return fmt.Errorf("上游 %s 的路由 %s 的 Rewrite 引用了不存在的参数 %q", route.Upstream, route.Pattern, name)
+		}
+	}
+	return nil
+}
+
+// sameConditions 主机和请求头条件是否完全相同
+func sameConditions(a, b *Route) bool {
+	if a.Host != b.Host || len(a.Headers) != len(b.Headers) {
+		return false
+	}
+	for k, v := range a.Headers {
+		if bv, ok := b.Headers[k]; !ok || bv != v {
+			return false
+		}
+	}
+	return true
+}
+
+// hostRank 精确主机 > 通配主机 > 不限主机
+func hostRank(host string) int {
+	switch {
+	case host == "":
+		return 0
+	case strings.HasPrefix(host, "*."):
+		return 1
+	}
+	return 2
+}
+
+// requestHost 去掉端口的小写主机名
+func requestHost(r *http.Request) string {
+	host := r.Host
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	return strings.ToLower(host)
+}
@@ -0,0 +1,297 @@
+package router
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"gateway/config"
+)
+
+func testUpstream(name string, mappings ...config.MappingRule) config.Upstream {
+	return config.Upstream{Name: name, Mappings: mappings}
+}
+
+func mustRouteTable(t testing.TB, upstreams ...config.Upstream) *RouteTable {
+	t.Helper()
+	table, err := NewRouteTable(upstreams, nil)
+	if err != nil {
+		t.Fatalf("NewRouteTable: %v", err)
+	}
+	return table
+}
+
+func TestLookupPrecedence(t *testing.T) {
+	// 配置顺序与优先级相反 匹配结果不受顺序影响
+	table := mustRouteTable(t,
+		testUpstream("wildcard", config.MappingRule{Method: "GET", Path: "/v1/post/*"}),
+		testUpstream("param", config.MappingRule{Method: "GET", Path: "/v1/post/:id"}),
+		testUpstream("exact", config.MappingRule{Method: "GET", Path: "/v1/post/latest"}),
+	)
+
+	tests := []struct {
+		method, path string
+		upstream     string // 为空表示没有匹配
+		param, value string
+	}{
+		{"GET", "/v1/post/latest", "exact", "", ""},
+		{"GET", "/v1/post/42", "param", "id", "42"},
+		{"GET", "/v1/post/latestx", "param", "id", "latestx"},
+		{"GET", "/v1/post/42/comments", "wildcard", "*", "42/comments"},
+		{"GET", "/v1/post/latest/comments", "wildcard", "*", "latest/comments"},
+		{"GET", "/v1/post", "wildcard", "*", ""},
+		{"GET", "/v1/posts", "", "", ""},
+		{"POST", "/v1/post/42", "", "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.method+" "+tt.path, func(t *testing.T) {
+			m, ok := table.Lookup(httptest.NewRequest(tt.method, tt.path, nil))
+			if tt.upstream == "" {
+				if ok {
+					t.Fatalf("got %s %s, want no match", m.Route.Upstream, m.Route.Pattern)
+				}
+				return
+			}
+			if !ok {
+				t.Fatalf("no match, want %s", tt.upstream)
+			}
+			if m.Route.Upstream != tt.upstream {
+				t.Errorf("upstream = %s, want %s", m.Route.Upstream, tt.upstream)
+			}
+			if tt.param != "" && m.Param(tt.param) != tt.value {
+				t.Errorf("param %s = %q, want %q", tt.param, m.Param(tt.param), tt.value)
+			}
+		})
+	}
+}
+
+func TestNewRouteTableConflicts(t *testing.T) {
+	tests := []struct {
+		name      string
+		upstreams []config.Upstream
+		wantErr   bool
+	}{
+		{
+			name: "same path in two upstreams",
+			upstreams: []config.Upstream{
+				testUpstream("a", config.MappingRule{Method: "GET", Path: "/v1/post"}),
+				testUpstream("b", config.MappingRule{Method: "get", Path: "/v1/post"}),
+			},
+			wantErr: true,
+		},
+		{
+			name: "params with different names",
+			upstreams: []config.Upstream{
+				testUpstream("a", config.MappingRule{Method: "GET", Path: "/v1/post/:id"}),
+				testUpstream("b", config.MappingRule{Method: "GET", Path: "/v1/post/:pid"}),
+			},
+			wantErr: true,
+		},
+		{
+			name: "wildcards with different names",
+			upstreams: []config.Upstream{
+				testUpstream("a", config.MappingRule{Method: "GET", Path: "/static/*"}),
+				testUpstream("b", config.MappingRule{Method: "GET", Path: "/static/*file"}),
+			},
+			wantErr: true,
+		},
+		{
+			name: "same headers in different order",
+			upstreams: []config.Upstream{
+				testUpstream("a", config.MappingRule{Method: "GET", Path: "/v1/post", Headers: map[string]string{"x-version": "2", "X-Tenant": "t"}}),
+				testUpstream("b", config.MappingRule{Method: "GET", Path: "/v1/post", Headers: map[string]string{"X-Tenant": "t", "X-Version": "2"}}),
+			},
+			wantErr: true,
+		},
+		{
+			name: "different methods",
+			upstreams: []config.Upstream{
+				testUpstream("a", config.MappingRule{Method: "GET", Path: "/v1/post"}),
+				testUpstream("b", config.MappingRule{Method: "POST", Path: "/v1/post"}),
+			},
+		},
+		{
+			name: "different hosts",
+			upstreams: []config.Upstream{
+				testUpstream("a", config.MappingRule{Method: "GET", Path: "/v1/post"}),
+				testUpstream("b", config.MappingRule{Method: "GET", Path: "/v1/post", Host: "api.example.com"}),
+			},
+		},
+		{
+			name: "different headers",
+			upstreams: []config.Upstream{
+				testUpstream("a", config.MappingRule{Method: "GET", Path: "/v1/post"}),
+				testUpstream("b", config.MappingRule{Method: "GET", Path: "/v1/post", Headers: map[string]string{"X-Version": "2"}}),
+			},
+		},
+		{
+			name: "exact param and wildcard",
+			upstreams: []config.Upstream{
+				testUpstream("a", config.MappingRule{Method: "GET", Path: "/v1/post/latest"}),
+				testUpstream("b", config.MappingRule{Method: "GET", Path: "/v1/post/:id"}),
+				testUpstream("c", config.MappingRule{Method: "GET", Path: "/v1/post/*"}),
+			},
+		},
+		{
+			name:      "missing method",
+			upstreams: []config.Upstream{testUpstream("a", config.MappingRule{Path: "/v1/post"})},
+			wantErr:   true,
+		},
+		{
+			name:      "wildcard not at the end",
+			upstreams: []config.Upstream{testUpstream("a", config.MappingRule{Method: "GET", Path: "/v1/*/post"})},
+			wantErr:   true,
+		},
+		{
+			name:      "param inside a segment",
+			upstreams: []config.Upstream{testUpstream("a", config.MappingRule{Method: "GET", Path: "/v1/post-:id"})},
+			wantErr:   true,
+		},
+		{
+			name:      "rewrite and strip prefix together",
+			upstreams: []config.Upstream{testUpstream("a", config.MappingRule{Method: "GET", Path: "/api/*", StripPrefix: "/api", Rewrite: "/*"})},
+			wantErr:   true,
+		},
+		{
+			name:      "rewrite with unknown param",
+			upstreams: []config.Upstream{testUpstream("a", config.MappingRule{Method: "GET", Path: "/api/posts/:id", Rewrite: "/v1/post/:pid"})},
+			wantErr:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewRouteTable(tt.upstreams, nil)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestLookupHostAndHeaders(t *testing.T) {
+	table := mustRouteTable(t,
+		testUpstream("any", config.MappingRule{Method: "GET", Path: "/v1/post"}),
+		testUpstream("wildcard-host", config.MappingRule{Method: "GET", Path: "/v1/post", Host: "*.example.com"}),
+		testUpstream("exact-host", config.MappingRule{Method: "GET", Path: "/v1/post", Host: "API.example.com"}),
+		testUpstream("v2", config.MappingRule{Method: "GET", Path: "/v1/post", Headers: map[string]string{"x-version": "2"}}),
+		testUpstream("host-only", config.MappingRule{Method: "GET", Path: "/v1/admin", Host: "admin.example.com"}),
+	)
+
+	tests := []struct {
+		name     string
+		host     string
+		header   map[string]string
+		path     string
+		upstream string // 为空表示没有匹配
+	}{
+		{"no conditions", "localhost:6666", nil, "/v1/post", "any"},
+		{"exact host with port", "api.example.com:6666", nil, "/v1/post", "exact-host"},
+		{"exact host case insensitive", "API.Example.com", nil, "/v1/post", "exact-host"},
+		{"wildcard host", "blog.example.com", nil, "/v1/post", "wildcard-host"},
+		{"wildcard host needs subdomain", "example.com", nil, "/v1/post", "any"},
+		{"host beats header", "api.example.com", map[string]string{"X-Version": "2"}, "/v1/post", "exact-host"},
+		{"header", "localhost", map[string]string{"X-Version": "2"}, "/v1/post", "v2"},
+		{"header value mismatch", "localhost", map[string]string{"X-Version": "3"}, "/v1/post", "any"},
+		{"host only route", "admin.example.com", nil, "/v1/admin", "host-only"},
+		{"host only route other host", "api.example.com", nil, "/v1/admin", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", tt.path, nil)
+			req.Host = tt.host
+			for k, v := range tt.header {
+				req.Header.Set(k, v)
+			}
+			m, ok := table.Lookup(req)
+			if tt.upstream == "" {
+				if ok {
+					t.Fatalf("got %s, want no match", m.Route.Upstream)
+				}
+				return
+			}
+			if !ok {
+				t.Fatalf("no match, want %s", tt.upstream)
+			}
+			if m.Route.Upstream != tt.upstream {
+				t.Errorf("upstream = %s, want %s", m.Route.Upstream, tt.upstream)
+			}
+		})
+	}
+}
+
+func TestLookupTargetPath(t *testing.T) {
+	table := mustRouteTable(t,
+		testUpstream("strip",
+			config.MappingRule{Method: "GET", Path: "/api/*", StripPrefix: "/api"},
+			config.MappingRule{Method: "GET", Path: "/svc/:name", StripPrefix: "/svc/"},
+		),
+		testUpstream("rewrite",
+			config.MappingRule{Method: "GET", Path: "/v2/posts/:id", Rewrite: "/v1/post/:id"},
+			config.MappingRule{Method: "GET", Path: "/v2/posts/:id/comments/:cid", Rewrite: "/v1/comment/:cid/post/:id"},
+			config.MappingRule{Method: "GET", Path: "/files/*path", Rewrite: "/static/*path"},
+		),
+		testUpstream("plain", config.MappingRule{Method: "GET", Path: "/v1/post/:id"}),
+	)
+
+	tests := []struct {
+		path, want string
+	}{
+		{"/api/v1/post/1", "/v1/post/1"},
+		{"/api", "/"},
+		{"/svc/user", "/user"},
+		{"/v2/posts/42", "/v1/post/42"},
+		{"/v2/posts/42/comments/7", "/v1/comment/7/post/42"},
+		{"/files/css/site.css", "/static/css/site.css"},
+		{"/files", "/static"},
+		{"/v1/post/42", "/v1/post/42"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			m, ok := table.Lookup(httptest.NewRequest("GET", tt.path, nil))
+			if !ok {
+				t.Fatal("no match")
+			}
+			if m.Path != tt.want {
+				t.Errorf("path = %q, want %q", m.Path, tt.want)
+			}
+		})
+	}
+}
+
+// BenchmarkLookup 网关每个请求都会经过路由匹配
+func BenchmarkLookup(b *testing.B) {
+	table := mustRouteTable(b,
+		testUpstream("postapi",
+			config.MappingRule{Method: "GET", Path: "/v1/post"},
+			config.MappingRule{Method: "POST", Path: "/v1/post"},
+			config.MappingRule{Method: "GET", Path: "/v1/post/list"},
+			config.MappingRule{Method: "GET", Path: "/v1/post/:id"},
+			config.MappingRule{Method: "PUT", Path: "/v1/post/:id/comments/:cid"},
+			config.MappingRule{Method: "GET", Path: "/v2/posts/:id", Rewrite: "/v1/post/:id"},
+		),
+		testUpstream("userapi",
+			config.MappingRule{Method: "POST", Path: "/v1/user/login"},
+			config.MappingRule{Method: "POST", Path: "/v1/user/register"},
+			config.MappingRule{Method: "GET", Path: "/v1/user/:id"},
+			config.MappingRule{Method: "GET", Path: "/v1/user/:id", Headers: map[string]string{"X-Version": "2"}},
+		),
+		testUpstream("static", config.MappingRule{Method: "GET", Path: "/static/*", StripPrefix: "/static"}),
+	)
+
+	cases := []struct{ name, method, path string }{
+		{"exact", "GET", "/v1/post"},
+		{"param", "GET", "/v1/post/42"},
+		{"two params", "PUT", "/v1/post/42/comments/7"},
+		{"rewrite", "GET", "/v2/posts/42"},
+		{"wildcard", "GET", "/static/css/site.css"},
+		{"not found", "GET", "/v1/not/found"},
+	}
+	for _, c := range cases {
+		req := httptest.NewRequest(c.method, c.path, nil)
+		b.Run(c.name, func(b *testing.B) {
+			b.ReportAllocs()
+			for b.Loop() {
+				table.Lookup(req)
+			}
+		})
+	}
+}
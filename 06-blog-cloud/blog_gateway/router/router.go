@@ -1,104 +1,108 @@
 package router
 
 import (
+	"context"
 	"fmt"
-	"net/http/httputil"
-	"net/url"
-	"strings"
+	"net/http"
+	"time"
 
 	"gateway/config"
+	"gateway/registry"
+	"gateway/upstream"
 )
 
 // Router 路由管理器
 type Router struct {
 	config *config.Config
+	routes *RouteTable
 	// 存储上游服务的代理实例
-	proxies map[string]*httputil.ReverseProxy
+	proxies map[string]*upstream.Upstream
 }
 
 // NewRouter 创建路由管理器实例
-func NewRouter(config *config.Config) *Router {
+// 配置了 Discovery 的上游从注册中心订阅实例 ctx 结束时停止订阅和健康检查
+func NewRouter(ctx context.Context, config *config.Config, reg registry.Registry) (*Router, error) {
+	routes, err := NewRouteTable(config.Upstreams, config.Compositions)
+	if err != nil {
+		return nil, err
+	}
 	router := &Router{
 		config:  config,
-		proxies: make(map[string]*httputil.ReverseProxy),
+		routes:  routes,
+		proxies: make(map[string]*upstream.Upstream),
 	}
 
 	// 初始化所有上游服务的代理
-	for _, upstream := range config.Upstreams {
-		targetURL, err := url.Parse(fmt.Sprintf("http://%s", upstream.Http.Target))
+	for _, cfg := range config.Upstreams {
+		proxy, err := upstream.New(cfg.Name, upstreamOptions(cfg.Http))
 		if err != nil {
-			continue
-		}
-
-		// 创建反向代理
-		proxy := httputil.NewSingleHostReverseProxy(targetURL)
-		router.proxies[upstream.Name] = proxy
-	}
-
-	return router
-}
-
-// MatchRoute 匹配路由，返回匹配的上游服务名称
-func (r *Router) MatchRoute(method, path string) string {
-	for _, upstream := range r.config.Upstreams {
-		for _, mapping := range upstream.Mappings {
-			if mapping.Method == method && r.matchPath(mapping.Path, path) {
-				return upstream.Name
-			}
+			return nil, err
 		}
-	}
-	return ""
-}
-
-// matchPath 匹配路径，支持精确匹配、路径参数和通配符
-func (r *Router) matchPath(pattern, path string) bool {
-	// 精确匹配
-	if pattern == path {
-		return true
-	}
-
-	// 通配符匹配
-	if strings.HasSuffix(pattern, "/*") {
-		prefix := strings.TrimSuffix(pattern, "/*")
-		if strings.HasPrefix(path, prefix) {
-			// 确保是完整路径段匹配
-			if len(prefix) == 0 {
-				return true
-			}
-			if len(path) == len(prefix) {
-				return true
+		if cfg.Discovery.Key != "" {
+			if reg == nil {
+				return nil, fmt.Errorf("上游 %s 配置了 Discovery 但没有配置注册中心", cfg.Name)
 			}
-			if path[len(prefix)] == '/' {
-				return true
+			if err := reg.Watch(ctx, cfg.Discovery.Key, proxy.SetInstances); err != nil {
+				return nil, fmt.Errorf("订阅上游 %s 失败: %w", cfg.Name, err)
 			}
 		}
-		return false
+		proxy.Start(ctx)
+		router.proxies[cfg.Name] = proxy
 	}
 
-	// 路径参数匹配（如 /v1/post/:id）
-	if strings.Contains(pattern, ":") {
-		patternParts := strings.Split(pattern, "/")
-		pathParts := strings.Split(path, "/")
+	return router, nil
+}
 
-		if len(patternParts) != len(pathParts) {
-			return false
-		}
+// Match 匹配路由 返回目标上游和转发路径
+func (r *Router) Match(req *http.Request) (*Match, bool) {
+	return r.routes.Lookup(req)
+}
 
-		for i, patternPart := range patternParts {
-			if strings.HasPrefix(patternPart, ":") {
-				continue // 跳过参数部分
-			}
-			if patternPart != pathParts[i] {
-				return false
-			}
+// Routes 所有路由
+func (r *Router) Routes() []*Route {
+	return r.routes.Routes()
+}
+
+// UpstreamStatus 所有上游的状态 按配置顺序
+func (r *Router) UpstreamStatus() []upstream.Status {
+	statuses := make([]upstream.Status, 0, len(r.config.Upstreams))
+	for _, cfg := range r.config.Upstreams {
+		if proxy := r.proxies[cfg.Name]; proxy != nil {
+			statuses = append(statuses, proxy.Status())
 		}
-		return true
 	}
-
-	return false
+	return statuses
 }
 
 // GetProxy 获取上游服务的代理实例
-func (r *Router) GetProxy(upstreamName string) *httputil.ReverseProxy {
+func (r *Router) GetProxy(upstreamName string) *upstream.Upstream {
 	return r.proxies[upstreamName]
 }
+
+// upstreamOptions 把配置文件中的毫秒数转换为上游配置
+func upstreamOptions(cfg config.HttpConfig) upstream.Options {
+	ms := func(n int) time.Duration { return time.Duration(n) * time.Millisecond }
+	opts := upstream.Options{
+		Balancer: cfg.Balancer,
+		Timeout:  ms(cfg.Timeout),
+		Retries:  cfg.Retries,
+		HealthCheck: upstream.HealthCheckOptions{
+			Path:               cfg.HealthCheck.Path,
+			Interval:           ms(cfg.HealthCheck.Interval),
+			Timeout:            ms(cfg.HealthCheck.Timeout),
+			HealthyThreshold:   cfg.HealthCheck.HealthyThreshold,
+			UnhealthyThreshold: cfg.HealthCheck.UnhealthyThreshold,
+			MaxFails:           cfg.HealthCheck.MaxFails,
+			FailTimeout:        ms(cfg.HealthCheck.FailTimeout),
+		},
+		BreakerThreshold: cfg.CircuitBreaker.FailureThreshold,
+		BreakerTimeout:   ms(cfg.CircuitBreaker.OpenTimeout),
+	}
+	if cfg.Target != "" {
+		opts.Targets = append(opts.Targets, upstream.Target{Addr: cfg.Target, Weight: 1})
+	}
+	for _, target := range cfg.Targets {
+		opts.Targets = append(opts.Targets, upstream.Target{Addr: target.Addr, Weight: target.Weight})
+	}
+	return opts
+}
@@ -0,0 +1,182 @@
+package router
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// node 基数树节点
+// 静态部分按公共前缀压缩 路径参数和通配符作为独立子节点
+// 匹配优先级 静态 > 路径参数 > 通配符 前面的分支匹配失败时回溯尝试后面的分支
+type node struct {
+	prefix   string  //静态前缀 参数节点和通配符节点为空
+	indices  string  //静态子节点前缀的首字节 与 static 一一对应
+	static   []*node //静态子节点
+	param    *node   //路径参数子节点 匹配一个非空路径段
+	wildcard *node   //通配符子节点 匹配剩余路径
+	routes   []*Route
+}
+
+// segment 路由模式解析后的片段
+type segment struct {
+	kind byte //'s' 静态 ':' 路径参数 '*' 通配符
+	text string
+}
+
+// parsePattern 解析路由模式
+// 路径参数和通配符必须占据完整的路径段 通配符只能出现在末尾
+// 通配符段 /* 同时匹配前缀本身 如 /v1/post/* 匹配 /v1/post 和 /v1/post/1/comments
+func parsePattern(pattern string) ([]segment, []string, error) {
+	if !strings.HasPrefix(pattern, "/") {
+		return nil, nil, fmt.Errorf("路径 %q 必须以 / 开头", pattern)
+	}
+	var segments []segment
+	var names []string
+	var static strings.Builder
+	parts := strings.Split(pattern[1:], "/")
+	for i, part := range parts {
+		switch {
+		case strings.HasPrefix(part, ":"):
+			name := part[1:]
+			if name == "" {
+				return nil, nil, fmt.Errorf("路径 %q 的参数缺少名称", pattern)
+			}
+			static.WriteByte('/')
+			segments = append(segments, segment{kind: 's', text: static.String()}, segment{kind: ':', text: name})
+			static.Reset()
+			names = append(names, name)
+		case strings.HasPrefix(part, "*"):
+			if i != len(parts)-1 {
+				return nil, nil, fmt.Errorf("路径 %q 的通配符只能出现在末尾", pattern)
+			}
+			name := part[1:]
+			if name == "" {
+				name = "*"
+			}
+			if static.Len() > 0 {
+				segments = append(segments, segment{kind: 's', text: static.String()})
+				static.Reset()
+			}
+			segments = append(segments, segment{kind: '*', text: name})
+			names = append(names, name)
+		default:
+			if strings.ContainsAny(part, ":*") {
+				return nil, nil, fmt.Errorf("路径 %q 的参数和通配符必须占据完整的路径段", pattern)
+			}
+			static.WriteByte('/')
+			static.WriteString(part)
+		}
+	}
+	if static.Len() > 0 {
+		segments = append(segments, segment{kind: 's', text: static.String()})
+	}
+	return segments, names, nil
+}
+
+// insert 按解析后的片段插入路由 返回路由所在的叶子节点
+func (n *node) insert(segments []segment) *node {
+	for _, seg := range segments {
+		switch seg.kind {
+		case 's':
+			n = n.insertStatic(seg.text)
+		case ':':
+			if n.param == nil {
+				n.param = &node{}
+			}
+			n = n.param
+		case '*':
+			if n.wildcard == nil {
+				n.wildcard = &node{}
+			}
+			n = n.wildcard
+		}
+	}
+	return n
+}
+
+// insertStatic 插入静态路径 必要时拆分已有节点的公共前缀
+func (n *node) insertStatic(path string) *node {
+	for path != "" {
+		i := strings.IndexByte(n.indices, path[0])
+		if i < 0 {
+			child := &node{prefix: path}
+			n.indices += path[:1]
+			n.static = append(n.static, child)
+			return child
+		}
+		child := n.static[i]
+		l := commonPrefix(child.prefix, path)
+		if l < len(child.prefix) {
+			// 拆分 child 原来的内容下沉为新的子节点
+			rest := *child
+			rest.prefix = child.prefix[l:]
+			*child = node{
+				prefix:  child.prefix[:l],
+				indices: rest.prefix[:1],
+				static:  []*node{&rest},
+			}
+		}
+		path = path[l:]
+		n = child
+	}
+	return n
+}
+
+// find 在 n 之下查找剩余路径 叶子节点上的路由还需满足主机和请求头条件
+// params 按路由模式中出现的顺序保存参数值
+func (n *node) find(path string, params []string, host string, header http.Header) (*Route, []string) {
+	if path == "" && len(n.routes) > 0 {
+		if route := acceptRoute(n.routes, host, header); route != nil {
+			return route, params
+		}
+	}
+	// 静态
+	if path != "" {
+		if i := strings.IndexByte(n.indices, path[0]); i >= 0 {
+			child := n.static[i]
+			if strings.HasPrefix(path, child.prefix) {
+				if route, values := child.find(path[len(child.prefix):], params, host, header); route != nil {
+					return route, values
+				}
+			}
+		}
+	}
+	// 路径参数
+	if n.param != nil && path != "" {
+		end := strings.IndexByte(path, '/')
+		if end < 0 {
+			end = len(path)
+		}
+		if end > 0 {
+			if route, values := n.param.find(path[end:], append(params, path[:end]), host, header); route != nil {
+				return route, values
+			}
+		}
+	}
+	// 通配符 剩余路径为空或从新的路径段开始
+	if n.wildcard != nil && (path == "" || path[0] == '/') && len(n.wildcard.routes) > 0 {
+		if route := acceptRoute(n.wildcard.routes, host, header); route != nil {
+			return route, append(params, strings.TrimPrefix(path, "/"))
+		}
+	}
+	return nil, nil
+}
+
+// acceptRoute 返回第一个满足条件的路由 路由已按条件具体程度排序
+func acceptRoute(routes []*Route, host string, header http.Header) *Route {
+	for _, route := range routes {
+		if route.matches(host, header) {
+			return route
+		}
+	}
+	return nil
+}
+
+func commonPrefix(a, b string) int {
+	i := 0
+	for i < len(a) && i < len(b) && a[i] == b[i] {
+		i++
+	}
+	return i
+}
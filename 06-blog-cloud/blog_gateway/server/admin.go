@@ -0,0 +1,88 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"gateway/metrics"
+)
+
+// ConfigStatus 当前配置版本
+type ConfigStatus struct {
+	Version     int64      `json:"version"`  //进程内的配置版本号 每次成功加载加一
+	Checksum    string     `json:"checksum"` //配置文件内容摘要
+	Path        string     `json:"path"`
+	LoadedAt    time.Time  `json:"loaded_at"`
+	LastError   string     `json:"last_error,omitempty"` //最近一次加载失败的原因 成功后清空
+	LastErrorAt *time.Time `json:"last_error_at,omitempty"`
+}
+
+// AdminHandler 本地管理接口
+//
+//	GET  /admin/config    当前配置版本
+//	GET  /admin/routes    当前生效的路由
+//	GET  /admin/upstreams 上游实例的健康状态和熔断状态
+//	POST /admin/reload    重新加载配置文件
+//	GET  /metrics         Prometheus 指标
+func (g *Gateway) AdminHandler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/admin/config", g.onlyGet(func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, g.configStatus())
+	}))
+	mux.HandleFunc("/admin/routes", g.onlyGet(func(w http.ResponseWriter, r *http.Request) {
+		rt := g.current.Load()
+		writeJSON(w, http.StatusOK, map[string]interface{}{"version": rt.version, "routes": rt.router.Routes()})
+	}))
+	mux.HandleFunc("/admin/upstreams", g.onlyGet(func(w http.ResponseWriter, r *http.Request) {
+		rt := g.current.Load()
+		writeJSON(w, http.StatusOK, map[string]interface{}{"version": rt.version, "upstreams": rt.router.UpstreamStatus()})
+	}))
+	mux.Handle("/metrics", metrics.Handler())
+	mux.HandleFunc("/admin/reload", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			writeJSON(w, http.StatusMethodNotAllowed, map[string]interface{}{"code": 405, "message": "Method not allowed"})
+			return
+		}
+		if err := g.Reload(); err != nil {
+			writeJSON(w, http.StatusUnprocessableEntity, map[string]interface{}{"code": 422, "message": err.Error()})
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]interface{}{"code": 200, "message": "ok", "config": g.configStatus()})
+	})
+	return mux
+}
+
+func (g *Gateway) configStatus() ConfigStatus {
+	rt := g.current.Load()
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	status := ConfigStatus{
+		Version:   rt.version,
+		Checksum:  rt.config.Checksum,
+		Path:      g.path,
+		LoadedAt:  rt.loadedAt,
+		LastError: g.lastError,
+	}
+	if g.lastError != "" {
+		at := g.lastErrorAt
+		status.LastErrorAt = &at
+	}
+	return status
+}
+
+func (g *Gateway) onlyGet(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			writeJSON(w, http.StatusMethodNotAllowed, map[string]interface{}{"code": 405, "message": "Method not allowed"})
+			return
+		}
+		next(w, r)
+	}
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
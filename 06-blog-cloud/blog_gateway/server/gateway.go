@@ -0,0 +1,146 @@
+package server
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"gateway/config"
+	"gateway/middleware"
+	"gateway/ratelimit"
+	"gateway/registry"
+	"gateway/router"
+	"gateway/utils"
+)
+
+// runtime 一个配置版本对应的路由、上游和处理器 重新加载时整体替换
+type runtime struct {
+	config   *config.Config
+	router   *router.Router
+	handler  http.Handler
+	version  int64
+	loadedAt time.Time
+	cancel   context.CancelFunc //停止该版本上游的健康检查和服务发现订阅
+}
+
+// Gateway 网关 持有当前生效的配置版本
+type Gateway struct {
+	path    string
+	reg     registry.Registry
+	limiter *middleware.LoginLimiter //登录失败记录跨配置版本保留
+
+	// 限流存储 存储配置不变时跨配置版本保留计数
+	store       ratelimit.Store
+	storeConfig config.RateLimitStoreConfig
+	storeClose  func()
+
+	current atomic.Pointer[runtime]
+
+	mu          sync.Mutex //串行化重新加载
+	version     int64
+	lastError   string
+	lastErrorAt time.Time
+}
+
+// New 使用已加载的配置创建网关 path 为重新加载时读取的配置文件
+func New(path string, cfg *config.Config, reg registry.Registry) (*Gateway, error) {
+	g := &Gateway{
+		path: path,
+		reg:  reg,
+		limiter: middleware.NewLoginLimiter(
+			cfg.Login.MaxFailuresPerUser,
+			cfg.Login.MaxFailuresPerIP,
+			loginWindow(cfg),
+		),
+	}
+	if err := g.apply(cfg); err != nil {
+		return nil, err
+	}
+	return g, nil
+}
+
+// ServeHTTP 使用当前配置版本处理请求
+func (g *Gateway) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	g.current.Load().handler.ServeHTTP(w, r)
+}
+
+// Reload 重新读取配置文件 校验失败或路由冲突时保留当前配置
+func (g *Gateway) Reload() error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	cfg, err := config.LoadConfig(g.path)
+	if err == nil {
+		if cfg.Checksum == g.current.Load().config.Checksum {
+			return nil
+		}
+		err = g.applyLocked(cfg)
+	}
+	if err != nil {
+		g.lastError, g.lastErrorAt = err.Error(), time.Now()
+		log.Printf("[CONFIG] 重新加载配置失败，继续使用版本 %d: %v", g.current.Load().version, err)
+		return err
+	}
+	g.lastError = ""
+	return nil
+}
+
+func (g *Gateway) apply(cfg *config.Config) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.applyLocked(cfg)
+}
+
+// applyLocked 构建新版本并原子替换 旧版本的后台任务在替换后停止
+// 已经在旧版本上处理的请求不受影响
+func (g *Gateway) applyLocked(cfg *config.Config) error {
+	old := g.current.Load()
+	if old != nil && !reflect.DeepEqual(old.config.Etcd, cfg.Etcd) {
+		log.Printf("[CONFIG] Etcd 配置修改需要重启网关才能生效")
+	}
+	if old != nil && old.config.Telemetry != cfg.Telemetry {
+		log.Printf("[CONFIG] Telemetry 配置修改需要重启网关才能生效")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	r, err := router.NewRouter(ctx, cfg, g.reg)
+	if err != nil {
+		cancel()
+		return err
+	}
+
+	g.version++
+	next := &runtime{
+		config:   cfg,
+		router:   r,
+		version:  g.version,
+		loadedAt: time.Now(),
+		cancel:   cancel,
+	}
+	var closeStore func()
+	if g.store == nil || g.storeConfig != cfg.RateLimitStore {
+		closeStore = g.storeClose
+		g.store, g.storeClose = newRateLimitStore(cfg.RateLimitStore)
+		g.storeConfig = cfg.RateLimitStore
+	}
+	next.handler = newHandler(cfg, r, g.limiter, g.store)
+
+	utils.InitJWT(cfg.Jwt.Secret, time.Duration(cfg.Jwt.AccessExpire)*time.Second)
+	g.limiter.SetLimits(cfg.Login.MaxFailuresPerUser, cfg.Login.MaxFailuresPerIP, loginWindow(cfg))
+	g.current.Store(next)
+	if old != nil {
+		old.cancel()
+	}
+	if closeStore != nil {
+		closeStore()
+	}
+	log.Printf("[CONFIG] 配置版本 %d 已生效 checksum: %s", next.version, cfg.Checksum)
+	return nil
+}
+
+func loginWindow(cfg *config.Config) time.Duration {
+	return time.Duration(cfg.Login.FailureWindow) * time.Millisecond
+}
@@ -0,0 +1,101 @@
+package server
+
+import (
+	"net/http"
+
+	"gateway/config"
+	"gateway/metrics"
+	"gateway/middleware"
+	"gateway/ratelimit"
+	"gateway/router"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+// newHandler 根据配置构建请求处理链
+func newHandler(cfg *config.Config, r *router.Router, limiter *middleware.LoginLimiter, store ratelimit.Store) http.Handler {
+	// 初始化中间件
+	jwtMiddleware := middleware.NewJwtMiddleware(cfg.Jwt.ExcludePaths)
+	loginMiddleware := middleware.NewLoginMiddleware(limiter)
+	logMiddleware := middleware.NewLogMiddleware()
+	traceMiddleware := middleware.NewTraceMiddleware()
+	metricsMiddleware := middleware.NewMetricsMiddleware()
+
+	// 自定义处理器
+	handler := http.NewServeMux()
+
+	// 登录请求转发到用户服务校验 由网关签发令牌 配置校验已保证上游存在
+	login := loginMiddleware.MiddlewareFunc()(r.GetProxy(cfg.Login.Upstream))
+	handler.HandleFunc("/v1/user/login", func(w http.ResponseWriter, req *http.Request) {
+		trace.SpanFromContext(req.Context()).SetName(req.Method + " /v1/user/login")
+		metrics.SetRoute(req.Context(), "/v1/user/login", cfg.Login.Upstream)
+		// 登录接口同样按映射规则限流
+		if match, ok := r.Match(req); ok && !checkRateLimit(store, w, req, match.Route) {
+			return
+		}
+		inFlight := metrics.RequestsInFlight.WithLabelValues("/v1/user/login", cfg.Login.Upstream)
+		inFlight.Inc()
+		defer inFlight.Dec()
+		login.ServeHTTP(w, req)
+	})
+
+	// 处理所有其他请求，根据配置文件进行路由匹配和转发
+	handler.HandleFunc("/", func(w http.ResponseWriter, req *http.Request) {
+		// 匹配路由
+		match, ok := r.Match(req)
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"code": 404, "message": "路由不存在"}`))
+			return
+		}
+
+		// 网关 span 以路由规则命名 避免路径参数导致 span 名过多
+		trace.SpanFromContext(req.Context()).SetName(req.Method + " " + match.Route.Pattern)
+		metrics.SetRoute(req.Context(), match.Route.Pattern, match.Route.Target())
+
+		// 限流
+		if !checkRateLimit(store, w, req, match.Route) {
+			return
+		}
+
+		inFlight := metrics.RequestsInFlight.WithLabelValues(match.Route.Pattern, match.Route.Target())
+		inFlight.Inc()
+		defer inFlight.Dec()
+
+		// 聚合路由 由网关请求各部分的上游并合并结果
+		if match.Route.Compose != nil {
+			match.Route.Compose.ServeHTTP(w, req, match.Param, func(name string) http.Handler {
+				if proxy := r.GetProxy(name); proxy != nil {
+					return proxy
+				}
+				return nil
+			})
+			return
+		}
+
+		// 获取对应的代理
+		proxy := r.GetProxy(match.Route.Upstream)
+		if proxy == nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			w.Write([]byte(`{"code": 500, "message": "代理服务不可用"}`))
+			return
+		}
+
+		// 按路由规则改写转发路径
+		if match.Path != req.URL.Path {
+			req.URL.Path = match.Path
+			req.URL.RawPath = ""
+		}
+
+		// 转发请求
+		proxy.ServeHTTP(w, req)
+	})
+
+	// 构建中间件链
+	var finalHandler http.Handler = handler
+	finalHandler = logMiddleware.MiddlewareFunc()(finalHandler)
+	finalHandler = jwtMiddleware.MiddlewareFunc()(finalHandler)
+	finalHandler = metricsMiddleware.MiddlewareFunc()(finalHandler)
+	finalHandler = traceMiddleware.MiddlewareFunc()(finalHandler)
+	return finalHandler
+}
@@ -0,0 +1,64 @@
+package server
+
+import (
+	"gateway/upstream"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	breakerStateDesc = prometheus.NewDesc("gateway_circuit_breaker_state",
+		"Circuit breaker state of each upstream, 1 for the current state.",
+		[]string{"upstream", "state"}, nil)
+	backendsDesc = prometheus.NewDesc("gateway_upstream_backends",
+		"Number of upstream backends by availability.",
+		[]string{"upstream", "state"}, nil)
+)
+
+// breakerStates 熔断器的所有状态 每个状态输出一条 当前状态为1
+var breakerStates = []string{"closed", "open", "half_open"}
+
+// upstreamCollector 抓取时读取当前生效的上游状态 配置重新加载后自动使用新的上游
+type upstreamCollector struct {
+	g *Gateway
+}
+
+// MetricsCollector 返回上游熔断状态和实例可用数的指标收集器
+func (g *Gateway) MetricsCollector() prometheus.Collector {
+	return upstreamCollector{g: g}
+}
+
+// Describe 实现 prometheus.Collector 接口
+func (c upstreamCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- breakerStateDesc
+	ch <- backendsDesc
+}
+
+// Collect 实现 prometheus.Collector 接口
+func (c upstreamCollector) Collect(ch chan<- prometheus.Metric) {
+	for _, status := range c.g.current.Load().router.UpstreamStatus() {
+		for _, state := range breakerStates {
+			var value float64
+			if status.Breaker == state {
+				value = 1
+			}
+			ch <- prometheus.MustNewConstMetric(breakerStateDesc, prometheus.GaugeValue, value, status.Name, state)
+		}
+
+		var available, unavailable float64
+		for _, backend := range status.Backends {
+			if isAvailable(backend) {
+				available++
+			} else {
+				unavailable++
+			}
+		}
+		ch <- prometheus.MustNewConstMetric(backendsDesc, prometheus.GaugeValue, available, status.Name, "available")
+		ch <- prometheus.MustNewConstMetric(backendsDesc, prometheus.GaugeValue, unavailable, status.Name, "unavailable")
+	}
+}
+
+// isAvailable 主动检查健康且没有被被动检查摘除
+func isAvailable(b upstream.BackendStatus) bool {
+	return b.Healthy && b.EjectedUntil == nil
+}
@@ -0,0 +1,47 @@
+package server
+
+import (
+	"context"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+)
+
+// configPollInterval 检查配置文件是否变化的间隔
+const configPollInterval = 2 * time.Second
+
+// Watch 配置文件变化或收到 SIGHUP 时重新加载配置 ctx 结束时停止
+// 通过修改时间和大小判断变化 编辑器先写临时文件再改名的方式同样可以检测到
+func (g *Gateway) Watch(ctx context.Context) {
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	defer signal.Stop(hup)
+
+	ticker := time.NewTicker(configPollInterval)
+	defer ticker.Stop()
+
+	last, _ := os.Stat(g.path)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-hup:
+			log.Printf("[CONFIG] 收到 SIGHUP，重新加载配置")
+			g.Reload()
+		case <-ticker.C:
+			info, err := os.Stat(g.path)
+			if err != nil {
+				// 改名替换过程中文件可能短暂不存在
+				continue
+			}
+			if last != nil && info.ModTime().Equal(last.ModTime()) && info.Size() == last.Size() {
+				continue
+			}
+			last = info
+			log.Printf("[CONFIG] 配置文件 %s 已修改，重新加载配置", g.path)
+			g.Reload()
+		}
+	}
+}
@@ -0,0 +1,48 @@
+package tracing
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+)
+
+// HeaderRequestID 请求ID头 网关生成后转发给上游 上游服务放入日志和 gRPC metadata
+const HeaderRequestID = "X-Request-ID"
+
+// maxRequestIDLen 客户端传入的请求ID最大长度
+const maxRequestIDLen = 64
+
+type requestIDKey struct{}
+
+// NewRequestID 生成请求ID 32位十六进制
+func NewRequestID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// ValidRequestID 客户端传入的请求ID是否可以沿用 只允许字母、数字和 -_. 防止日志注入
+func ValidRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLen {
+		return false
+	}
+	for _, c := range id {
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9', c == '-', c == '_', c == '.':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// WithRequestID 把请求ID放入 ctx
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// RequestID 返回 ctx 中的请求ID 没有时返回空字符串
+func RequestID(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
@@ -0,0 +1,99 @@
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// TracerName 网关创建 span 使用的 tracer 名称
+const TracerName = "gateway"
+
+// Options 链路追踪选项
+type Options struct {
+	Name         string
+	Endpoint     string  // 为空时只生成 trace 不导出
+	Sampler      float64 // 采样率 上游传入的 traceparent 已有采样结果时以上游为准
+	Batcher      string  // otlphttp 或 file
+	OtlpHttpPath string
+	Disabled     bool
+}
+
+// Start 初始化全局 TracerProvider 和 W3C traceparent 传播器 返回的函数用于退出时刷新未导出的 span
+func Start(opts Options) (func(context.Context) error, error) {
+	// 传播器总是设置 关闭追踪时也原样转发客户端的 traceparent
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+	if opts.Disabled {
+		return func(context.Context) error { return nil }, nil
+	}
+
+	// 没有配置导出地址时也使用 SDK 保证网关总能生成 traceparent 传给下游
+	providerOpts := []sdktrace.TracerProviderOption{
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(opts.Sampler))),
+		sdktrace.WithResource(resource.NewSchemaless(attribute.String("service.name", opts.Name))),
+	}
+	if opts.Endpoint != "" {
+		exporter, err := newExporter(opts)
+		if err != nil {
+			return nil, err
+		}
+		providerOpts = append(providerOpts, sdktrace.WithBatcher(exporter))
+	}
+	provider := sdktrace.NewTracerProvider(providerOpts...)
+	otel.SetTracerProvider(provider)
+	return provider.Shutdown, nil
+}
+
+func newExporter(opts Options) (sdktrace.SpanExporter, error) {
+	switch opts.Batcher {
+	case "otlphttp":
+		clientOpts := []otlptracehttp.Option{
+			otlptracehttp.WithEndpoint(opts.Endpoint),
+			otlptracehttp.WithInsecure(),
+		}
+		if opts.OtlpHttpPath != "" {
+			clientOpts = append(clientOpts, otlptracehttp.WithURLPath(opts.OtlpHttpPath))
+		}
+		return otlptracehttp.New(context.Background(), clientOpts...)
+	case "file":
+		f, err := os.OpenFile(opts.Endpoint, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o644)
+		if err != nil {
+			return nil, fmt.Errorf("打开链路追踪文件失败: %w", err)
+		}
+		return stdouttrace.New(stdouttrace.WithWriter(f))
+	default:
+		return nil, fmt.Errorf("不支持的链路追踪导出方式: %s", opts.Batcher)
+	}
+}
+
+// Tracer 返回网关使用的 tracer
+func Tracer() trace.Tracer {
+	return otel.Tracer(TracerName)
+}
+
+// Inject 把 ctx 中的 span 写入请求头的 traceparent
+func Inject(ctx context.Context, header map[string][]string) {
+	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(header))
+}
+
+// Extract 从请求头的 traceparent 中恢复上游的 span
+func Extract(ctx context.Context, header map[string][]string) context.Context {
+	return otel.GetTextMapPropagator().Extract(ctx, propagation.HeaderCarrier(header))
+}
+
+// TraceID 返回 ctx 中的 trace ID 没有时返回空字符串
+func TraceID(ctx context.Context) string {
+	if sc := trace.SpanContextFromContext(ctx); sc.HasTraceID() {
+		return sc.TraceID().String()
+	}
+	return ""
+}
@@ -0,0 +1,121 @@
+package upstream
+
+import (
+	"net/url"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Backend 上游实例 记录健康状态和当前连接数
+type Backend struct {
+	Addr   string
+	URL    *url.URL
+	Weight int
+
+	active atomic.Int64 //正在处理的请求数
+
+	mu           sync.Mutex
+	healthy      bool      //主动健康检查结果
+	checkOK      int       //主动检查连续成功次数
+	checkFail    int       //主动检查连续失败次数
+	fails        int       //被动检查连续失败次数
+	ejectedUntil time.Time //被动检查摘除截止时间
+}
+
+func newBackend(addr string, weight int) (*Backend, error) {
+	u, err := parseTarget(addr)
+	if err != nil {
+		return nil, err
+	}
+	if weight <= 0 {
+		weight = 1
+	}
+	return &Backend{Addr: addr, URL: u, Weight: weight, healthy: true}, nil
+}
+
+// Available 实例是否可以接收请求
+func (b *Backend) Available(now time.Time) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.healthy && !now.Before(b.ejectedUntil)
+}
+
+// Active 正在处理的请求数
+func (b *Backend) Active() int64 {
+	return b.active.Load()
+}
+
+// BackendStatus 实例状态 供管理接口展示
+type BackendStatus struct {
+	Addr         string     `json:"addr"`
+	Weight       int        `json:"weight"`
+	Healthy      bool       `json:"healthy"`       //主动健康检查结果
+	EjectedUntil *time.Time `json:"ejected_until"` //被动检查摘除截止时间 未摘除时为空
+	Active       int64      `json:"active"`
+}
+
+// Status 实例当前状态
+func (b *Backend) Status() BackendStatus {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	status := BackendStatus{Addr: b.Addr, Weight: b.Weight, Healthy: b.healthy, Active: b.active.Load()}
+	if time.Now().Before(b.ejectedUntil) {
+		until := b.ejectedUntil
+		status.EjectedUntil = &until
+	}
+	return status
+}
+
+// markSuccess 请求成功 清空被动检查的失败计数
+func (b *Backend) markSuccess() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.fails = 0
+}
+
+// markFailure 请求失败 连续失败 maxFails 次后摘除 failTimeout 时长
+func (b *Backend) markFailure(maxFails int, failTimeout time.Duration) bool {
+	if maxFails <= 0 {
+		return false
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.fails++
+	if b.fails < maxFails {
+		return false
+	}
+	b.fails = 0
+	b.ejectedUntil = time.Now().Add(failTimeout)
+	return true
+}
+
+// recordCheck 记录一次主动检查结果 返回健康状态是否变化
+func (b *Backend) recordCheck(ok bool, healthyThreshold, unhealthyThreshold int) (bool, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	before := b.healthy
+	if ok {
+		b.checkOK++
+		b.checkFail = 0
+		if !b.healthy && b.checkOK >= healthyThreshold {
+			b.healthy = true
+		}
+	} else {
+		b.checkFail++
+		b.checkOK = 0
+		if b.healthy && b.checkFail >= unhealthyThreshold {
+			b.healthy = false
+		}
+	}
+	return b.healthy, b.healthy != before
+}
+
+// parseTarget 解析 host:port 或完整URL
+func parseTarget(addr string) (*url.URL, error) {
+	if !strings.Contains(addr, "://") {
+		addr = "http://" + addr
+	}
+	return url.Parse(addr)
+}
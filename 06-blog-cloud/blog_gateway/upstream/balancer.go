@@ -0,0 +1,89 @@
+package upstream
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+)
+
+// 负载均衡策略
+const (
+	BalancerRoundRobin = "round_robin"
+	BalancerLeastConn  = "least_conn"
+	BalancerWeighted   = "weighted"
+)
+
+// Balancer 从可用实例中选择一个 candidates 不为空
+type Balancer interface {
+	Pick(candidates []*Backend) *Backend
+}
+
+// NewBalancer 根据策略名创建负载均衡器 为空时使用轮询
+func NewBalancer(strategy string) (Balancer, error) {
+	switch strategy {
+	case "", BalancerRoundRobin:
+		return &roundRobin{}, nil
+	case BalancerLeastConn:
+		return &leastConn{}, nil
+	case BalancerWeighted:
+		return &weighted{current: make(map[*Backend]int)}, nil
+	}
+	return nil, fmt.Errorf("不支持的负载均衡策略 %q", strategy)
+}
+
+// roundRobin 轮询
+type roundRobin struct {
+	next atomic.Uint64
+}
+
+func (b *roundRobin) Pick(candidates []*Backend) *Backend {
+	n := b.next.Add(1) - 1
+	return candidates[n%uint64(len(candidates))]
+}
+
+// leastConn 最少连接 连接数相同时轮询
+type leastConn struct {
+	next atomic.Uint64
+}
+
+func (b *leastConn) Pick(candidates []*Backend) *Backend {
+	offset := int(b.next.Add(1) - 1)
+	var best *Backend
+	for i := range candidates {
+		backend := candidates[(offset+i)%len(candidates)]
+		if best == nil || backend.Active() < best.Active() {
+			best = backend
+		}
+	}
+	return best
+}
+
+// weighted 平滑加权轮询 与 nginx 的算法一致 权重高的实例不会连续集中被选中
+type weighted struct {
+	mu      sync.Mutex
+	current map[*Backend]int
+}
+
+func (b *weighted) Pick(candidates []*Backend) *Backend {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	total := 0
+	var best *Backend
+	for _, backend := range candidates {
+		b.current[backend] += backend.Weight
+		total += backend.Weight
+		if best == nil || b.current[backend] > b.current[best] {
+			best = backend
+		}
+	}
+	b.current[best] -= total
+	// 清理已经下线的实例
+	if len(b.current) > 2*len(candidates) {
+		alive := make(map[*Backend]int, len(candidates))
+		for _, backend := range candidates {
+			alive[backend] = b.current[backend]
+		}
+		b.current = alive
+	}
+	return best
+}
@@ -0,0 +1,103 @@
+package upstream
+
+import (
+	"sync"
+	"time"
+)
+
+// 熔断器状态
+const (
+	breakerClosed = iota
+	breakerOpen
+	breakerHalfOpen
+)
+
+// Breaker 熔断器
+// 连续失败达到阈值后打开 打开期间直接拒绝请求
+// 超过 openTimeout 后进入半开状态 只放行一个探测请求 成功则关闭 失败则重新打开
+type Breaker struct {
+	threshold   int
+	openTimeout time.Duration
+
+	mu       sync.Mutex
+	state    int
+	failures int
+	openedAt time.Time
+	probing  bool
+}
+
+// NewBreaker 创建熔断器 threshold 为0时不熔断
+func NewBreaker(threshold int, openTimeout time.Duration) *Breaker {
+	return &Breaker{threshold: threshold, openTimeout: openTimeout}
+}
+
+// Allow 是否放行请求 拒绝时返回建议的重试等待时间
+func (b *Breaker) Allow() (bool, time.Duration) {
+	if b.threshold <= 0 {
+		return true, 0
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	switch b.state {
+	case breakerOpen:
+		wait := b.openTimeout - time.Since(b.openedAt)
+		if wait > 0 {
+			return false, wait
+		}
+		b.state = breakerHalfOpen
+		b.probing = true
+		return true, 0
+	case breakerHalfOpen:
+		if b.probing {
+			return false, b.openTimeout
+		}
+		b.probing = true
+		return true, 0
+	}
+	return true, 0
+}
+
+// State 熔断器状态 closed、open 或 half_open
+func (b *Breaker) State() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	switch b.state {
+	case breakerOpen:
+		return "open"
+	case breakerHalfOpen:
+		return "half_open"
+	}
+	return "closed"
+}
+
+// Record 记录请求结果
+func (b *Breaker) Record(success bool) {
+	if b.threshold <= 0 {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if success {
+		b.state = breakerClosed
+		b.failures = 0
+		b.probing = false
+		return
+	}
+	b.failures++
+	if b.state == breakerHalfOpen || b.failures >= b.threshold {
+		b.state = breakerOpen
+		b.openedAt = time.Now()
+		b.failures = 0
+		b.probing = false
+	}
+}
+
+// Abandon 放行的请求没有结果（如客户端断开） 半开状态下允许下一个探测请求
+func (b *Breaker) Abandon() {
+	if b.threshold <= 0 {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.probing = false
+}
@@ -0,0 +1,54 @@
+package upstream
+
+import (
+	"context"
+	"log"
+	"net"
+	"net/http"
+	"time"
+)
+
+// runHealthCheck 定期主动检查所有实例 ctx 结束时停止
+func (u *Upstream) runHealthCheck(ctx context.Context) {
+	ticker := time.NewTicker(u.opts.HealthCheck.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			for _, backend := range u.Backends() {
+				ok := u.check(ctx, backend)
+				healthy, changed := backend.recordCheck(ok, u.opts.HealthCheck.HealthyThreshold, u.opts.HealthCheck.UnhealthyThreshold)
+				if changed {
+					log.Printf("[UPSTREAM] %s 实例 %s 健康状态变为 %v", u.name, backend.Addr, healthy)
+				}
+			}
+		}
+	}
+}
+
+// check 配置了 Path 时发送 GET 请求 2xx、3xx 视为健康 否则只检查TCP连接
+func (u *Upstream) check(ctx context.Context, backend *Backend) bool {
+	ctx, cancel := context.WithTimeout(ctx, u.opts.HealthCheck.Timeout)
+	defer cancel()
+	if u.opts.HealthCheck.Path == "" {
+		var d net.Dialer
+		conn, err := d.DialContext(ctx, "tcp", backend.URL.Host)
+		if err != nil {
+			return false
+		}
+		conn.Close()
+		return true
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, backend.URL.String()+u.opts.HealthCheck.Path, nil)
+	if err != nil {
+		return false
+	}
+	resp, err := u.checkClient.Do(req)
+	if err != nil {
+		return false
+	}
+	resp.Body.Close()
+	return resp.StatusCode < http.StatusBadRequest
+}
@@ -0,0 +1,438 @@
+package upstream
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"net"
+	"net/http"
+	"net/http/httputil"
+	"sync"
+	"time"
+
+	"gateway/metrics"
+	"gateway/registry"
+	"gateway/tracing"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// maxRetryBody 可以重试的请求体上限 超过时不重试
+const maxRetryBody = 1 << 20
+
+// errRetryableStatus 上游返回了可以重试的状态码
+var errRetryableStatus = errors.New("retryable upstream status")
+
+// Target 静态配置的实例
+type Target struct {
+	Addr   string
+	Weight int
+}
+
+// HealthCheckOptions 健康检查配置
+type HealthCheckOptions struct {
+	Path               string        //主动检查路径 为空时只检查TCP连接
+	Interval           time.Duration //主动检查间隔 为0时不做主动检查
+	Timeout            time.Duration //单次主动检查超时
+	HealthyThreshold   int           //连续成功多少次恢复
+	UnhealthyThreshold int           //连续失败多少次摘除
+	MaxFails           int           //被动检查 连续请求失败多少次摘除 为0时不做被动检查
+	FailTimeout        time.Duration //被动检查摘除时长
+}
+
+// Options 上游配置
+type Options struct {
+	Targets          []Target
+	Balancer         string
+	Timeout          time.Duration //等待上游响应头的超时时间
+	Retries          int           //幂等请求失败后最多重试次数
+	HealthCheck      HealthCheckOptions
+	BreakerThreshold int           //连续失败多少次熔断 为0时不熔断
+	BreakerTimeout   time.Duration //熔断持续时间
+}
+
+func (o *Options) setDefaults() {
+	if o.Timeout <= 0 {
+		o.Timeout = 3 * time.Second
+	}
+	if o.HealthCheck.Timeout <= 0 {
+		o.HealthCheck.Timeout = time.Second
+	}
+	if o.HealthCheck.HealthyThreshold <= 0 {
+		o.HealthCheck.HealthyThreshold = 2
+	}
+	if o.HealthCheck.UnhealthyThreshold <= 0 {
+		o.HealthCheck.UnhealthyThreshold = 3
+	}
+	if o.HealthCheck.FailTimeout <= 0 {
+		o.HealthCheck.FailTimeout = 10 * time.Second
+	}
+	if o.BreakerTimeout <= 0 {
+		o.BreakerTimeout = 10 * time.Second
+	}
+}
+
+// Upstream 上游服务 负责选择实例、重试、健康检查和熔断
+type Upstream struct {
+	name        string
+	opts        Options
+	balancer    Balancer
+	breaker     *Breaker
+	proxy       *httputil.ReverseProxy
+	checkClient *http.Client
+
+	mu         sync.RWMutex
+	static     []*Backend //静态配置的实例
+	discovered []*Backend //注册中心发现的实例 不为空时优先使用
+}
+
+// New 创建上游服务
+func New(name string, opts Options) (*Upstream, error) {
+	opts.setDefaults()
+	balancer, err := NewBalancer(opts.Balancer)
+	if err != nil {
+		return nil, fmt.Errorf("上游 %s: %w", name, err)
+	}
+	u := &Upstream{
+		name:        name,
+		opts:        opts,
+		balancer:    balancer,
+		breaker:     NewBreaker(opts.BreakerThreshold, opts.BreakerTimeout),
+		checkClient: &http.Client{},
+	}
+	for _, target := range opts.Targets {
+		backend, err := newBackend(target.Addr, target.Weight)
+		if err != nil {
+			return nil, fmt.Errorf("上游 %s 地址 %s 无效: %w", name, target.Addr, err)
+		}
+		u.static = append(u.static, backend)
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.ResponseHeaderTimeout = opts.Timeout
+	transport.DialContext = (&net.Dialer{Timeout: opts.Timeout, KeepAlive: 30 * time.Second}).DialContext
+	u.proxy = &httputil.ReverseProxy{
+		Transport: transport,
+		Rewrite: func(pr *httputil.ProxyRequest) {
+			pr.SetURL(attemptFrom(pr.In.Context()).backend.URL)
+			// 与原来的单地址代理一致 保留客户端请求的 Host
+			pr.Out.Host = pr.In.Host
+			pr.SetXForwarded()
+			// traceparent 指向本次转发尝试的 span
+			tracing.Inject(pr.Out.Context(), pr.Out.Header)
+		},
+		ModifyResponse: func(resp *http.Response) error {
+			a := attemptFrom(resp.Request.Context())
+			a.status = resp.StatusCode
+			if isRetryableStatus(resp.StatusCode) {
+				a.failed = true
+				// 还可以重试时丢弃这次响应
+				if !a.last {
+					return errRetryableStatus
+				}
+			}
+			return nil
+		},
+		ErrorHandler: func(w http.ResponseWriter, r *http.Request, err error) {
+			a := attemptFrom(r.Context())
+			a.failed, a.err = true, err
+			if a.last {
+				u.writeProxyError(w, err)
+			}
+		},
+	}
+	return u, nil
+}
+
+// Start 启动主动健康检查 ctx 结束时停止
+func (u *Upstream) Start(ctx context.Context) {
+	if u.opts.HealthCheck.Interval > 0 {
+		go u.runHealthCheck(ctx)
+	}
+}
+
+// SetInstances 更新注册中心发现的实例 由注册中心回调 已存在的实例保留健康状态
+func (u *Upstream) SetInstances(instances []registry.Instance) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	existing := make(map[string]*Backend, len(u.discovered))
+	for _, backend := range u.discovered {
+		existing[backend.Addr] = backend
+	}
+	discovered := make([]*Backend, 0, len(instances))
+	addrs := make([]string, 0, len(instances))
+	for _, instance := range instances {
+		if backend, ok := existing[instance.Addr]; ok {
+			discovered = append(discovered, backend)
+			addrs = append(addrs, instance.Addr)
+			continue
+		}
+		backend, err := newBackend(instance.Addr, 1)
+		if err != nil {
+			log.Printf("[UPSTREAM] %s 忽略无效实例 %s: %v", u.name, instance.Addr, err)
+			continue
+		}
+		discovered = append(discovered, backend)
+		addrs = append(addrs, instance.Addr)
+	}
+	u.discovered = discovered
+	log.Printf("[UPSTREAM] %s 实例更新: %v", u.name, addrs)
+}
+
+// Status 上游状态 供管理接口展示
+type Status struct {
+	Name     string          `json:"name"`
+	Balancer string          `json:"balancer"`
+	Breaker  string          `json:"breaker"`
+	Source   string          `json:"source"` //static 或 discovery
+	Backends []BackendStatus `json:"backends"`
+}
+
+// Status 上游当前状态
+func (u *Upstream) Status() Status {
+	u.mu.RLock()
+	source := "static"
+	if len(u.discovered) > 0 {
+		source = "discovery"
+	}
+	u.mu.RUnlock()
+	balancer := u.opts.Balancer
+	if balancer == "" {
+		balancer = BalancerRoundRobin
+	}
+	status := Status{Name: u.name, Balancer: balancer, Breaker: u.breaker.State(), Source: source}
+	for _, backend := range u.Backends() {
+		status.Backends = append(status.Backends, backend.Status())
+	}
+	return status
+}
+
+// Backends 当前生效的实例
+func (u *Upstream) Backends() []*Backend {
+	u.mu.RLock()
+	defer u.mu.RUnlock()
+	if len(u.discovered) > 0 {
+		return u.discovered
+	}
+	return u.static
+}
+
+// pick 从可用且本次请求未尝试过的实例中选择一个
+func (u *Upstream) pick(tried map[*Backend]bool) *Backend {
+	candidates := u.candidates(tried)
+	if len(candidates) == 0 {
+		return nil
+	}
+	return u.balancer.Pick(candidates)
+}
+
+// hasCandidate 是否还有可以尝试的实例 不影响负载均衡状态
+func (u *Upstream) hasCandidate(tried map[*Backend]bool) bool {
+	return len(u.candidates(tried)) > 0
+}
+
+func (u *Upstream) candidates(tried map[*Backend]bool) []*Backend {
+	now := time.Now()
+	var candidates []*Backend
+	for _, backend := range u.Backends() {
+		if !tried[backend] && backend.Available(now) {
+			candidates = append(candidates, backend)
+		}
+	}
+	return candidates
+}
+
+// attempt 一次转发尝试的状态 通过请求上下文传给代理回调
+type attempt struct {
+	backend *Backend
+	last    bool //是否为最后一次尝试 最后一次失败时才向客户端写入错误
+	failed  bool
+	err     error
+	status  int //上游返回的状态码 连接失败时为0
+}
+
+type attemptKey struct{}
+
+func attemptFrom(ctx context.Context) *attempt {
+	return ctx.Value(attemptKey{}).(*attempt)
+}
+
+// ServeHTTP 转发请求 幂等请求失败时换一个实例重试
+func (u *Upstream) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if ok, wait := u.breaker.Allow(); !ok {
+		metrics.UpstreamRejectedTotal.WithLabelValues(u.name, "circuit_open").Inc()
+		u.writeUnavailable(w, "circuit_open", "上游服务熔断中，请稍后重试", wait)
+		return
+	}
+	// 熔断器只统计有明确结果的请求
+	var success, failed bool
+	defer func() {
+		switch {
+		case success:
+			u.breaker.Record(true)
+		case failed:
+			u.breaker.Record(false)
+		default:
+			u.breaker.Abandon()
+		}
+	}()
+
+	attempts := 1
+	var body []byte
+	if u.opts.Retries > 0 && isIdempotent(r.Method) {
+		var ok bool
+		if body, ok = bufferBody(r); ok {
+			attempts += u.opts.Retries
+		}
+	}
+
+	tried := make(map[*Backend]bool)
+	for i := 0; i < attempts; i++ {
+		backend := u.pick(tried)
+		if backend == nil {
+			metrics.UpstreamRejectedTotal.WithLabelValues(u.name, "no_available_backend").Inc()
+			u.writeUnavailable(w, "no_available_backend", "没有可用的上游实例", 0)
+			return
+		}
+		tried[backend] = true
+		// 重试次数用完或没有其他可用实例时 这就是最后一次尝试
+		a := &attempt{backend: backend, last: i == attempts-1 || !u.hasCandidate(tried)}
+		if body != nil {
+			r.Body = io.NopCloser(bytes.NewReader(body))
+		}
+
+		// 每次转发尝试一个 span 重试时可以看到每个实例的耗时和结果
+		ctx, span := tracing.Tracer().Start(r.Context(), "proxy "+u.name,
+			trace.WithSpanKind(trace.SpanKindClient),
+			trace.WithAttributes(
+				attribute.String("upstream.name", u.name),
+				attribute.String("server.address", backend.Addr),
+				attribute.Int("upstream.attempt", i+1),
+			),
+		)
+		start := time.Now()
+		backend.active.Add(1)
+		u.proxy.ServeHTTP(w, r.WithContext(context.WithValue(ctx, attemptKey{}, a)))
+		backend.active.Add(-1)
+		endAttemptSpan(span, a)
+		u.observeAttempt(a, time.Since(start))
+
+		if !a.failed {
+			backend.markSuccess()
+			success = true
+			return
+		}
+		// 客户端已断开 不计入失败也不再重试
+		if r.Context().Err() != nil {
+			return
+		}
+		if backend.markFailure(u.opts.HealthCheck.MaxFails, u.opts.HealthCheck.FailTimeout) {
+			log.Printf("[UPSTREAM] %s 实例 %s 连续失败 暂时摘除", u.name, backend.Addr)
+		}
+		if a.last {
+			failed = true
+			return
+		}
+		log.Printf("[UPSTREAM] %s 实例 %s 请求失败: %v，重试", u.name, backend.Addr, a.err)
+	}
+}
+
+func (u *Upstream) observeAttempt(a *attempt, elapsed time.Duration) {
+	result := "success"
+	if a.failed {
+		result = "failure"
+	}
+	metrics.UpstreamRequestsTotal.WithLabelValues(u.name, result).Inc()
+	metrics.UpstreamRequestDuration.WithLabelValues(u.name).Observe(elapsed.Seconds())
+}
+
+func endAttemptSpan(span trace.Span, a *attempt) {
+	if a.status != 0 {
+		span.SetAttributes(attribute.Int("http.response.status_code", a.status))
+	}
+	if a.failed {
+		msg := http.StatusText(a.status)
+		if a.err != nil && a.err != errRetryableStatus {
+			span.RecordError(a.err)
+			msg = a.err.Error()
+		}
+		span.SetStatus(codes.Error, msg)
+	}
+	span.End()
+}
+
+// UnavailableResponse 上游不可用时返回的结构
+type UnavailableResponse struct {
+	Code       int    `json:"code"`
+	Message    string `json:"message"`
+	Upstream   string `json:"upstream"`
+	Reason     string `json:"reason"`
+	RetryAfter int    `json:"retry_after,omitempty"` //建议的重试等待秒数
+}
+
+func (u *Upstream) writeUnavailable(w http.ResponseWriter, reason, message string, retryAfter time.Duration) {
+	resp := UnavailableResponse{
+		Code:     http.StatusServiceUnavailable,
+		Message:  message,
+		Upstream: u.name,
+		Reason:   reason,
+	}
+	if retryAfter > 0 {
+		resp.RetryAfter = int(math.Ceil(retryAfter.Seconds()))
+		w.Header().Set("Retry-After", fmt.Sprint(resp.RetryAfter))
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusServiceUnavailable)
+	json.NewEncoder(w).Encode(resp)
+}
+
+// writeProxyError 转发失败 超时返回504 其他错误返回502
+func (u *Upstream) writeProxyError(w http.ResponseWriter, err error) {
+	status, message := http.StatusBadGateway, "上游服务请求失败"
+	var netErr net.Error
+	if errors.Is(err, context.DeadlineExceeded) || errors.As(err, &netErr) && netErr.Timeout() {
+		status, message = http.StatusGatewayTimeout, "上游服务响应超时"
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]interface{}{"code": status, "message": message, "upstream": u.name})
+}
+
+// isIdempotent 幂等方法才可以安全重试
+func isIdempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+// isRetryableStatus 上游网关类错误 换一个实例可能成功
+func isRetryableStatus(status int) bool {
+	return status == http.StatusBadGateway || status == http.StatusServiceUnavailable || status == http.StatusGatewayTimeout
+}
+
+// bufferBody 读取请求体以便重试时重放 过大时放弃重试
+func bufferBody(r *http.Request) ([]byte, bool) {
+	if r.Body == nil || r.Body == http.NoBody {
+		return nil, true
+	}
+	if r.ContentLength > maxRetryBody {
+		return nil, false
+	}
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxRetryBody+1))
+	if err != nil || len(body) > maxRetryBody {
+		// 已经读出的部分需要拼回去
+		r.Body = io.NopCloser(io.MultiReader(bytes.NewReader(body), r.Body))
+		return nil, false
+	}
+	r.Body.Close()
+	return body, true
+}
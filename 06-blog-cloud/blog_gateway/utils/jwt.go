@@ -2,13 +2,48 @@ package utils
 
 import (
 	"errors"
+	"sync/atomic"
 	"time"
 
 	"github.com/golang-jwt/jwt/v4"
 )
 
-// JWT_SECRET 密钥
-const JWT_SECRET = "moon_zhang"
+// defaultTokenExpire 默认令牌有效期
+const defaultTokenExpire = 24 * time.Hour
+
+// jwtKey 签名密钥和令牌有效期
+type jwtKey struct {
+	secret []byte
+	expire time.Duration
+}
+
+// currentKey 由 InitJWT 根据配置设置 配置重新加载时整体替换
+// 网关是唯一的令牌签发方 上游服务使用同一个密钥校验
+var currentKey atomic.Pointer[jwtKey]
+
+// InitJWT 设置签名密钥和令牌有效期 expire 为0时使用默认值24小时
+func InitJWT(secret string, expire time.Duration) {
+	if expire <= 0 {
+		expire = defaultTokenExpire
+	}
+	currentKey.Store(&jwtKey{secret: []byte(secret), expire: expire})
+}
+
+// TokenExpire 令牌有效期
+func TokenExpire() time.Duration {
+	if key := currentKey.Load(); key != nil {
+		return key.expire
+	}
+	return defaultTokenExpire
+}
+
+func loadKey() (*jwtKey, error) {
+	key := currentKey.Load()
+	if key == nil || len(key.secret) == 0 {
+		return nil, errors.New("jwt secret is not initialized")
+	}
+	return key, nil
+}
 
 type JWTClaims struct {
 	UserID   int64  `json:"user_id"`
@@ -18,14 +53,18 @@ type JWTClaims struct {
 
 // GenerateToken 生成JWT令牌
 func GenerateToken(userID int64, username string) (string, error) {
+	key, err := loadKey()
+	if err != nil {
+		return "", err
+	}
 	// 设置过期时间
-	expirationTime := time.Now().Add(24 * time.Hour)
-	// 设置secret key
+	now := time.Now()
 	claims := JWTClaims{
 		UserID:   userID,
 		Username: username,
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(expirationTime),
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(key.expire)),
 			Subject:   username,
 			// 设置签发人
 			Issuer: "blog",
@@ -34,7 +73,7 @@ func GenerateToken(userID int64, username string) (string, error) {
 	// 创建令牌
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
 	// 签名令牌
-	tokenString, err := token.SignedString([]byte(JWT_SECRET))
+	tokenString, err := token.SignedString(key.secret)
 	if err != nil {
 		return "", err
 	}
@@ -43,10 +82,14 @@ func GenerateToken(userID int64, username string) (string, error) {
 
 // ParseToken 解析JWT令牌
 func ParseToken(tokenString string) (*JWTClaims, error) {
-	// 解析令牌
+	key, err := loadKey()
+	if err != nil {
+		return nil, err
+	}
+	// 解析令牌 只接受HS256 防止算法替换
 	token, err := jwt.ParseWithClaims(tokenString, &JWTClaims{}, func(token *jwt.Token) (interface{}, error) {
-		return []byte(JWT_SECRET), nil
-	})
+		return key.secret, nil
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}))
 	if err != nil {
 		return nil, err
 	}
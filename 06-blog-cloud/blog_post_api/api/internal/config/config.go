@@ -4,11 +4,17 @@
 package config
 
 import (
+	"github.com/zeromicro/go-zero/core/discov"
 	"github.com/zeromicro/go-zero/rest"
 	"github.com/zeromicro/go-zero/zrpc"
 )
 
 type Config struct {
 	rest.RestConf
+	Auth struct {
+		AccessSecret string
+	}
 	PostRpc zrpc.RpcClientConf
+	// Registry 注册到 etcd 供网关发现 不配置时不注册
+	Registry discov.EtcdConf `json:",optional"`
 }
@@ -9,6 +9,8 @@ import (
 	"06-blog-cloud/blog_post_api/api/internal/logic"
 	"06-blog-cloud/blog_post_api/api/internal/svc"
 	"06-blog-cloud/blog_post_api/api/internal/types"
+	"blog-common/errorx"
+
 	"github.com/zeromicro/go-zero/rest/httpx"
 )
 
@@ -16,7 +18,7 @@ func GetPostByIdHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		var req types.PathId
 		if err := httpx.Parse(r, &req); err != nil {
-			httpx.ErrorCtx(r.Context(), w, err)
+			httpx.ErrorCtx(r.Context(), w, errorx.NewParamError(err))
 			return
 		}
 
@@ -8,13 +8,23 @@ import (
 
 	"06-blog-cloud/blog_post_api/api/internal/logic"
 	"06-blog-cloud/blog_post_api/api/internal/svc"
+	"06-blog-cloud/blog_post_api/api/internal/types"
+	"blog-common/errorx"
+
 	"github.com/zeromicro/go-zero/rest/httpx"
 )
 
+// 分页查询文章 按 next_cursor 翻页
 func listHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
+		var req types.ListDto
+		if err := httpx.Parse(r, &req); err != nil {
+			httpx.ErrorCtx(r.Context(), w, errorx.NewParamError(err))
+			return
+		}
+
 		l := logic.NewListLogic(r.Context(), svcCtx)
-		resp, err := l.List()
+		resp, err := l.List(&req)
 		if err != nil {
 			httpx.ErrorCtx(r.Context(), w, err)
 		} else {
@@ -25,14 +25,25 @@ func RegisterHandlers(server *rest.Server, serverCtx *svc.ServiceContext) {
 				Path:    "/list",
 				Handler: listHandler(serverCtx),
 			},
-			{
-				Method:  http.MethodPost,
-				Path:    "/save",
-				Handler: saveHandler(serverCtx),
-			},
 		},
 		rest.WithPrefix("/v1/post"),
 		rest.WithTimeout(3000*time.Millisecond),
 		rest.WithMaxBytes(1048576),
 	)
+
+	server.AddRoutes(
+		rest.WithMiddlewares(
+			[]rest.Middleware{serverCtx.AuthInterceptor},
+			[]rest.Route{
+				{
+					Method:  http.MethodPost,
+					Path:    "/save",
+					Handler: saveHandler(serverCtx),
+				},
+			}...,
+		),
+		rest.WithPrefix("/v1/post"),
+		rest.WithTimeout(3000*time.Millisecond),
+		rest.WithMaxBytes(1048576),
+	)
 }
@@ -5,10 +5,10 @@ package logic
 
 import (
 	"context"
-	"strconv"
 
 	"06-blog-cloud/blog_post_api/api/internal/svc"
 	"06-blog-cloud/blog_post_api/api/internal/types"
+	"blog-common/errorx"
 	"blog-post-service/rpc/types/post"
 
 	"github.com/zeromicro/go-zero/core/logx"
@@ -35,25 +35,11 @@ func (l *GetPostByIdLogic) GetPostById(req *types.PathId) (resp *types.PostVo, e
 		Id: int64(req.Id), // 将 int 转换为 int64
 	}
 
-	// 尝试调用 RPC 服务获取文章详情
+	// 调用 RPC 服务获取文章详情
 	postDto, err := l.svcCtx.PostRpc.GetPost(l.ctx, rpcReq)
 	if err != nil {
-		l.Logger.Error("获取文章详情失败:", err)
-		// RPC服务连接失败时，返回模拟数据以测试API
-		l.Logger.Info("RPC服务不可用，返回模拟数据")
-
-		// 根据ID返回对应的模拟文章数据
-		resp = &types.PostVo{
-			Id:        req.Id,
-			Title:     "博客文章 #" + strconv.Itoa(req.Id),
-			Content:   "这是ID为" + strconv.Itoa(req.Id) + "的博客文章详细内容。这是一篇关于Go语言和微服务开发的技术文章，介绍了如何构建高性能的后端服务。",
-			Summary:   "这是ID为" + strconv.Itoa(req.Id) + "的博客文章摘要。",
-			Cover:     "https://example.com/cover" + strconv.Itoa(req.Id) + ".jpg",
-			ViewCount: 100 + req.Id*10,
-			LikeCount: 10 + req.Id,
-			UserID:    1001,
-		}
-		return resp, nil
+		l.Logger.Errorf("获取文章详情失败, ID: %d, err: %v", req.Id, err)
+		return nil, errorx.FromError(err)
 	}
 
 	// 将 RPC 服务返回的 PostDto 转换为 API 层的 PostVo
@@ -66,6 +52,7 @@ func (l *GetPostByIdLogic) GetPostById(req *types.PathId) (resp *types.PostVo, e
 		ViewCount: int(postDto.ViewCount),
 		LikeCount: int(postDto.LikeCount),
 		UserID:    postDto.UserID,
+		CreatedAt: postDto.CreatedAt,
 	}
 
 	l.Logger.Info("获取文章详情成功，ID：", req.Id)
@@ -8,6 +8,7 @@ import (
 
 	"06-blog-cloud/blog_post_api/api/internal/svc"
 	"06-blog-cloud/blog_post_api/api/internal/types"
+	"blog-common/errorx"
 	"blog-post-service/rpc/types/post"
 
 	"github.com/zeromicro/go-zero/core/logx"
@@ -27,44 +28,48 @@ func NewListLogic(ctx context.Context, svcCtx *svc.ServiceContext) *ListLogic {
 	}
 }
 
-func (l *ListLogic) List() (resp []types.PostVo, err error) {
-	// 尝试调用RPC服务获取文章列表
-	postListResp, err := l.svcCtx.PostRpc.GetPostsByConditions(l.ctx, &post.PostDtoConditions{})
-	if err != nil {
-		l.Logger.Error("获取文章列表失败:", err)
-		// RPC服务连接失败时，返回模拟数据以测试API
-		l.Logger.Info("RPC服务不可用，返回模拟数据")
+// sortFields sort_by 参数对应的排序字段
+var sortFields = map[string]post.PostSortField{
+	"":           post.PostSortField_SORT_CREATED_AT,
+	"created_at": post.PostSortField_SORT_CREATED_AT,
+	"view_count": post.PostSortField_SORT_VIEW_COUNT,
+	"like_count": post.PostSortField_SORT_LIKE_COUNT,
+}
 
-		// 返回模拟数据
-		resp = []types.PostVo{
-			{
-				Id:        1,
-				Title:     "第一篇博客文章",
-				Content:   "这是第一篇博客文章的详细内容...",
-				Summary:   "这是第一篇博客文章的摘要",
-				Cover:     "https://example.com/cover1.jpg",
-				ViewCount: 100,
-				LikeCount: 10,
-				UserID:    1001,
-			},
-			{
-				Id:        2,
-				Title:     "第二篇博客文章",
-				Content:   "这是第二篇博客文章的详细内容...",
-				Summary:   "这是第二篇博客文章的摘要",
-				Cover:     "https://example.com/cover2.jpg",
-				ViewCount: 50,
-				LikeCount: 5,
-				UserID:    1001,
-			},
-		}
-		return resp, nil
+// List 分页查询文章 第一页同时返回总数
+func (l *ListLogic) List(req *types.ListDto) (resp *types.ListVo, err error) {
+	sortBy, ok := sortFields[req.SortBy]
+	if !ok {
+		return nil, errorx.New(errorx.CodeInvalidParam, "无效的排序字段")
+	}
+
+	// 调用RPC服务获取文章列表
+	postListResp, err := l.svcCtx.PostRpc.GetPostsByConditions(l.ctx, &post.PostDtoConditions{
+		Title:       req.Title,
+		Content:     req.Content,
+		UserID:      req.UserID,
+		PageSize:    int32(req.PageSize),
+		Cursor:      req.Cursor,
+		SortBy:      sortBy,
+		Asc:         req.Order == "asc",
+		CreatedFrom: req.CreatedFrom,
+		CreatedTo:   req.CreatedTo,
+		SummaryOnly: req.SummaryOnly,
+		WithTotal:   req.Cursor == "",
+	})
+	if err != nil {
+		l.Logger.Errorf("获取文章列表失败: %v", err)
+		return nil, errorx.FromError(err)
 	}
 
 	// 将RPC返回的数据转换为API需要的格式
-	resp = make([]types.PostVo, len(postListResp.Posts))
+	resp = &types.ListVo{
+		Posts:      make([]types.PostVo, len(postListResp.Posts)),
+		NextCursor: postListResp.NextCursor,
+		Total:      postListResp.Total,
+	}
 	for i, postItem := range postListResp.Posts {
-		resp[i] = types.PostVo{
+		resp.Posts[i] = types.PostVo{
 			Id:        int(postItem.ID),
 			Title:     postItem.Title,
 			Content:   postItem.Content,
@@ -73,9 +78,10 @@ func (l *ListLogic) List() (resp []types.PostVo, err error) {
 			ViewCount: int(postItem.ViewCount),
 			LikeCount: int(postItem.LikeCount),
 			UserID:    postItem.UserID,
+			CreatedAt: postItem.CreatedAt,
 		}
 	}
 
-	l.Logger.Info("获取文章列表成功，共", len(resp), "篇文章")
+	l.Logger.Info("获取文章列表成功，本页", len(resp.Posts), "篇文章")
 	return resp, nil
 }
@@ -8,6 +8,7 @@ import (
 
 	"06-blog-cloud/blog_post_api/api/internal/svc"
 	"06-blog-cloud/blog_post_api/api/internal/types"
+	"blog-common/errorx"
 	"blog-post-service/rpc/types/post"
 
 	"github.com/zeromicro/go-zero/core/logx"
@@ -27,55 +28,41 @@ func NewSaveLogic(ctx context.Context, svcCtx *svc.ServiceContext) *SaveLogic {
 	}
 }
 
-func (l *SaveLogic) Save(req *types.PostDto) (resp *types.MsgVo, err error) {
+// Save 新增或更新文章 有 ID 时更新 RPC 失败时按 gRPC 状态码返回错误
+// 作者由 PostService 根据 metadata 中的令牌确定 更新他人的文章需要管理员角色
+func (l *SaveLogic) Save(req *types.PostDto) (resp *types.SaveVo, err error) {
 	// 验证请求参数
-	if req == nil {
-		return &types.MsgVo{Msg: "请求参数不能为空"}, nil
-	}
 	if req.Title == "" {
-		return &types.MsgVo{Msg: "文章标题不能为空"}, nil
+		return nil, errorx.New(errorx.CodeInvalidParam, "文章标题不能为空")
 	}
 	if req.Content == "" {
-		return &types.MsgVo{Msg: "文章内容不能为空"}, nil
-	}
-	if req.UserID <= 0 {
-		return &types.MsgVo{Msg: "用户ID必须大于0"}, nil
+		return nil, errorx.New(errorx.CodeInvalidParam, "文章内容不能为空")
 	}
 
 	// 创建 RPC 调用所需的 PostDto 对象
 	rpcPostDto := &post.PostDto{
-		ID:        int64(req.Id),
-		Title:     req.Title,
-		Content:   req.Content,
-		Summary:   req.Summary,
-		Cover:     req.Cover,
-		ViewCount: int64(req.ViewCount),
-		LikeCount: int64(req.LikeCount),
-		UserID:    req.UserID,
+		ID:      int64(req.Id),
+		Title:   req.Title,
+		Content: req.Content,
+		Summary: req.Summary,
+		Cover:   req.Cover,
 	}
 
 	// 根据是否有 ID 判断是新增还是更新
 	if req.Id > 0 {
-		// 更新文章
-		_, err = l.svcCtx.PostRpc.UpdatePost(l.ctx, rpcPostDto)
-		if err != nil {
-			l.Logger.Error("更新文章失败:", err)
-			// RPC服务不可用时，模拟更新成功
-			l.Logger.Info("RPC服务不可用，模拟更新文章成功")
-			return &types.MsgVo{Msg: "更新文章成功"}, nil
+		if _, err = l.svcCtx.PostRpc.UpdatePost(l.ctx, rpcPostDto); err != nil {
+			l.Logger.Errorf("更新文章失败, ID: %d, err: %v", req.Id, err)
+			return nil, errorx.FromError(err)
 		}
 		l.Logger.Info("更新文章成功, ID:", req.Id)
-		return &types.MsgVo{Msg: "更新文章成功"}, nil
-	} else {
-		// 新增文章
-		_, err = l.svcCtx.PostRpc.CreatePost(l.ctx, rpcPostDto)
-		if err != nil {
-			l.Logger.Error("新增文章失败:", err)
-			// RPC服务不可用时，模拟新增成功
-			l.Logger.Info("RPC服务不可用，模拟新增文章成功")
-			return &types.MsgVo{Msg: "新增文章成功"}, nil
-		}
-		l.Logger.Info("新增文章成功")
-		return &types.MsgVo{Msg: "新增文章成功"}, nil
+		return &types.SaveVo{Id: int64(req.Id), Msg: "更新文章成功"}, nil
+	}
+
+	postId, err := l.svcCtx.PostRpc.CreatePost(l.ctx, rpcPostDto)
+	if err != nil {
+		l.Logger.Errorf("新增文章失败: %v", err)
+		return nil, errorx.FromError(err)
 	}
+	l.Logger.Info("新增文章成功, ID:", postId.Id)
+	return &types.SaveVo{Id: postId.Id, Msg: "新增文章成功"}, nil
 }
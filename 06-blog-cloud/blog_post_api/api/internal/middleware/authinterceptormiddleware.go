@@ -0,0 +1,49 @@
+// Code scaffolded by goctl. Safe to edit.
+// goctl 1.9.2
+
+package middleware
+
+import (
+	"net/http"
+	"strings"
+
+	"blog-common/auth"
+	"blog-common/errorx"
+
+	"github.com/zeromicro/go-zero/core/logx"
+	"github.com/zeromicro/go-zero/rest/httpx"
+)
+
+// AuthInterceptorMiddleware 校验网关签发的令牌 通过后把调用方放入 ctx
+// 网关已经校验过令牌 这里再次校验是为了防止绕过网关直接访问
+type AuthInterceptorMiddleware struct {
+	secret string
+}
+
+func NewAuthInterceptorMiddleware(secret string) *AuthInterceptorMiddleware {
+	return &AuthInterceptorMiddleware{secret: secret}
+}
+
+func (m *AuthInterceptorMiddleware) Handle(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		tokenString, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || tokenString == "" {
+			httpx.ErrorCtx(r.Context(), w, errorx.New(errorx.CodeUnauthorized, "缺少令牌"))
+			return
+		}
+
+		claims, err := auth.ParseToken(m.secret, tokenString)
+		if err != nil {
+			logx.WithContext(r.Context()).Infof("令牌校验失败: %v", err)
+			httpx.ErrorCtx(r.Context(), w, errorx.New(errorx.CodeUnauthorized, "无效的令牌"))
+			return
+		}
+
+		ctx := auth.WithCaller(r.Context(), &auth.Caller{
+			UserID:   claims.UserID,
+			Username: claims.Username,
+			Token:    tokenString,
+		})
+		next(w, r.WithContext(ctx))
+	}
+}
@@ -5,23 +5,33 @@ package svc
 
 import (
 	"06-blog-cloud/blog_post_api/api/internal/config"
+	"06-blog-cloud/blog_post_api/api/internal/middleware"
+	"blog-common/auth"
+	"blog-common/tracing"
 	"blog-post-service/rpc/postservice"
 
+	"github.com/zeromicro/go-zero/rest"
 	"github.com/zeromicro/go-zero/zrpc"
 )
 
 type ServiceContext struct {
-	Config  config.Config
-	PostRpc postservice.PostService
+	Config          config.Config
+	AuthInterceptor rest.Middleware
+	PostRpc         postservice.PostService
 }
 
 func NewServiceContext(c config.Config) *ServiceContext {
-	client, err := zrpc.NewClient(c.PostRpc)
+	// 请求ID和调用方的令牌通过 gRPC metadata 传给 PostService
+	client, err := zrpc.NewClient(c.PostRpc,
+		zrpc.WithUnaryClientInterceptor(tracing.UnaryClientInterceptor),
+		zrpc.WithUnaryClientInterceptor(auth.UnaryClientInterceptor),
+	)
 	if err != nil {
 		panic(err)
 	}
 	return &ServiceContext{
-		Config:  c,
-		PostRpc: postservice.NewPostService(client),
+		Config:          c,
+		AuthInterceptor: middleware.NewAuthInterceptorMiddleware(c.Auth.AccessSecret).Handle,
+		PostRpc:         postservice.NewPostService(client),
 	}
 }
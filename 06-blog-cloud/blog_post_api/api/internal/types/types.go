@@ -3,15 +3,38 @@
 
 package types
 
-type MsgVo struct {
-	Msg string `json:"msg"`
+// 文章列表查询参数 为空的条件不参与过滤
+type ListDto struct {
+	// 标题和内容按包含匹配
+	Title   string `form:"title,optional"`
+	Content string `form:"content,optional"`
+	UserID  int64  `form:"user_id,optional"`
+	// 每页条数 默认 10 最大 100
+	PageSize int `form:"page_size,optional,range=[0:100]"`
+	// 上一页返回的 next_cursor 为空时从第一页开始
+	Cursor string `form:"cursor,optional"`
+	// 排序字段和方向 默认按创建时间降序
+	SortBy string `form:"sort_by,optional,options=created_at|view_count|like_count"`
+	Order  string `form:"order,optional,options=asc|desc"`
+	// 创建时间范围 Unix 秒 包含 created_from 不包含 created_to
+	CreatedFrom int64 `form:"created_from,optional"`
+	CreatedTo   int64 `form:"created_to,optional"`
+	// 为 true 时只返回摘要 不返回内容
+	SummaryOnly bool `form:"summary_only,optional"`
+}
+
+// 文章列表 total 只在第一页返回
+type ListVo struct {
+	Posts      []PostVo `json:"posts"`
+	NextCursor string   `json:"next_cursor"`
+	Total      int64    `json:"total,omitempty"`
 }
 
 type PathId struct {
 	Id int `path:"id"`
 }
 
-// 文章数据传输对象
+// 保存文章 作者为当前登录用户 阅读量和点赞量由服务维护
 type PostDto struct {
 	Id      int    `json:"id,omitempty"`
 	Title   string `json:"title"`
@@ -19,20 +42,24 @@ type PostDto struct {
 	Summary string `json:"summary"`
 	//非必填，默认值为空字符串
 	Cover string `json:"cover,omitempty"`
-	//非必填，默认值为0
-	ViewCount int `json:"view_count,omitempty"`
-	//非必填，默认值为0
-	LikeCount int   `json:"like_count,omitempty"`
-	UserID    int64 `json:"user_id"`
 }
 
 type PostVo struct {
-	Id        int    `json:"id"`
-	Title     string `json:"title"`
-	Content   string `json:"content"`
+	Id    int    `json:"id"`
+	Title string `json:"title"`
+	// 列表只返回摘要时为空
+	Content   string `json:"content,omitempty"`
 	Summary   string `json:"summary"`
 	Cover     string `json:"cover"`
 	ViewCount int    `json:"view_count"`
 	LikeCount int    `json:"like_count"`
 	UserID    int64  `json:"user_id"`
+	// 创建时间 Unix 秒
+	CreatedAt int64 `json:"created_at"`
+}
+
+// 保存结果 新增时返回新文章的ID
+type SaveVo struct {
+	Id  int64  `json:"id"`
+	Msg string `json:"msg"`
 }
@@ -10,9 +10,15 @@ import (
 	"06-blog-cloud/blog_post_api/api/internal/config"
 	"06-blog-cloud/blog_post_api/api/internal/handler"
 	"06-blog-cloud/blog_post_api/api/internal/svc"
+	"blog-common/errorx"
+	"blog-common/tracing"
 
 	"github.com/zeromicro/go-zero/core/conf"
+	"github.com/zeromicro/go-zero/core/discov"
+	"github.com/zeromicro/go-zero/core/logx"
+	"github.com/zeromicro/go-zero/core/netx"
 	"github.com/zeromicro/go-zero/rest"
+	"github.com/zeromicro/go-zero/rest/httpx"
 )
 
 var configFile = flag.String("f", "etc/post.yaml", "the config file")
@@ -25,10 +31,24 @@ func main() {
 
 	server := rest.MustNewServer(c.RestConf)
 	defer server.Stop()
+	// 请求ID写入 logx 上下文 traceparent 由 go-zero 根据 Telemetry 配置处理
+	server.Use(tracing.RequestIDMiddleware)
+	// 错误按业务错误码返回对应的 HTTP 状态码 RPC 错误按 gRPC 状态码映射
+	httpx.SetErrorHandlerCtx(errorx.ErrorHandler)
 
 	ctx := svc.NewServiceContext(c)
 	handler.RegisterHandlers(server, ctx)
 
+	// 注册到 etcd 键为 Key/租约ID 与 zrpc 服务的注册方式一致
+	if len(c.Registry.Hosts) > 0 {
+		addr := fmt.Sprintf("%s:%d", netx.InternalIp(), c.Port)
+		pub := discov.NewPublisher(c.Registry.Hosts, c.Registry.Key, addr)
+		if err := pub.KeepAlive(); err != nil {
+			logx.Must(err)
+		}
+		defer pub.Stop()
+	}
+
 	fmt.Printf("Starting server at %s:%d...\n", c.Host, c.Port)
 	server.Start()
 }
@@ -1,7 +1,18 @@
 package config
 
-import "github.com/zeromicro/go-zero/zrpc"
+import (
+	"blog-common/database"
+
+	"github.com/zeromicro/go-zero/zrpc"
+)
 
 type Config struct {
 	zrpc.RpcServerConf
+	MySQL database.Config
+	// 令牌由网关签发 AccessSecret 必须与网关 Jwt.Secret 一致
+	Auth struct {
+		AccessSecret string
+	}
+	// 查询调用方的角色 判断是否为管理员
+	UserRpc zrpc.RpcClientConf
 }
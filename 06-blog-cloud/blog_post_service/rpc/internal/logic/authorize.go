@@ -0,0 +1,35 @@
+package logic
+
+import (
+	"context"
+
+	"blog-common/auth"
+	"blog-common/errorx"
+	"blog-post-service/rpc/internal/svc"
+	"blog-post-service/rpc/models"
+	"blog_user_service/rpc/types/user"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+// authorize 只有作者和管理员可以修改或删除文章
+// 令牌中没有角色 不是作者时以 UserService 中的角色为准
+func authorize(ctx context.Context, svcCtx *svc.ServiceContext, p *models.Post) error {
+	caller, ok := auth.CallerFrom(ctx)
+	if !ok {
+		return errorx.New(errorx.CodeUnauthorized, "未登录")
+	}
+	if caller.UserID == p.UserID {
+		return nil
+	}
+	userInfo, err := svcCtx.UserRpc.GetUserById(ctx, &user.IdDto{Id: caller.UserID})
+	if err != nil {
+		logx.WithContext(ctx).Errorf("RPC GetUserById failed: %v", err)
+		return errorx.FromError(err)
+	}
+	if userInfo.Role != auth.RoleAdmin {
+		logx.WithContext(ctx).Infof("User %d tried to modify post %d of user %d", caller.UserID, p.ID, p.UserID)
+		return errorx.New(errorx.CodeForbidden, "只能修改自己的文章")
+	}
+	return nil
+}
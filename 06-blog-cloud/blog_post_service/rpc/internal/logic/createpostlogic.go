@@ -1,7 +1,8 @@
 package logic
 
 import (
-	"blog-post-service/rpc/inits"
+	"blog-common/auth"
+	"blog-common/errorx"
 	"blog-post-service/rpc/internal/svc"
 	"blog-post-service/rpc/models"
 	"blog-post-service/rpc/types/post"
@@ -24,26 +25,37 @@ func NewCreatePostLogic(ctx context.Context, svcCtx *svc.ServiceContext) *Create
 	}
 }
 
-// 新增文章
-func (l *CreatePostLogic) CreatePost(in *post.PostDto) (*post.IsSuccess, error) {
+// 新增文章 返回新文章的ID 作者为调用方 阅读量和点赞量从 0 开始
+func (l *CreatePostLogic) CreatePost(in *post.PostDto) (*post.PostId, error) {
+	caller, ok := auth.CallerFrom(l.ctx)
+	if !ok {
+		return nil, errorx.New(errorx.CodeUnauthorized, "未登录")
+	}
+	if in.Title == "" {
+		return nil, errorx.New(errorx.CodeInvalidParam, "文章标题不能为空")
+	}
+	if in.Content == "" {
+		return nil, errorx.New(errorx.CodeInvalidParam, "文章内容不能为空")
+	}
+	if in.UserID != 0 && in.UserID != caller.UserID {
+		return nil, errorx.New(errorx.CodeForbidden, "不能以其他用户的身份发布文章")
+	}
+
 	// 将PostDto转换为Post模型
 	postModel := &models.Post{
-		Title:     in.Title,
-		Content:   in.Content,
-		Summary:   in.Summary,
-		Cover:     in.Cover,
-		UserID:    in.UserID,
-		ViewCount: int(in.ViewCount),
-		LikeCount: int(in.LikeCount),
+		Title:   in.Title,
+		Content: in.Content,
+		Summary: in.Summary,
+		Cover:   in.Cover,
+		UserID:  caller.UserID,
 	}
 
 	// 使用GORM保存到数据库
-	result := inits.MysqlDb.Create(postModel)
-	if result.Error != nil {
-		l.Error("创建文章失败：", result.Error)
-		return &post.IsSuccess{Success: false}, result.Error
+	if err := l.svcCtx.PostRepo.Create(l.ctx, postModel); err != nil {
+		l.Error("创建文章失败：", err)
+		return nil, errorx.New(errorx.CodeInternal, "创建文章失败")
 	}
 
 	l.Info("创建文章成功，ID：", postModel.ID)
-	return &post.IsSuccess{Success: true}, nil
+	return &post.PostId{Id: postModel.ID}, nil
 }
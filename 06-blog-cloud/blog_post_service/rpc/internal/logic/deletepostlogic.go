@@ -2,13 +2,14 @@ package logic
 
 import (
 	"context"
+	"errors"
 
-	"blog-post-service/rpc/inits"
+	"blog-common/errorx"
 	"blog-post-service/rpc/internal/svc"
-	"blog-post-service/rpc/models"
 	"blog-post-service/rpc/types/post"
 
 	"github.com/zeromicro/go-zero/core/logx"
+	"gorm.io/gorm"
 )
 
 type DeletePostLogic struct {
@@ -25,27 +26,30 @@ func NewDeletePostLogic(ctx context.Context, svcCtx *svc.ServiceContext) *Delete
 	}
 }
 
-// 删除文章
+// 删除文章 只有作者和管理员可以删除
 func (l *DeletePostLogic) DeletePost(in *post.PostId) (*post.IsSuccess, error) {
 	// 检查文章是否存在
-	var existingPost models.Post
-	result := inits.MysqlDb.First(&existingPost, in.Id)
-	if result.Error != nil {
-		l.Error("文章不存在或查询失败：", result.Error)
-		return &post.IsSuccess{Success: false}, result.Error
+	existingPost, err := l.svcCtx.PostRepo.FindByID(l.ctx, in.Id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, errorx.Newf(errorx.CodePostNotFound, "文章 %d 不存在", in.Id)
+	}
+	if err != nil {
+		l.Error("查询文章失败：", err)
+		return nil, errorx.New(errorx.CodeInternal, "删除文章失败")
+	}
+	if err := authorize(l.ctx, l.svcCtx, existingPost); err != nil {
+		return nil, err
 	}
 
 	// 执行删除操作（软删除，因为模型中定义了DeletedAt字段）
-	result = inits.MysqlDb.Delete(&existingPost)
-	if result.Error != nil {
-		l.Error("删除文章失败：", result.Error)
-		return &post.IsSuccess{Success: false}, result.Error
+	// 查询和删除之间文章可能已被删除
+	err = l.svcCtx.PostRepo.Delete(l.ctx, in.Id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, errorx.Newf(errorx.CodePostNotFound, "文章 %d 不存在", in.Id)
 	}
-
-	// 检查是否真的有行被删除
-	if result.RowsAffected == 0 {
-		l.Errorf("未找到需要删除的文章，ID：", in.Id)
-		return &post.IsSuccess{Success: false}, nil
+	if err != nil {
+		l.Error("删除文章失败：", err)
+		return nil, errorx.New(errorx.CodeInternal, "删除文章失败")
 	}
 
 	l.Info("删除文章成功，ID：", in.Id)
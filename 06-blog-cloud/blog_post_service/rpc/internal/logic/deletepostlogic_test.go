@@ -0,0 +1,49 @@
+package logic
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"blog-common/errorx"
+	"blog-post-service/rpc/models"
+	"blog-post-service/rpc/types/post"
+
+	"gorm.io/gorm"
+)
+
+func TestDeletePost(t *testing.T) {
+	tests := []struct {
+		name     string
+		caller   int64
+		missing  bool
+		wantCode int
+	}{
+		{name: "author", caller: authorID},
+		{name: "admin", caller: adminID},
+		{name: "other user", caller: otherID, wantCode: errorx.CodeForbidden},
+		{name: "not logged in", wantCode: errorx.CodeUnauthorized},
+		{name: "post not found", caller: authorID, missing: true, wantCode: errorx.CodePostNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svcCtx := newTestServiceContext()
+			id := createPost(t, svcCtx, models.Post{Title: "title", Content: "content", UserID: authorID})
+			target := id
+			if tt.missing {
+				target = id + 1
+			}
+
+			_, err := NewDeletePostLogic(withCaller(tt.caller), svcCtx).DeletePost(&post.PostId{Id: target})
+			if code := errorCode(err); code != tt.wantCode {
+				t.Fatalf("code = %d (%v), want %d", code, err, tt.wantCode)
+			}
+
+			_, err = svcCtx.PostRepo.FindByID(context.Background(), id)
+			deleted := errors.Is(err, gorm.ErrRecordNotFound)
+			if deleted != (tt.wantCode == errorx.CodeOK) {
+				t.Errorf("deleted = %v (%v)", deleted, err)
+			}
+		})
+	}
+}
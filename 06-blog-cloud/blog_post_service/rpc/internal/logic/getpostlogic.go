@@ -2,13 +2,14 @@ package logic
 
 import (
 	"context"
+	"errors"
 
-	"blog-post-service/rpc/inits"
+	"blog-common/errorx"
 	"blog-post-service/rpc/internal/svc"
-	"blog-post-service/rpc/models"
 	"blog-post-service/rpc/types/post"
 
 	"github.com/zeromicro/go-zero/core/logx"
+	"gorm.io/gorm"
 )
 
 type GetPostLogic struct {
@@ -27,14 +28,14 @@ func NewGetPostLogic(ctx context.Context, svcCtx *svc.ServiceContext) *GetPostLo
 
 // 获取文章
 func (l *GetPostLogic) GetPost(in *post.PostId) (*post.PostDto, error) {
-	// 声明一个Post模型变量用于存储查询结果
-	var postModel models.Post
-
-	// 使用GORM根据ID查询文章
-	result := inits.MysqlDb.First(&postModel, in.Id)
-	if result.Error != nil {
-		l.Error("查询文章失败：", result.Error)
-		return nil, result.Error
+	// 根据ID查询文章
+	postModel, err := l.svcCtx.PostRepo.FindByID(l.ctx, in.Id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, errorx.Newf(errorx.CodePostNotFound, "文章 %d 不存在", in.Id)
+	}
+	if err != nil {
+		l.Error("查询文章失败：", err)
+		return nil, errorx.New(errorx.CodeInternal, "查询文章失败")
 	}
 
 	// 将Post模型转换为PostDto
@@ -47,6 +48,7 @@ func (l *GetPostLogic) GetPost(in *post.PostId) (*post.PostDto, error) {
 		ViewCount: int64(postModel.ViewCount),
 		LikeCount: int64(postModel.LikeCount),
 		UserID:    postModel.UserID,
+		CreatedAt: postModel.CreatedAt.Unix(),
 	}
 
 	l.Info("查询文章成功，ID：", in.Id)
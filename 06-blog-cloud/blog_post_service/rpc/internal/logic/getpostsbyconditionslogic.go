@@ -2,15 +2,23 @@ package logic
 
 import (
 	"context"
+	"encoding/base64"
+	"encoding/json"
+	"time"
 
-	"blog-post-service/rpc/inits"
+	"blog-common/errorx"
+	"blog-post-service/rpc/internal/repository"
 	"blog-post-service/rpc/internal/svc"
-	"blog-post-service/rpc/models"
 	"blog-post-service/rpc/types/post"
 
 	"github.com/zeromicro/go-zero/core/logx"
 )
 
+const (
+	defaultPageSize = 10
+	maxPageSize     = 100
+)
+
 type GetPostsByConditionsLogic struct {
 	ctx    context.Context
 	svcCtx *svc.ServiceContext
@@ -25,40 +33,76 @@ func NewGetPostsByConditionsLogic(ctx context.Context, svcCtx *svc.ServiceContex
 	}
 }
 
-// 多条件查询文章 不传入就是查询所有
+// 多条件分页查询文章 条件不传入就是查询所有 按游标翻页
 func (l *GetPostsByConditionsLogic) GetPostsByConditions(in *post.PostDtoConditions) (*post.PostDtoList, error) {
-	// 声明一个Post模型切片用于存储查询结果
-	var postModels []models.Post
-	var total int64
-
-	// 构建查询条件
-	query := inits.MysqlDb.Model(&models.Post{})
-
-	// 根据条件过滤
-	if in.Title != "" {
-		query = query.Where("title LIKE ?", "%"+in.Title+"%")
+	if in.PageSize < 0 || in.PageSize > maxPageSize {
+		return nil, errorx.Newf(errorx.CodeInvalidParam, "每页条数不能超过 %d", maxPageSize)
 	}
-	if in.Content != "" {
-		query = query.Where("content LIKE ?", "%"+in.Content+"%")
+	pageSize := int(in.PageSize)
+	if pageSize == 0 {
+		pageSize = defaultPageSize
 	}
-	if in.UserID > 0 {
-		query = query.Where("user_id = ?", in.UserID)
+	if _, ok := post.PostSortField_name[int32(in.SortBy)]; !ok {
+		return nil, errorx.New(errorx.CodeInvalidParam, "无效的排序字段")
+	}
+	if in.CreatedFrom < 0 || in.CreatedTo < 0 || (in.CreatedTo > 0 && in.CreatedFrom >= in.CreatedTo) {
+		return nil, errorx.New(errorx.CodeInvalidParam, "无效的创建时间范围")
 	}
 
-	// 统计符合条件的总数
-	if err := query.Count(&total).Error; err != nil {
-		l.Error("统计文章总数失败：", err)
-		return nil, err
+	// 构建查询条件 为空的条件不参与过滤
+	cond := repository.PostConditions{
+		Title:       in.Title,
+		Content:     in.Content,
+		UserID:      in.UserID,
+		SortBy:      repository.SortField(in.SortBy),
+		Asc:         in.Asc,
+		SummaryOnly: in.SummaryOnly,
+		// 多查一条判断是否还有下一页
+		Limit: pageSize + 1,
+	}
+	if in.CreatedFrom > 0 {
+		cond.CreatedFrom = time.Unix(in.CreatedFrom, 0)
+	}
+	if in.CreatedTo > 0 {
+		cond.CreatedTo = time.Unix(in.CreatedTo, 0)
+	}
+	if in.Cursor != "" {
+		c, err := decodeCursor(in.Cursor)
+		if err != nil || c.Sort != in.SortBy || c.Asc != in.Asc {
+			return nil, errorx.New(errorx.CodeInvalidParam, "无效的游标 改变排序方式后要从第一页开始")
+		}
+		cond.After = &repository.PostKey{Value: c.Value, ID: c.ID}
 	}
 
 	// 执行查询
-	if err := query.Find(&postModels).Error; err != nil {
+	postModels, err := l.svcCtx.PostRepo.Find(l.ctx, cond)
+	if err != nil {
 		l.Error("查询文章列表失败：", err)
-		return nil, err
+		return nil, errorx.New(errorx.CodeInternal, "查询文章列表失败")
+	}
+
+	resp := &post.PostDtoList{}
+	if len(postModels) > pageSize {
+		postModels = postModels[:pageSize]
+		last := &postModels[pageSize-1]
+		resp.NextCursor = encodeCursor(&cursor{
+			Sort:  in.SortBy,
+			Asc:   in.Asc,
+			Value: cond.SortBy.Value(last),
+			ID:    last.ID,
+		})
+	}
+
+	// 统计符合条件的总数 只在调用方需要时统计
+	if in.WithTotal {
+		if resp.Total, err = l.svcCtx.PostRepo.Count(l.ctx, cond); err != nil {
+			l.Error("统计文章总数失败：", err)
+			return nil, errorx.New(errorx.CodeInternal, "查询文章列表失败")
+		}
 	}
 
 	// 将Post模型切片转换为PostDto切片
-	postDtos := make([]*post.PostDto, 0, len(postModels))
+	resp.Posts = make([]*post.PostDto, 0, len(postModels))
 	for _, postModel := range postModels {
 		postDto := &post.PostDto{
 			ID:        postModel.ID,
@@ -69,13 +113,36 @@ func (l *GetPostsByConditionsLogic) GetPostsByConditions(in *post.PostDtoConditi
 			ViewCount: int64(postModel.ViewCount),
 			LikeCount: int64(postModel.LikeCount),
 			UserID:    postModel.UserID,
+			CreatedAt: postModel.CreatedAt.Unix(),
 		}
-		postDtos = append(postDtos, postDto)
+		resp.Posts = append(resp.Posts, postDto)
 	}
 
-	l.Info("查询文章列表成功，共找到：", total, "篇")
-	return &post.PostDtoList{
-		Posts: postDtos,
-		Total: int32(total),
-	}, nil
+	l.Info("查询文章列表成功，本页：", len(resp.Posts), "篇")
+	return resp, nil
+}
+
+// cursor 游标内容 对调用方不透明 包含排序方式和上一页最后一篇文章的位置
+type cursor struct {
+	Sort  post.PostSortField `json:"s"`
+	Asc   bool               `json:"a"`
+	Value int64              `json:"v"`
+	ID    int64              `json:"i"`
+}
+
+func encodeCursor(c *cursor) string {
+	b, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func decodeCursor(s string) (*cursor, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	c := &cursor{}
+	if err := json.Unmarshal(b, c); err != nil {
+		return nil, err
+	}
+	return c, nil
 }
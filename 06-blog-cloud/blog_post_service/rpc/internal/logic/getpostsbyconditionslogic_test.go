@@ -0,0 +1,156 @@
+package logic
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"blog-common/errorx"
+	"blog-post-service/rpc/models"
+	"blog-post-service/rpc/types/post"
+)
+
+// listAll 按游标翻完所有页 返回每页的文章ID
+func listAll(t *testing.T, l *GetPostsByConditionsLogic, in *post.PostDtoConditions) [][]int64 {
+	t.Helper()
+	var pages [][]int64
+	for {
+		resp, err := l.GetPostsByConditions(in)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var ids []int64
+		for _, p := range resp.Posts {
+			ids = append(ids, p.ID)
+		}
+		pages = append(pages, ids)
+		if resp.NextCursor == "" {
+			return pages
+		}
+		if len(pages) > 100 {
+			t.Fatal("too many pages")
+		}
+		in.Cursor = resp.NextCursor
+	}
+}
+
+func TestGetPostsByConditionsPaging(t *testing.T) {
+	svcCtx := newTestServiceContext()
+	// 阅读量只有 3 种 翻页时要按ID区分阅读量相同的文章
+	var ids []int64
+	for i := range 25 {
+		ids = append(ids, createPost(t, svcCtx, models.Post{
+			Title:     "title",
+			Content:   "content",
+			UserID:    authorID + int64(i%2),
+			ViewCount: i % 3,
+		}))
+	}
+	newest := slices.Clone(ids)
+	slices.Reverse(newest)
+
+	var byViews []int64
+	for views := range 3 {
+		for i, id := range ids {
+			if i%3 == views {
+				byViews = append(byViews, id)
+			}
+		}
+	}
+
+	var byAuthor []int64
+	for i, id := range newest {
+		if i%2 == 0 {
+			byAuthor = append(byAuthor, id)
+		}
+	}
+
+	tests := []struct {
+		name      string
+		in        *post.PostDtoConditions
+		wantPages []int
+		want      []int64
+	}{
+		{name: "default page size", in: &post.PostDtoConditions{}, wantPages: []int{10, 10, 5}, want: newest},
+		{name: "exact pages", in: &post.PostDtoConditions{PageSize: 5}, wantPages: []int{5, 5, 5, 5, 5}, want: newest},
+		{name: "single page", in: &post.PostDtoConditions{PageSize: 100}, wantPages: []int{25}, want: newest},
+		{name: "view count asc with ties", in: &post.PostDtoConditions{PageSize: 4, SortBy: post.PostSortField_SORT_VIEW_COUNT, Asc: true}, wantPages: []int{4, 4, 4, 4, 4, 4, 1}, want: byViews},
+		{name: "filter by author", in: &post.PostDtoConditions{PageSize: 5, UserID: authorID}, wantPages: []int{5, 5, 3}, want: byAuthor},
+		{name: "no match", in: &post.PostDtoConditions{Title: "missing"}, wantPages: []int{0}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pages := listAll(t, NewGetPostsByConditionsLogic(context.Background(), svcCtx), tt.in)
+			var sizes []int
+			var got []int64
+			for _, page := range pages {
+				sizes = append(sizes, len(page))
+				got = append(got, page...)
+			}
+			if !slices.Equal(sizes, tt.wantPages) {
+				t.Errorf("page sizes = %v, want %v", sizes, tt.wantPages)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("ids = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetPostsByConditionsTotalAndSummary(t *testing.T) {
+	svcCtx := newTestServiceContext()
+	for range 3 {
+		createPost(t, svcCtx, models.Post{Title: "title", Content: "content", Summary: "summary", UserID: authorID})
+	}
+
+	resp, err := NewGetPostsByConditionsLogic(context.Background(), svcCtx).GetPostsByConditions(&post.PostDtoConditions{
+		PageSize:    2,
+		WithTotal:   true,
+		SummaryOnly: true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 总数是符合条件的全部文章 不受分页影响
+	if resp.Total != 3 || len(resp.Posts) != 2 {
+		t.Errorf("total = %d, posts = %d, want 3 and 2", resp.Total, len(resp.Posts))
+	}
+	for _, p := range resp.Posts {
+		if p.Content != "" || p.Summary != "summary" {
+			t.Errorf("post = %v, want summary only", p)
+		}
+	}
+}
+
+func TestGetPostsByConditionsInvalid(t *testing.T) {
+	svcCtx := newTestServiceContext()
+	for range 3 {
+		createPost(t, svcCtx, models.Post{Title: "title", Content: "content", UserID: authorID})
+	}
+	l := NewGetPostsByConditionsLogic(context.Background(), svcCtx)
+	first, err := l.GetPostsByConditions(&post.PostDtoConditions{PageSize: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		in   *post.PostDtoConditions
+	}{
+		{name: "page size too large", in: &post.PostDtoConditions{PageSize: maxPageSize + 1}},
+		{name: "negative page size", in: &post.PostDtoConditions{PageSize: -1}},
+		{name: "unknown sort field", in: &post.PostDtoConditions{SortBy: 99}},
+		{name: "invalid time range", in: &post.PostDtoConditions{CreatedFrom: 200, CreatedTo: 100}},
+		{name: "malformed cursor", in: &post.PostDtoConditions{Cursor: "not-a-cursor"}},
+		{name: "cursor with other sort", in: &post.PostDtoConditions{Cursor: first.NextCursor, SortBy: post.PostSortField_SORT_LIKE_COUNT}},
+		{name: "cursor with other order", in: &post.PostDtoConditions{Cursor: first.NextCursor, Asc: true}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := l.GetPostsByConditions(tt.in)
+			if code := errorCode(err); code != errorx.CodeInvalidParam {
+				t.Errorf("code = %d (%v), want %d", code, err, errorx.CodeInvalidParam)
+			}
+		})
+	}
+}
@@ -0,0 +1,72 @@
+package logic
+
+import (
+	"context"
+	"testing"
+
+	"blog-common/auth"
+	"blog-common/errorx"
+	"blog-post-service/rpc/internal/config"
+	"blog-post-service/rpc/internal/repository"
+	"blog-post-service/rpc/internal/svc"
+	"blog-post-service/rpc/models"
+	"blog_user_service/rpc/types/user"
+	"blog_user_service/rpc/userservice"
+
+	"google.golang.org/grpc"
+)
+
+// 测试用户 author 是文章作者 admin 是管理员
+const (
+	authorID int64 = 1
+	otherID  int64 = 2
+	adminID  int64 = 3
+)
+
+// userService 只实现 authorize 用到的 GetUserById
+type userService struct {
+	userservice.UserService
+	roles map[int64]string
+}
+
+func (s *userService) GetUserById(ctx context.Context, in *user.IdDto, opts ...grpc.CallOption) (*user.UserInfoVo, error) {
+	role, ok := s.roles[in.Id]
+	if !ok {
+		return nil, errorx.Newf(errorx.CodeUserNotFound, "用户 %d 不存在", in.Id)
+	}
+	return &user.UserInfoVo{Id: in.Id, Role: role}, nil
+}
+
+func newTestServiceContext() *svc.ServiceContext {
+	users := &userService{roles: map[int64]string{
+		authorID: auth.RoleUser,
+		otherID:  auth.RoleUser,
+		adminID:  auth.RoleAdmin,
+	}}
+	return svc.NewServiceContextWithRepo(config.Config{}, repository.NewMemoryPostRepository(), users)
+}
+
+// createPost 直接写入仓库 可以指定阅读量等由服务维护的字段
+func createPost(t *testing.T, svcCtx *svc.ServiceContext, p models.Post) int64 {
+	t.Helper()
+	if err := svcCtx.PostRepo.Create(context.Background(), &p); err != nil {
+		t.Fatal(err)
+	}
+	return p.ID
+}
+
+// withCaller 模拟网关校验令牌后的 ctx userID 为 0 时表示未登录
+func withCaller(userID int64) context.Context {
+	if userID == 0 {
+		return context.Background()
+	}
+	return auth.WithCaller(context.Background(), &auth.Caller{UserID: userID})
+}
+
+// errorCode 错误对应的业务错误码 nil 为 CodeOK
+func errorCode(err error) int {
+	if err == nil {
+		return errorx.CodeOK
+	}
+	return errorx.FromError(err).Code
+}
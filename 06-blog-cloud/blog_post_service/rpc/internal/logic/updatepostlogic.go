@@ -2,13 +2,15 @@ package logic
 
 import (
 	"context"
+	"errors"
 
-	"blog-post-service/rpc/inits"
+	"blog-common/errorx"
 	"blog-post-service/rpc/internal/svc"
 	"blog-post-service/rpc/models"
 	"blog-post-service/rpc/types/post"
 
 	"github.com/zeromicro/go-zero/core/logx"
+	"gorm.io/gorm"
 )
 
 type UpdatePostLogic struct {
@@ -25,39 +27,40 @@ func NewUpdatePostLogic(ctx context.Context, svcCtx *svc.ServiceContext) *Update
 	}
 }
 
-// 更新文章
+// 更新文章 只有作者和管理员可以更新
+// 只更新标题、内容、摘要和封面 作者和阅读量、点赞量由服务维护
 func (l *UpdatePostLogic) UpdatePost(in *post.PostDto) (*post.IsSuccess, error) {
-	// 先检查文章是否存在
-	var existingPost models.Post
-
-	// 构建更新数据
-	updateData := map[string]interface{}{
-		"title":      in.Title,
-		"content":    in.Content,
-		"summary":    in.Summary,
-		"cover":      in.Cover,
-		"view_count": in.ViewCount,
-		"like_count": in.LikeCount,
-		"user_id":    in.UserID,
+	if in.Title == "" {
+		return nil, errorx.New(errorx.CodeInvalidParam, "文章标题不能为空")
 	}
-	result := inits.MysqlDb.First(&existingPost, in.ID)
-	if result.Error != nil {
-		//保存文章
-		inits.MysqlDb.Model(&existingPost).Save(updateData)
-		return &post.IsSuccess{Success: false}, result.Error
+	if in.Content == "" {
+		return nil, errorx.New(errorx.CodeInvalidParam, "文章内容不能为空")
 	}
 
-	// 执行更新操作
-	result = inits.MysqlDb.Model(&existingPost).Updates(updateData)
-	if result.Error != nil {
-		l.Error("更新文章失败：", result.Error)
-		return &post.IsSuccess{Success: false}, result.Error
+	// 先检查文章是否存在
+	existingPost, err := l.svcCtx.PostRepo.FindByID(l.ctx, in.ID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, errorx.Newf(errorx.CodePostNotFound, "文章 %d 不存在", in.ID)
+	}
+	if err != nil {
+		l.Error("查询文章失败：", err)
+		return nil, errorx.New(errorx.CodeInternal, "更新文章失败")
+	}
+	if err := authorize(l.ctx, l.svcCtx, existingPost); err != nil {
+		return nil, err
 	}
 
-	// 检查是否真的有行被更新
-	if result.RowsAffected == 0 {
-		l.Errorf("未找到需要更新的文章，ID：", in.ID)
-		return &post.IsSuccess{Success: false}, nil
+	// 构建更新数据 in 中的作者和计数不会被使用
+	updateData := &models.Post{
+		BaseModel: models.BaseModel{ID: in.ID},
+		Title:     in.Title,
+		Content:   in.Content,
+		Summary:   in.Summary,
+		Cover:     in.Cover,
+	}
+	if err := l.svcCtx.PostRepo.Update(l.ctx, updateData); err != nil {
+		l.Error("更新文章失败：", err)
+		return nil, errorx.New(errorx.CodeInternal, "更新文章失败")
 	}
 
 	l.Info("更新文章成功，ID：", in.ID)
@@ -0,0 +1,57 @@
+package logic
+
+import (
+	"context"
+	"testing"
+
+	"blog-common/errorx"
+	"blog-post-service/rpc/models"
+	"blog-post-service/rpc/types/post"
+)
+
+func TestUpdatePost(t *testing.T) {
+	tests := []struct {
+		name     string
+		caller   int64
+		missing  bool
+		title    string
+		wantCode int
+	}{
+		{name: "author", caller: authorID, title: "new"},
+		{name: "admin", caller: adminID, title: "new"},
+		{name: "other user", caller: otherID, title: "new", wantCode: errorx.CodeForbidden},
+		{name: "not logged in", title: "new", wantCode: errorx.CodeUnauthorized},
+		{name: "caller not found", caller: 99, title: "new", wantCode: errorx.CodeUserNotFound},
+		{name: "post not found", caller: authorID, missing: true, title: "new", wantCode: errorx.CodePostNotFound},
+		{name: "empty title", caller: authorID, wantCode: errorx.CodeInvalidParam},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svcCtx := newTestServiceContext()
+			id := createPost(t, svcCtx, models.Post{Title: "old", Content: "old", UserID: authorID, ViewCount: 5})
+			target := id
+			if tt.missing {
+				target = id + 1
+			}
+			// 作者和计数由服务维护 请求中的值不生效
+			req := &post.PostDto{ID: target, Title: tt.title, Content: "new", UserID: otherID, ViewCount: 100}
+
+			_, err := NewUpdatePostLogic(withCaller(tt.caller), svcCtx).UpdatePost(req)
+			if code := errorCode(err); code != tt.wantCode {
+				t.Fatalf("code = %d (%v), want %d", code, err, tt.wantCode)
+			}
+
+			got, err := svcCtx.PostRepo.FindByID(context.Background(), id)
+			if err != nil {
+				t.Fatal(err)
+			}
+			wantTitle := "old"
+			if tt.wantCode == errorx.CodeOK {
+				wantTitle = "new"
+			}
+			if got.Title != wantTitle || got.UserID != authorID || got.ViewCount != 5 {
+				t.Errorf("post = %+v, want title %q by %d", got, wantTitle, authorID)
+			}
+		})
+	}
+}
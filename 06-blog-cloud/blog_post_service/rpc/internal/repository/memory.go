@@ -0,0 +1,133 @@
+package repository
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"blog-post-service/rpc/models"
+
+	"gorm.io/gorm"
+)
+
+// memoryPostRepository 数据保存在内存中的 PostRepository 行为与 gorm 实现一致
+type memoryPostRepository struct {
+	mu     sync.Mutex
+	nextID int64
+	posts  map[int64]models.Post
+}
+
+// NewMemoryPostRepository 数据保存在内存中的 PostRepository 测试 logic 时通过 svc.NewServiceContextWithRepo 注入
+func NewMemoryPostRepository() PostRepository {
+	return &memoryPostRepository{posts: make(map[int64]models.Post)}
+}
+
+func (r *memoryPostRepository) FindByID(ctx context.Context, id int64) (*models.Post, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	p, ok := r.posts[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &p, nil
+}
+
+func (r *memoryPostRepository) Find(ctx context.Context, cond PostConditions) ([]models.Post, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	// less 按 (排序字段, ID) 比较 a 是否排在 b 之前
+	less := func(a, b PostKey) bool {
+		if !cond.Asc {
+			a, b = b, a
+		}
+		if a.Value != b.Value {
+			return a.Value < b.Value
+		}
+		return a.ID < b.ID
+	}
+	key := func(p *models.Post) PostKey {
+		return PostKey{Value: cond.SortBy.Value(p), ID: p.ID}
+	}
+
+	var posts []models.Post
+	for _, p := range r.filter(cond) {
+		if cond.After != nil && !less(*cond.After, key(&p)) {
+			continue
+		}
+		if cond.SummaryOnly {
+			p.Content = ""
+		}
+		posts = append(posts, p)
+	}
+	sort.Slice(posts, func(i, j int) bool { return less(key(&posts[i]), key(&posts[j])) })
+	if cond.Limit > 0 && len(posts) > cond.Limit {
+		posts = posts[:cond.Limit]
+	}
+	return posts, nil
+}
+
+func (r *memoryPostRepository) Count(ctx context.Context, cond PostConditions) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return int64(len(r.filter(cond))), nil
+}
+
+// filter 返回符合过滤条件的文章 调用方需持有锁
+func (r *memoryPostRepository) filter(cond PostConditions) []models.Post {
+	var posts []models.Post
+	for _, p := range r.posts {
+		if cond.Title != "" && !strings.Contains(p.Title, cond.Title) {
+			continue
+		}
+		if cond.Content != "" && !strings.Contains(p.Content, cond.Content) {
+			continue
+		}
+		if cond.UserID > 0 && p.UserID != cond.UserID {
+			continue
+		}
+		if !cond.CreatedFrom.IsZero() && p.CreatedAt.Before(cond.CreatedFrom) {
+			continue
+		}
+		if !cond.CreatedTo.IsZero() && !p.CreatedAt.Before(cond.CreatedTo) {
+			continue
+		}
+		posts = append(posts, p)
+	}
+	return posts
+}
+
+func (r *memoryPostRepository) Create(ctx context.Context, p *models.Post) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.nextID++
+	now := time.Now()
+	p.ID, p.CreatedAt, p.UpdatedAt = r.nextID, now, now
+	r.posts[p.ID] = *p
+	return nil
+}
+
+func (r *memoryPostRepository) Update(ctx context.Context, p *models.Post) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	old, ok := r.posts[p.ID]
+	if !ok {
+		// gorm 更新不存在的记录时不返回错误
+		return nil
+	}
+	old.Title, old.Content, old.Summary, old.Cover = p.Title, p.Content, p.Summary, p.Cover
+	old.UpdatedAt = time.Now()
+	r.posts[p.ID] = old
+	return nil
+}
+
+func (r *memoryPostRepository) Delete(ctx context.Context, id int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.posts[id]; !ok {
+		return gorm.ErrRecordNotFound
+	}
+	delete(r.posts, id)
+	return nil
+}
@@ -0,0 +1,184 @@
+// Package repository 文章服务的数据访问 logic 只依赖接口
+// 查询不到时返回 gorm.ErrRecordNotFound
+package repository
+
+import (
+	"context"
+	"time"
+
+	"blog-post-service/rpc/models"
+
+	"gorm.io/gorm"
+)
+
+// SortField 文章排序字段 值相同时按ID排序
+type SortField int
+
+const (
+	SortByCreatedAt SortField = iota
+	SortByViewCount
+	SortByLikeCount
+)
+
+// column 排序字段对应的列
+func (s SortField) column() string {
+	switch s {
+	case SortByViewCount:
+		return "view_count"
+	case SortByLikeCount:
+		return "like_count"
+	default:
+		return "created_at"
+	}
+}
+
+// Value 文章排序字段的值 用于生成游标 创建时间为 Unix 纳秒
+func (s SortField) Value(p *models.Post) int64 {
+	switch s {
+	case SortByViewCount:
+		return int64(p.ViewCount)
+	case SortByLikeCount:
+		return int64(p.LikeCount)
+	default:
+		return p.CreatedAt.UnixNano()
+	}
+}
+
+// arg Value 对应的查询参数
+func (s SortField) arg(value int64) interface{} {
+	if s == SortByCreatedAt {
+		return time.Unix(0, value)
+	}
+	return value
+}
+
+// PostKey 翻页位置 上一页最后一篇文章的排序字段值和ID
+type PostKey struct {
+	Value int64
+	ID    int64
+}
+
+// PostConditions 文章查询条件 为空的条件不参与过滤
+type PostConditions struct {
+	// 标题和内容按包含匹配
+	Title   string
+	Content string
+	UserID  int64
+	// 创建时间范围 包含 CreatedFrom 不包含 CreatedTo
+	CreatedFrom time.Time
+	CreatedTo   time.Time
+
+	// 以下字段只用于 Find
+	SortBy SortField
+	Asc    bool
+	// After 不为空时从这个位置之后开始
+	After *PostKey
+	Limit int
+	// SummaryOnly 不查询 Content
+	SummaryOnly bool
+}
+
+// PostRepository 文章数据访问
+type PostRepository interface {
+	// FindByID 根据ID查询文章
+	FindByID(ctx context.Context, id int64) (*models.Post, error)
+	// Find 按条件和排序查询一页文章
+	Find(ctx context.Context, cond PostConditions) ([]models.Post, error)
+	// Count 统计符合过滤条件的文章数 忽略排序和翻页
+	Count(ctx context.Context, cond PostConditions) (int64, error)
+	// Create 新增文章 成功后 p.ID 为新文章的ID
+	Create(ctx context.Context, p *models.Post) error
+	// Update 按 p.ID 更新文章的标题、内容、摘要和封面 包括零值
+	// 作者和阅读量、点赞量由服务维护 不会被修改
+	Update(ctx context.Context, p *models.Post) error
+	// Delete 软删除文章 文章不存在时返回 gorm.ErrRecordNotFound
+	Delete(ctx context.Context, id int64) error
+}
+
+// contentColumns Update 更新的列
+var contentColumns = []string{"title", "content", "summary", "cover"}
+
+type gormPostRepository struct {
+	db *gorm.DB
+}
+
+// NewPostRepository 基于 gorm 的 PostRepository
+func NewPostRepository(db *gorm.DB) PostRepository {
+	return &gormPostRepository{db: db}
+}
+
+func (r *gormPostRepository) FindByID(ctx context.Context, id int64) (*models.Post, error) {
+	var p models.Post
+	if err := r.db.WithContext(ctx).First(&p, id).Error; err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+func (r *gormPostRepository) Find(ctx context.Context, cond PostConditions) ([]models.Post, error) {
+	query := r.filter(ctx, cond)
+	col, op, dir := cond.SortBy.column(), "<", "DESC"
+	if cond.Asc {
+		op, dir = ">", "ASC"
+	}
+	// 按 (排序字段, ID) 定位 不使用 OFFSET 翻页深度不影响性能
+	if cond.After != nil {
+		value := cond.SortBy.arg(cond.After.Value)
+		query = query.Where("("+col+" "+op+" ? OR ("+col+" = ? AND id "+op+" ?))", value, value, cond.After.ID)
+	}
+	if cond.SummaryOnly {
+		query = query.Omit("content")
+	}
+
+	var posts []models.Post
+	err := query.Order(col + " " + dir).Order("id " + dir).Limit(cond.Limit).Find(&posts).Error
+	return posts, err
+}
+
+func (r *gormPostRepository) Count(ctx context.Context, cond PostConditions) (int64, error) {
+	var total int64
+	err := r.filter(ctx, cond).Count(&total).Error
+	return total, err
+}
+
+// filter 根据过滤条件构建查询
+func (r *gormPostRepository) filter(ctx context.Context, cond PostConditions) *gorm.DB {
+	query := r.db.WithContext(ctx).Model(&models.Post{})
+	if cond.Title != "" {
+		query = query.Where("title LIKE ?", "%"+cond.Title+"%")
+	}
+	if cond.Content != "" {
+		query = query.Where("content LIKE ?", "%"+cond.Content+"%")
+	}
+	if cond.UserID > 0 {
+		query = query.Where("user_id = ?", cond.UserID)
+	}
+	if !cond.CreatedFrom.IsZero() {
+		query = query.Where("created_at >= ?", cond.CreatedFrom)
+	}
+	if !cond.CreatedTo.IsZero() {
+		query = query.Where("created_at < ?", cond.CreatedTo)
+	}
+	return query
+}
+
+func (r *gormPostRepository) Create(ctx context.Context, p *models.Post) error {
+	return r.db.WithContext(ctx).Create(p).Error
+}
+
+func (r *gormPostRepository) Update(ctx context.Context, p *models.Post) error {
+	return r.db.WithContext(ctx).Model(&models.Post{BaseModel: models.BaseModel{ID: p.ID}}).
+		Select(contentColumns).Updates(p).Error
+}
+
+func (r *gormPostRepository) Delete(ctx context.Context, id int64) error {
+	// 模型中定义了 DeletedAt 字段 为软删除
+	result := r.db.WithContext(ctx).Delete(&models.Post{}, id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
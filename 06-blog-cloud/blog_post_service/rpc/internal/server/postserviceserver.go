@@ -24,7 +24,7 @@ func NewPostServiceServer(svcCtx *svc.ServiceContext) *PostServiceServer {
 }
 
 // 新增文章
-func (s *PostServiceServer) CreatePost(ctx context.Context, in *post.PostDto) (*post.IsSuccess, error) {
+func (s *PostServiceServer) CreatePost(ctx context.Context, in *post.PostDto) (*post.PostId, error) {
 	l := logic.NewCreatePostLogic(ctx, s.svcCtx)
 	return l.CreatePost(in)
 }
@@ -1,13 +1,48 @@
 package svc
 
-import "blog-post-service/rpc/internal/config"
+import (
+	"log"
+
+	"blog-common/database"
+	"blog-common/tracing"
+	"blog-post-service/rpc/internal/config"
+	"blog-post-service/rpc/internal/repository"
+	"blog-post-service/rpc/models"
+	"blog_user_service/rpc/userservice"
+
+	"github.com/zeromicro/go-zero/zrpc"
+)
 
 type ServiceContext struct {
-	Config config.Config
+	Config   config.Config
+	PostRepo repository.PostRepository
+	UserRpc  userservice.UserService
 }
 
 func NewServiceContext(c config.Config) *ServiceContext {
+	db := database.MustOpen(c.MySQL)
+	// 自动迁移所有模型
+	if c.MySQL.IsAutoMigrate {
+		if err := db.AutoMigrate(
+			&models.Post{},
+		); err != nil {
+			log.Fatalf("failed to migrate database: %v", err)
+		}
+	}
+	// 请求ID通过 gRPC metadata 传给 UserService
+	client, err := zrpc.NewClient(c.UserRpc, zrpc.WithUnaryClientInterceptor(tracing.UnaryClientInterceptor))
+	if err != nil {
+		panic(err)
+	}
+	return NewServiceContextWithRepo(c, repository.NewPostRepository(db), userservice.NewUserService(client))
+}
+
+// NewServiceContextWithRepo 使用指定的 PostRepository 和 UserService
+// 测试时可以传入 repository.NewMemoryPostRepository()
+func NewServiceContextWithRepo(c config.Config, postRepo repository.PostRepository, userRpc userservice.UserService) *ServiceContext {
 	return &ServiceContext{
-		Config: c,
+		Config:   c,
+		PostRepo: postRepo,
+		UserRpc:  userRpc,
 	}
 }
@@ -8,7 +8,7 @@ import (
 
 type BaseModel struct {
 	ID        int64          `json:"id" gorm:"primaryKey"`
-	CreatedAt time.Time      `json:"created_at" gorm:"column:created_at;autoCreateTime"`
+	CreatedAt time.Time      `json:"created_at" gorm:"column:created_at;autoCreateTime;index"` // 列表按创建时间排序和过滤
 	UpdatedAt time.Time      `json:"updated_at" gorm:"column:updated_at;autoUpdateTime"`
 	DeletedAt gorm.DeletedAt `json:"deleted_at" gorm:"column:deleted_at"`
 	IsDeleted bool           `json:"is_deleted" gorm:"column:is_deleted"`
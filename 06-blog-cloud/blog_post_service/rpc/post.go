@@ -4,7 +4,8 @@ import (
 	"flag"
 	"fmt"
 
-	"blog-post-service/rpc/inits"
+	"blog-common/auth"
+	"blog-common/tracing"
 	"blog-post-service/rpc/internal/config"
 	"blog-post-service/rpc/internal/server"
 	"blog-post-service/rpc/internal/svc"
@@ -17,15 +18,15 @@ import (
 	"google.golang.org/grpc/reflection"
 )
 
-// var configFile = flag.String("f", "etc/post.yaml", "the config file")
+var configFile = flag.String("f", "etc/post.yaml", "the config file")
 
 func main() {
 	flag.Parse()
 
 	var c config.Config
-	conf.MustLoad("etc/post.yaml", &c)
+	conf.MustLoad(*configFile, &c)
+	// 打开数据库连接 创建数据访问
 	ctx := svc.NewServiceContext(c)
-	inits.InitDB()
 	s := zrpc.MustNewServer(c.RpcServerConf, func(grpcServer *grpc.Server) {
 		post.RegisterPostServiceServer(grpcServer, server.NewPostServiceServer(ctx))
 
@@ -33,6 +34,15 @@ func main() {
 			reflection.Register(grpcServer)
 		}
 	})
+	// 从 gRPC metadata 中取出请求ID 写入 logx 上下文
+	// 校验调用方的令牌 查询文章不需要登录
+	s.AddUnaryInterceptors(
+		tracing.UnaryServerInterceptor,
+		auth.NewUnaryServerInterceptor(c.Auth.AccessSecret,
+			post.PostService_GetPost_FullMethodName,
+			post.PostService_GetPostsByConditions_FullMethodName,
+		),
+	)
 	defer s.Stop()
 
 	fmt.Printf("Starting rpc server at %s...\n", c.ListenOn)
@@ -22,7 +22,7 @@ type (
 
 	PostService interface {
 		// 新增文章
-		CreatePost(ctx context.Context, in *PostDto, opts ...grpc.CallOption) (*IsSuccess, error)
+		CreatePost(ctx context.Context, in *PostDto, opts ...grpc.CallOption) (*PostId, error)
 		// 获取文章
 		GetPost(ctx context.Context, in *PostId, opts ...grpc.CallOption) (*PostDto, error)
 		// 多条件查询文章 不传入就是查询所有
@@ -45,7 +45,7 @@ func NewPostService(cli zrpc.Client) PostService {
 }
 
 // 新增文章
-func (m *defaultPostService) CreatePost(ctx context.Context, in *PostDto, opts ...grpc.CallOption) (*IsSuccess, error) {
+func (m *defaultPostService) CreatePost(ctx context.Context, in *PostDto, opts ...grpc.CallOption) (*PostId, error) {
 	client := post.NewPostServiceClient(m.cli.Conn())
 	return client.CreatePost(ctx, in, opts...)
 }
@@ -4,6 +4,8 @@
 // 	protoc        v3.19.4
 // source: post.proto
 
+// 用户服务的 user.proto 没有 package 声明 post 服务调用 UserService 时同名消息 IsSuccess 会在同一进程中冲突
+
 package post
 
 import (
@@ -20,6 +22,56 @@ const (
 	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
 )
 
+// 排序字段
+type PostSortField int32
+
+const (
+	PostSortField_SORT_CREATED_AT PostSortField = 0
+	PostSortField_SORT_VIEW_COUNT PostSortField = 1
+	PostSortField_SORT_LIKE_COUNT PostSortField = 2
+)
+
+// Enum value maps for PostSortField.
+var (
+	PostSortField_name = map[int32]string{
+		0: "SORT_CREATED_AT",
+		1: "SORT_VIEW_COUNT",
+		2: "SORT_LIKE_COUNT",
+	}
+	PostSortField_value = map[string]int32{
+		"SORT_CREATED_AT": 0,
+		"SORT_VIEW_COUNT": 1,
+		"SORT_LIKE_COUNT": 2,
+	}
+)
+
+func (x PostSortField) Enum() *PostSortField {
+	p := new(PostSortField)
+	*p = x
+	return p
+}
+
+func (x PostSortField) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (PostSortField) Descriptor() protoreflect.EnumDescriptor {
+	return file_post_proto_enumTypes[0].Descriptor()
+}
+
+func (PostSortField) Type() protoreflect.EnumType {
+	return &file_post_proto_enumTypes[0]
+}
+
+func (x PostSortField) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use PostSortField.Descriptor instead.
+func (PostSortField) EnumDescriptor() ([]byte, []int) {
+	return file_post_proto_rawDescGZIP(), []int{0}
+}
+
 type PostId struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
@@ -121,8 +173,10 @@ type PostDtoList struct {
 	unknownFields protoimpl.UnknownFields
 
 	Posts []*PostDto `protobuf:"bytes,1,rep,name=posts,proto3" json:"posts,omitempty"`
-	// 总条数
-	Total int32 `protobuf:"varint,2,opt,name=total,proto3" json:"total,omitempty"`
+	// 总条数 只有 WithTotal 为 true 时统计
+	Total int64 `protobuf:"varint,2,opt,name=total,proto3" json:"total,omitempty"`
+	// 下一页的游标 为空时没有下一页
+	NextCursor string `protobuf:"bytes,3,opt,name=NextCursor,proto3" json:"NextCursor,omitempty"`
 }
 
 func (x *PostDtoList) Reset() {
@@ -164,26 +218,37 @@ func (x *PostDtoList) GetPosts() []*PostDto {
 	return nil
 }
 
-func (x *PostDtoList) GetTotal() int32 {
+func (x *PostDtoList) GetTotal() int64 {
 	if x != nil {
 		return x.Total
 	}
 	return 0
 }
 
+func (x *PostDtoList) GetNextCursor() string {
+	if x != nil {
+		return x.NextCursor
+	}
+	return ""
+}
+
 type PostDto struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	Title     string `protobuf:"bytes,1,opt,name=Title,proto3" json:"Title,omitempty"`
-	Content   string `protobuf:"bytes,2,opt,name=Content,proto3" json:"Content,omitempty"`
-	Summary   string `protobuf:"bytes,3,opt,name=Summary,proto3" json:"Summary,omitempty"`
-	Cover     string `protobuf:"bytes,4,opt,name=Cover,proto3" json:"Cover,omitempty"`
-	ViewCount int64  `protobuf:"varint,5,opt,name=ViewCount,proto3" json:"ViewCount,omitempty"`
-	LikeCount int64  `protobuf:"varint,6,opt,name=LikeCount,proto3" json:"LikeCount,omitempty"`
-	UserID    int64  `protobuf:"varint,7,opt,name=UserID,proto3" json:"UserID,omitempty"`
-	ID        int64  `protobuf:"varint,8,opt,name=ID,proto3" json:"ID,omitempty"`
+	Title   string `protobuf:"bytes,1,opt,name=Title,proto3" json:"Title,omitempty"`
+	Content string `protobuf:"bytes,2,opt,name=Content,proto3" json:"Content,omitempty"`
+	Summary string `protobuf:"bytes,3,opt,name=Summary,proto3" json:"Summary,omitempty"`
+	Cover   string `protobuf:"bytes,4,opt,name=Cover,proto3" json:"Cover,omitempty"`
+	// 阅读量和点赞量由服务维护 新增和更新时忽略
+	ViewCount int64 `protobuf:"varint,5,opt,name=ViewCount,proto3" json:"ViewCount,omitempty"`
+	LikeCount int64 `protobuf:"varint,6,opt,name=LikeCount,proto3" json:"LikeCount,omitempty"`
+	// 作者 新增时为调用方 更新时不能修改
+	UserID int64 `protobuf:"varint,7,opt,name=UserID,proto3" json:"UserID,omitempty"`
+	ID     int64 `protobuf:"varint,8,opt,name=ID,proto3" json:"ID,omitempty"`
+	// 创建时间 Unix 秒 只在查询结果中返回
+	CreatedAt int64 `protobuf:"varint,9,opt,name=CreatedAt,proto3" json:"CreatedAt,omitempty"`
 }
 
 func (x *PostDto) Reset() {
@@ -274,15 +339,38 @@ func (x *PostDto) GetID() int64 {
 	return 0
 }
 
-// 文章查询条件
+func (x *PostDto) GetCreatedAt() int64 {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return 0
+}
+
+// 文章查询条件 为空的条件不参与过滤
 type PostDtoConditions struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
+	// 标题和内容按包含匹配
 	Title   string `protobuf:"bytes,1,opt,name=Title,proto3" json:"Title,omitempty"`
 	Content string `protobuf:"bytes,2,opt,name=Content,proto3" json:"Content,omitempty"`
 	UserID  int64  `protobuf:"varint,3,opt,name=UserID,proto3" json:"UserID,omitempty"`
+	// 每页条数 默认 10 最大 100
+	PageSize int32 `protobuf:"varint,4,opt,name=PageSize,proto3" json:"PageSize,omitempty"`
+	// 上一页返回的 NextCursor 为空时从第一页开始 翻页时排序方式要与第一页一致
+	Cursor string `protobuf:"bytes,5,opt,name=Cursor,proto3" json:"Cursor,omitempty"`
+	// 排序字段 相同时按ID排序
+	SortBy PostSortField `protobuf:"varint,6,opt,name=SortBy,proto3,enum=post.PostSortField" json:"SortBy,omitempty"`
+	// 为 true 时升序 默认降序
+	Asc bool `protobuf:"varint,7,opt,name=Asc,proto3" json:"Asc,omitempty"`
+	// 创建时间范围 Unix 秒 包含 CreatedFrom 不包含 CreatedTo 0 表示不限
+	CreatedFrom int64 `protobuf:"varint,8,opt,name=CreatedFrom,proto3" json:"CreatedFrom,omitempty"`
+	CreatedTo   int64 `protobuf:"varint,9,opt,name=CreatedTo,proto3" json:"CreatedTo,omitempty"`
+	// 只返回摘要 不返回 Content
+	SummaryOnly bool `protobuf:"varint,10,opt,name=SummaryOnly,proto3" json:"SummaryOnly,omitempty"`
+	// 是否统计总数 统计需要额外的查询 一般只在第一页统计
+	WithTotal bool `protobuf:"varint,11,opt,name=WithTotal,proto3" json:"WithTotal,omitempty"`
 }
 
 func (x *PostDtoConditions) Reset() {
@@ -338,52 +426,135 @@ func (x *PostDtoConditions) GetUserID() int64 {
 	return 0
 }
 
+func (x *PostDtoConditions) GetPageSize() int32 {
+	if x != nil {
+		return x.PageSize
+	}
+	return 0
+}
+
+func (x *PostDtoConditions) GetCursor() string {
+	if x != nil {
+		return x.Cursor
+	}
+	return ""
+}
+
+func (x *PostDtoConditions) GetSortBy() PostSortField {
+	if x != nil {
+		return x.SortBy
+	}
+	return PostSortField_SORT_CREATED_AT
+}
+
+func (x *PostDtoConditions) GetAsc() bool {
+	if x != nil {
+		return x.Asc
+	}
+	return false
+}
+
+func (x *PostDtoConditions) GetCreatedFrom() int64 {
+	if x != nil {
+		return x.CreatedFrom
+	}
+	return 0
+}
+
+func (x *PostDtoConditions) GetCreatedTo() int64 {
+	if x != nil {
+		return x.CreatedTo
+	}
+	return 0
+}
+
+func (x *PostDtoConditions) GetSummaryOnly() bool {
+	if x != nil {
+		return x.SummaryOnly
+	}
+	return false
+}
+
+func (x *PostDtoConditions) GetWithTotal() bool {
+	if x != nil {
+		return x.WithTotal
+	}
+	return false
+}
+
 var File_post_proto protoreflect.FileDescriptor
 
 var file_post_proto_rawDesc = []byte{
-	0x0a, 0x0a, 0x70, 0x6f, 0x73, 0x74, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x22, 0x18, 0x0a, 0x06,
-	0x50, 0x6f, 0x73, 0x74, 0x49, 0x64, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01,
-	0x28, 0x03, 0x52, 0x02, 0x69, 0x64, 0x22, 0x25, 0x0a, 0x09, 0x49, 0x73, 0x53, 0x75, 0x63, 0x63,
-	0x65, 0x73, 0x73, 0x12, 0x18, 0x0a, 0x07, 0x73, 0x75, 0x63, 0x63, 0x65, 0x73, 0x73, 0x18, 0x01,
-	0x20, 0x01, 0x28, 0x08, 0x52, 0x07, 0x73, 0x75, 0x63, 0x63, 0x65, 0x73, 0x73, 0x22, 0x43, 0x0a,
-	0x0b, 0x50, 0x6f, 0x73, 0x74, 0x44, 0x74, 0x6f, 0x4c, 0x69, 0x73, 0x74, 0x12, 0x1e, 0x0a, 0x05,
-	0x70, 0x6f, 0x73, 0x74, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x08, 0x2e, 0x50, 0x6f,
-	0x73, 0x74, 0x44, 0x74, 0x6f, 0x52, 0x05, 0x70, 0x6f, 0x73, 0x74, 0x73, 0x12, 0x14, 0x0a, 0x05,
-	0x74, 0x6f, 0x74, 0x61, 0x6c, 0x18, 0x02, 0x20, 0x01, 0x28, 0x05, 0x52, 0x05, 0x74, 0x6f, 0x74,
-	0x61, 0x6c, 0x22, 0xcd, 0x01, 0x0a, 0x07, 0x50, 0x6f, 0x73, 0x74, 0x44, 0x74, 0x6f, 0x12, 0x14,
-	0x0a, 0x05, 0x54, 0x69, 0x74, 0x6c, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x54,
-	0x69, 0x74, 0x6c, 0x65, 0x12, 0x18, 0x0a, 0x07, 0x43, 0x6f, 0x6e, 0x74, 0x65, 0x6e, 0x74, 0x18,
-	0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x43, 0x6f, 0x6e, 0x74, 0x65, 0x6e, 0x74, 0x12, 0x18,
-	0x0a, 0x07, 0x53, 0x75, 0x6d, 0x6d, 0x61, 0x72, 0x79, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52,
-	0x07, 0x53, 0x75, 0x6d, 0x6d, 0x61, 0x72, 0x79, 0x12, 0x14, 0x0a, 0x05, 0x43, 0x6f, 0x76, 0x65,
-	0x72, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x43, 0x6f, 0x76, 0x65, 0x72, 0x12, 0x1c,
-	0x0a, 0x09, 0x56, 0x69, 0x65, 0x77, 0x43, 0x6f, 0x75, 0x6e, 0x74, 0x18, 0x05, 0x20, 0x01, 0x28,
-	0x03, 0x52, 0x09, 0x56, 0x69, 0x65, 0x77, 0x43, 0x6f, 0x75, 0x6e, 0x74, 0x12, 0x1c, 0x0a, 0x09,
-	0x4c, 0x69, 0x6b, 0x65, 0x43, 0x6f, 0x75, 0x6e, 0x74, 0x18, 0x06, 0x20, 0x01, 0x28, 0x03, 0x52,
-	0x09, 0x4c, 0x69, 0x6b, 0x65, 0x43, 0x6f, 0x75, 0x6e, 0x74, 0x12, 0x16, 0x0a, 0x06, 0x55, 0x73,
-	0x65, 0x72, 0x49, 0x44, 0x18, 0x07, 0x20, 0x01, 0x28, 0x03, 0x52, 0x06, 0x55, 0x73, 0x65, 0x72,
-	0x49, 0x44, 0x12, 0x0e, 0x0a, 0x02, 0x49, 0x44, 0x18, 0x08, 0x20, 0x01, 0x28, 0x03, 0x52, 0x02,
-	0x49, 0x44, 0x22, 0x5b, 0x0a, 0x11, 0x50, 0x6f, 0x73, 0x74, 0x44, 0x74, 0x6f, 0x43, 0x6f, 0x6e,
-	0x64, 0x69, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x12, 0x14, 0x0a, 0x05, 0x54, 0x69, 0x74, 0x6c, 0x65,
-	0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x54, 0x69, 0x74, 0x6c, 0x65, 0x12, 0x18, 0x0a,
-	0x07, 0x43, 0x6f, 0x6e, 0x74, 0x65, 0x6e, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07,
-	0x43, 0x6f, 0x6e, 0x74, 0x65, 0x6e, 0x74, 0x12, 0x16, 0x0a, 0x06, 0x55, 0x73, 0x65, 0x72, 0x49,
-	0x44, 0x18, 0x03, 0x20, 0x01, 0x28, 0x03, 0x52, 0x06, 0x55, 0x73, 0x65, 0x72, 0x49, 0x44, 0x32,
-	0xd0, 0x01, 0x0a, 0x0b, 0x50, 0x6f, 0x73, 0x74, 0x53, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x12,
-	0x22, 0x0a, 0x0a, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x50, 0x6f, 0x73, 0x74, 0x12, 0x08, 0x2e,
-	0x50, 0x6f, 0x73, 0x74, 0x44, 0x74, 0x6f, 0x1a, 0x0a, 0x2e, 0x49, 0x73, 0x53, 0x75, 0x63, 0x63,
-	0x65, 0x73, 0x73, 0x12, 0x1c, 0x0a, 0x07, 0x47, 0x65, 0x74, 0x50, 0x6f, 0x73, 0x74, 0x12, 0x07,
-	0x2e, 0x50, 0x6f, 0x73, 0x74, 0x49, 0x64, 0x1a, 0x08, 0x2e, 0x50, 0x6f, 0x73, 0x74, 0x44, 0x74,
-	0x6f, 0x12, 0x38, 0x0a, 0x14, 0x47, 0x65, 0x74, 0x50, 0x6f, 0x73, 0x74, 0x73, 0x42, 0x79, 0x43,
-	0x6f, 0x6e, 0x64, 0x69, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x12, 0x12, 0x2e, 0x50, 0x6f, 0x73, 0x74,
-	0x44, 0x74, 0x6f, 0x43, 0x6f, 0x6e, 0x64, 0x69, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x1a, 0x0c, 0x2e,
-	0x50, 0x6f, 0x73, 0x74, 0x44, 0x74, 0x6f, 0x4c, 0x69, 0x73, 0x74, 0x12, 0x21, 0x0a, 0x0a, 0x44,
-	0x65, 0x6c, 0x65, 0x74, 0x65, 0x50, 0x6f, 0x73, 0x74, 0x12, 0x07, 0x2e, 0x50, 0x6f, 0x73, 0x74,
-	0x49, 0x64, 0x1a, 0x0a, 0x2e, 0x49, 0x73, 0x53, 0x75, 0x63, 0x63, 0x65, 0x73, 0x73, 0x12, 0x22,
-	0x0a, 0x0a, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x50, 0x6f, 0x73, 0x74, 0x12, 0x08, 0x2e, 0x50,
-	0x6f, 0x73, 0x74, 0x44, 0x74, 0x6f, 0x1a, 0x0a, 0x2e, 0x49, 0x73, 0x53, 0x75, 0x63, 0x63, 0x65,
-	0x73, 0x73, 0x42, 0x08, 0x5a, 0x06, 0x2e, 0x2f, 0x70, 0x6f, 0x73, 0x74, 0x62, 0x06, 0x70, 0x72,
-	0x6f, 0x74, 0x6f, 0x33,
+	0x0a, 0x0a, 0x70, 0x6f, 0x73, 0x74, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x04, 0x70, 0x6f,
+	0x73, 0x74, 0x22, 0x18, 0x0a, 0x06, 0x50, 0x6f, 0x73, 0x74, 0x49, 0x64, 0x12, 0x0e, 0x0a, 0x02,
+	0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x03, 0x52, 0x02, 0x69, 0x64, 0x22, 0x25, 0x0a, 0x09,
+	0x49, 0x73, 0x53, 0x75, 0x63, 0x63, 0x65, 0x73, 0x73, 0x12, 0x18, 0x0a, 0x07, 0x73, 0x75, 0x63,
+	0x63, 0x65, 0x73, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x08, 0x52, 0x07, 0x73, 0x75, 0x63, 0x63,
+	0x65, 0x73, 0x73, 0x22, 0x68, 0x0a, 0x0b, 0x50, 0x6f, 0x73, 0x74, 0x44, 0x74, 0x6f, 0x4c, 0x69,
+	0x73, 0x74, 0x12, 0x23, 0x0a, 0x05, 0x70, 0x6f, 0x73, 0x74, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28,
+	0x0b, 0x32, 0x0d, 0x2e, 0x70, 0x6f, 0x73, 0x74, 0x2e, 0x50, 0x6f, 0x73, 0x74, 0x44, 0x74, 0x6f,
+	0x52, 0x05, 0x70, 0x6f, 0x73, 0x74, 0x73, 0x12, 0x14, 0x0a, 0x05, 0x74, 0x6f, 0x74, 0x61, 0x6c,
+	0x18, 0x02, 0x20, 0x01, 0x28, 0x03, 0x52, 0x05, 0x74, 0x6f, 0x74, 0x61, 0x6c, 0x12, 0x1e, 0x0a,
+	0x0a, 0x4e, 0x65, 0x78, 0x74, 0x43, 0x75, 0x72, 0x73, 0x6f, 0x72, 0x18, 0x03, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x0a, 0x4e, 0x65, 0x78, 0x74, 0x43, 0x75, 0x72, 0x73, 0x6f, 0x72, 0x22, 0xeb, 0x01,
+	0x0a, 0x07, 0x50, 0x6f, 0x73, 0x74, 0x44, 0x74, 0x6f, 0x12, 0x14, 0x0a, 0x05, 0x54, 0x69, 0x74,
+	0x6c, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x54, 0x69, 0x74, 0x6c, 0x65, 0x12,
+	0x18, 0x0a, 0x07, 0x43, 0x6f, 0x6e, 0x74, 0x65, 0x6e, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x07, 0x43, 0x6f, 0x6e, 0x74, 0x65, 0x6e, 0x74, 0x12, 0x18, 0x0a, 0x07, 0x53, 0x75, 0x6d,
+	0x6d, 0x61, 0x72, 0x79, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x53, 0x75, 0x6d, 0x6d,
+	0x61, 0x72, 0x79, 0x12, 0x14, 0x0a, 0x05, 0x43, 0x6f, 0x76, 0x65, 0x72, 0x18, 0x04, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x05, 0x43, 0x6f, 0x76, 0x65, 0x72, 0x12, 0x1c, 0x0a, 0x09, 0x56, 0x69, 0x65,
+	0x77, 0x43, 0x6f, 0x75, 0x6e, 0x74, 0x18, 0x05, 0x20, 0x01, 0x28, 0x03, 0x52, 0x09, 0x56, 0x69,
+	0x65, 0x77, 0x43, 0x6f, 0x75, 0x6e, 0x74, 0x12, 0x1c, 0x0a, 0x09, 0x4c, 0x69, 0x6b, 0x65, 0x43,
+	0x6f, 0x75, 0x6e, 0x74, 0x18, 0x06, 0x20, 0x01, 0x28, 0x03, 0x52, 0x09, 0x4c, 0x69, 0x6b, 0x65,
+	0x43, 0x6f, 0x75, 0x6e, 0x74, 0x12, 0x16, 0x0a, 0x06, 0x55, 0x73, 0x65, 0x72, 0x49, 0x44, 0x18,
+	0x07, 0x20, 0x01, 0x28, 0x03, 0x52, 0x06, 0x55, 0x73, 0x65, 0x72, 0x49, 0x44, 0x12, 0x0e, 0x0a,
+	0x02, 0x49, 0x44, 0x18, 0x08, 0x20, 0x01, 0x28, 0x03, 0x52, 0x02, 0x49, 0x44, 0x12, 0x1c, 0x0a,
+	0x09, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x64, 0x41, 0x74, 0x18, 0x09, 0x20, 0x01, 0x28, 0x03,
+	0x52, 0x09, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x64, 0x41, 0x74, 0x22, 0xce, 0x02, 0x0a, 0x11,
+	0x50, 0x6f, 0x73, 0x74, 0x44, 0x74, 0x6f, 0x43, 0x6f, 0x6e, 0x64, 0x69, 0x74, 0x69, 0x6f, 0x6e,
+	0x73, 0x12, 0x14, 0x0a, 0x05, 0x54, 0x69, 0x74, 0x6c, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x05, 0x54, 0x69, 0x74, 0x6c, 0x65, 0x12, 0x18, 0x0a, 0x07, 0x43, 0x6f, 0x6e, 0x74, 0x65,
+	0x6e, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x43, 0x6f, 0x6e, 0x74, 0x65, 0x6e,
+	0x74, 0x12, 0x16, 0x0a, 0x06, 0x55, 0x73, 0x65, 0x72, 0x49, 0x44, 0x18, 0x03, 0x20, 0x01, 0x28,
+	0x03, 0x52, 0x06, 0x55, 0x73, 0x65, 0x72, 0x49, 0x44, 0x12, 0x1a, 0x0a, 0x08, 0x50, 0x61, 0x67,
+	0x65, 0x53, 0x69, 0x7a, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x05, 0x52, 0x08, 0x50, 0x61, 0x67,
+	0x65, 0x53, 0x69, 0x7a, 0x65, 0x12, 0x16, 0x0a, 0x06, 0x43, 0x75, 0x72, 0x73, 0x6f, 0x72, 0x18,
+	0x05, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x43, 0x75, 0x72, 0x73, 0x6f, 0x72, 0x12, 0x2b, 0x0a,
+	0x06, 0x53, 0x6f, 0x72, 0x74, 0x42, 0x79, 0x18, 0x06, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x13, 0x2e,
+	0x70, 0x6f, 0x73, 0x74, 0x2e, 0x50, 0x6f, 0x73, 0x74, 0x53, 0x6f, 0x72, 0x74, 0x46, 0x69, 0x65,
+	0x6c, 0x64, 0x52, 0x06, 0x53, 0x6f, 0x72, 0x74, 0x42, 0x79, 0x12, 0x10, 0x0a, 0x03, 0x41, 0x73,
+	0x63, 0x18, 0x07, 0x20, 0x01, 0x28, 0x08, 0x52, 0x03, 0x41, 0x73, 0x63, 0x12, 0x20, 0x0a, 0x0b,
+	0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x64, 0x46, 0x72, 0x6f, 0x6d, 0x18, 0x08, 0x20, 0x01, 0x28,
+	0x03, 0x52, 0x0b, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x64, 0x46, 0x72, 0x6f, 0x6d, 0x12, 0x1c,
+	0x0a, 0x09, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x64, 0x54, 0x6f, 0x18, 0x09, 0x20, 0x01, 0x28,
+	0x03, 0x52, 0x09, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x64, 0x54, 0x6f, 0x12, 0x20, 0x0a, 0x0b,
+	0x53, 0x75, 0x6d, 0x6d, 0x61, 0x72, 0x79, 0x4f, 0x6e, 0x6c, 0x79, 0x18, 0x0a, 0x20, 0x01, 0x28,
+	0x08, 0x52, 0x0b, 0x53, 0x75, 0x6d, 0x6d, 0x61, 0x72, 0x79, 0x4f, 0x6e, 0x6c, 0x79, 0x12, 0x1c,
+	0x0a, 0x09, 0x57, 0x69, 0x74, 0x68, 0x54, 0x6f, 0x74, 0x61, 0x6c, 0x18, 0x0b, 0x20, 0x01, 0x28,
+	0x08, 0x52, 0x09, 0x57, 0x69, 0x74, 0x68, 0x54, 0x6f, 0x74, 0x61, 0x6c, 0x2a, 0x4e, 0x0a, 0x0d,
+	0x50, 0x6f, 0x73, 0x74, 0x53, 0x6f, 0x72, 0x74, 0x46, 0x69, 0x65, 0x6c, 0x64, 0x12, 0x13, 0x0a,
+	0x0f, 0x53, 0x4f, 0x52, 0x54, 0x5f, 0x43, 0x52, 0x45, 0x41, 0x54, 0x45, 0x44, 0x5f, 0x41, 0x54,
+	0x10, 0x00, 0x12, 0x13, 0x0a, 0x0f, 0x53, 0x4f, 0x52, 0x54, 0x5f, 0x56, 0x49, 0x45, 0x57, 0x5f,
+	0x43, 0x4f, 0x55, 0x4e, 0x54, 0x10, 0x01, 0x12, 0x13, 0x0a, 0x0f, 0x53, 0x4f, 0x52, 0x54, 0x5f,
+	0x4c, 0x49, 0x4b, 0x45, 0x5f, 0x43, 0x4f, 0x55, 0x4e, 0x54, 0x10, 0x02, 0x32, 0xff, 0x01, 0x0a,
+	0x0b, 0x50, 0x6f, 0x73, 0x74, 0x53, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x12, 0x29, 0x0a, 0x0a,
+	0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x50, 0x6f, 0x73, 0x74, 0x12, 0x0d, 0x2e, 0x70, 0x6f, 0x73,
+	0x74, 0x2e, 0x50, 0x6f, 0x73, 0x74, 0x44, 0x74, 0x6f, 0x1a, 0x0c, 0x2e, 0x70, 0x6f, 0x73, 0x74,
+	0x2e, 0x50, 0x6f, 0x73, 0x74, 0x49, 0x64, 0x12, 0x26, 0x0a, 0x07, 0x47, 0x65, 0x74, 0x50, 0x6f,
+	0x73, 0x74, 0x12, 0x0c, 0x2e, 0x70, 0x6f, 0x73, 0x74, 0x2e, 0x50, 0x6f, 0x73, 0x74, 0x49, 0x64,
+	0x1a, 0x0d, 0x2e, 0x70, 0x6f, 0x73, 0x74, 0x2e, 0x50, 0x6f, 0x73, 0x74, 0x44, 0x74, 0x6f, 0x12,
+	0x42, 0x0a, 0x14, 0x47, 0x65, 0x74, 0x50, 0x6f, 0x73, 0x74, 0x73, 0x42, 0x79, 0x43, 0x6f, 0x6e,
+	0x64, 0x69, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x12, 0x17, 0x2e, 0x70, 0x6f, 0x73, 0x74, 0x2e, 0x50,
+	0x6f, 0x73, 0x74, 0x44, 0x74, 0x6f, 0x43, 0x6f, 0x6e, 0x64, 0x69, 0x74, 0x69, 0x6f, 0x6e, 0x73,
+	0x1a, 0x11, 0x2e, 0x70, 0x6f, 0x73, 0x74, 0x2e, 0x50, 0x6f, 0x73, 0x74, 0x44, 0x74, 0x6f, 0x4c,
+	0x69, 0x73, 0x74, 0x12, 0x2b, 0x0a, 0x0a, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x50, 0x6f, 0x73,
+	0x74, 0x12, 0x0c, 0x2e, 0x70, 0x6f, 0x73, 0x74, 0x2e, 0x50, 0x6f, 0x73, 0x74, 0x49, 0x64, 0x1a,
+	0x0f, 0x2e, 0x70, 0x6f, 0x73, 0x74, 0x2e, 0x49, 0x73, 0x53, 0x75, 0x63, 0x63, 0x65, 0x73, 0x73,
+	0x12, 0x2c, 0x0a, 0x0a, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x50, 0x6f, 0x73, 0x74, 0x12, 0x0d,
+	0x2e, 0x70, 0x6f, 0x73, 0x74, 0x2e, 0x50, 0x6f, 0x73, 0x74, 0x44, 0x74, 0x6f, 0x1a, 0x0f, 0x2e,
+	0x70, 0x6f, 0x73, 0x74, 0x2e, 0x49, 0x73, 0x53, 0x75, 0x63, 0x63, 0x65, 0x73, 0x73, 0x42, 0x08,
+	0x5a, 0x06, 0x2e, 0x2f, 0x70, 0x6f, 0x73, 0x74, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
 }
 
 var (
@@ -398,31 +569,34 @@ func file_post_proto_rawDescGZIP() []byte {
 	return file_post_proto_rawDescData
 }
 
+var file_post_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
 var file_post_proto_msgTypes = make([]protoimpl.MessageInfo, 5)
 var file_post_proto_goTypes = []interface{}{
-	(*PostId)(nil),            // 0: PostId
-	(*IsSuccess)(nil),         // 1: IsSuccess
-	(*PostDtoList)(nil),       // 2: PostDtoList
-	(*PostDto)(nil),           // 3: PostDto
-	(*PostDtoConditions)(nil), // 4: PostDtoConditions
+	(PostSortField)(0),        // 0: post.PostSortField
+	(*PostId)(nil),            // 1: post.PostId
+	(*IsSuccess)(nil),         // 2: post.IsSuccess
+	(*PostDtoList)(nil),       // 3: post.PostDtoList
+	(*PostDto)(nil),           // 4: post.PostDto
+	(*PostDtoConditions)(nil), // 5: post.PostDtoConditions
 }
 var file_post_proto_depIdxs = []int32{
-	3, // 0: PostDtoList.posts:type_name -> PostDto
-	3, // 1: PostService.CreatePost:input_type -> PostDto
-	0, // 2: PostService.GetPost:input_type -> PostId
-	4, // 3: PostService.GetPostsByConditions:input_type -> PostDtoConditions
-	0, // 4: PostService.DeletePost:input_type -> PostId
-	3, // 5: PostService.UpdatePost:input_type -> PostDto
-	1, // 6: PostService.CreatePost:output_type -> IsSuccess
-	3, // 7: PostService.GetPost:output_type -> PostDto
-	2, // 8: PostService.GetPostsByConditions:output_type -> PostDtoList
-	1, // 9: PostService.DeletePost:output_type -> IsSuccess
-	1, // 10: PostService.UpdatePost:output_type -> IsSuccess
-	6, // [6:11] is the sub-list for method output_type
-	1, // [1:6] is the sub-list for method input_type
-	1, // [1:1] is the sub-list for extension type_name
-	1, // [1:1] is the sub-list for extension extendee
-	0, // [0:1] is the sub-list for field type_name
+	4, // 0: post.PostDtoList.posts:type_name -> post.PostDto
+	0, // 1: post.PostDtoConditions.SortBy:type_name -> post.PostSortField
+	4, // 2: post.PostService.CreatePost:input_type -> post.PostDto
+	1, // 3: post.PostService.GetPost:input_type -> post.PostId
+	5, // 4: post.PostService.GetPostsByConditions:input_type -> post.PostDtoConditions
+	1, // 5: post.PostService.DeletePost:input_type -> post.PostId
+	4, // 6: post.PostService.UpdatePost:input_type -> post.PostDto
+	1, // 7: post.PostService.CreatePost:output_type -> post.PostId
+	4, // 8: post.PostService.GetPost:output_type -> post.PostDto
+	3, // 9: post.PostService.GetPostsByConditions:output_type -> post.PostDtoList
+	2, // 10: post.PostService.DeletePost:output_type -> post.IsSuccess
+	2, // 11: post.PostService.UpdatePost:output_type -> post.IsSuccess
+	7, // [7:12] is the sub-list for method output_type
+	2, // [2:7] is the sub-list for method input_type
+	2, // [2:2] is the sub-list for extension type_name
+	2, // [2:2] is the sub-list for extension extendee
+	0, // [0:2] is the sub-list for field type_name
 }
 
 func init() { file_post_proto_init() }
@@ -497,13 +671,14 @@ func file_post_proto_init() {
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: file_post_proto_rawDesc,
-			NumEnums:      0,
+			NumEnums:      1,
 			NumMessages:   5,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
 		GoTypes:           file_post_proto_goTypes,
 		DependencyIndexes: file_post_proto_depIdxs,
+		EnumInfos:         file_post_proto_enumTypes,
 		MessageInfos:      file_post_proto_msgTypes,
 	}.Build()
 	File_post_proto = out.File
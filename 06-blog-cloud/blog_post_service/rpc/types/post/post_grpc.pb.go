@@ -19,11 +19,11 @@ import (
 const _ = grpc.SupportPackageIsVersion9
 
 const (
-	PostService_CreatePost_FullMethodName           = "/PostService/CreatePost"
-	PostService_GetPost_FullMethodName              = "/PostService/GetPost"
-	PostService_GetPostsByConditions_FullMethodName = "/PostService/GetPostsByConditions"
-	PostService_DeletePost_FullMethodName           = "/PostService/DeletePost"
-	PostService_UpdatePost_FullMethodName           = "/PostService/UpdatePost"
+	PostService_CreatePost_FullMethodName           = "/post.PostService/CreatePost"
+	PostService_GetPost_FullMethodName              = "/post.PostService/GetPost"
+	PostService_GetPostsByConditions_FullMethodName = "/post.PostService/GetPostsByConditions"
+	PostService_DeletePost_FullMethodName           = "/post.PostService/DeletePost"
+	PostService_UpdatePost_FullMethodName           = "/post.PostService/UpdatePost"
 )
 
 // PostServiceClient is the client API for PostService service.
@@ -31,7 +31,7 @@ const (
 // For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
 type PostServiceClient interface {
 	// 新增文章
-	CreatePost(ctx context.Context, in *PostDto, opts ...grpc.CallOption) (*IsSuccess, error)
+	CreatePost(ctx context.Context, in *PostDto, opts ...grpc.CallOption) (*PostId, error)
 	// 获取文章
 	GetPost(ctx context.Context, in *PostId, opts ...grpc.CallOption) (*PostDto, error)
 	// 多条件查询文章 不传入就是查询所有
@@ -50,9 +50,9 @@ func NewPostServiceClient(cc grpc.ClientConnInterface) PostServiceClient {
 	return &postServiceClient{cc}
 }
 
-func (c *postServiceClient) CreatePost(ctx context.Context, in *PostDto, opts ...grpc.CallOption) (*IsSuccess, error) {
+func (c *postServiceClient) CreatePost(ctx context.Context, in *PostDto, opts ...grpc.CallOption) (*PostId, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
-	out := new(IsSuccess)
+	out := new(PostId)
 	err := c.cc.Invoke(ctx, PostService_CreatePost_FullMethodName, in, out, cOpts...)
 	if err != nil {
 		return nil, err
@@ -105,7 +105,7 @@ func (c *postServiceClient) UpdatePost(ctx context.Context, in *PostDto, opts ..
 // for forward compatibility.
 type PostServiceServer interface {
 	// 新增文章
-	CreatePost(context.Context, *PostDto) (*IsSuccess, error)
+	CreatePost(context.Context, *PostDto) (*PostId, error)
 	// 获取文章
 	GetPost(context.Context, *PostId) (*PostDto, error)
 	// 多条件查询文章 不传入就是查询所有
@@ -124,7 +124,7 @@ type PostServiceServer interface {
 // pointer dereference when methods are called.
 type UnimplementedPostServiceServer struct{}
 
-func (UnimplementedPostServiceServer) CreatePost(context.Context, *PostDto) (*IsSuccess, error) {
+func (UnimplementedPostServiceServer) CreatePost(context.Context, *PostDto) (*PostId, error) {
 	return nil, status.Error(codes.Unimplemented, "method CreatePost not implemented")
 }
 func (UnimplementedPostServiceServer) GetPost(context.Context, *PostId) (*PostDto, error) {
@@ -254,7 +254,7 @@ func _PostService_UpdatePost_Handler(srv interface{}, ctx context.Context, dec f
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
 var PostService_ServiceDesc = grpc.ServiceDesc{
-	ServiceName: "PostService",
+	ServiceName: "post.PostService",
 	HandlerType: (*PostServiceServer)(nil),
 	Methods: []grpc.MethodDesc{
 		{
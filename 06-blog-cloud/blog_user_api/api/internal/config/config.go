@@ -4,6 +4,7 @@
 package config
 
 import (
+	"github.com/zeromicro/go-zero/core/discov"
 	"github.com/zeromicro/go-zero/rest"
 	"github.com/zeromicro/go-zero/zrpc"
 )
@@ -15,4 +16,6 @@ type Config struct {
 		AccessExpire int64
 	}
 	UserRpc zrpc.RpcClientConf
+	// Registry 注册到 etcd 供网关发现 不配置时不注册
+	Registry discov.EtcdConf `json:",optional"`
 }
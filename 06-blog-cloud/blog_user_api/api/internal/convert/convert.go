@@ -0,0 +1,5 @@
+// Package convert UserService 的 proto 类型与 API 类型之间的转换
+// 转换函数由 gen 生成 两边字段不一致时生成失败 修改 user.proto 或 userapi.api 后要重新生成
+package convert
+
+//go:generate go run ./gen
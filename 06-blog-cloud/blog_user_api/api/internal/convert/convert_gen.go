@@ -0,0 +1,41 @@
+// Code generated by blog-common/convgen. DO NOT EDIT.
+// 字段变化后执行 go generate 重新生成
+
+package convert
+
+import (
+	"api/internal/types"
+	"blog_user_service/rpc/types/user"
+)
+
+// ToUser user.UserInfoVo 转换为 types.User
+func ToUser(in *user.UserInfoVo) *types.User {
+	if in == nil {
+		return nil
+	}
+	out := &types.User{}
+	out.Id = in.Id
+	out.Name = in.Name
+	out.Email = in.Email
+	out.Age = int(in.Age)
+	out.Description = in.Description
+	out.Nickname = in.Nickname
+	out.Role = in.Role
+	return out
+}
+
+// ToUpdateUserDto types.UpdateUserVo 转换为 user.UpdateUserDto
+func ToUpdateUserDto(in *types.UpdateUserVo) *user.UpdateUserDto {
+	if in == nil {
+		return nil
+	}
+	out := &user.UpdateUserDto{}
+	out.Id = in.Id
+	out.Name = in.Name
+	out.Email = in.Email
+	out.Password = in.Password
+	out.Age = int32(in.Age)
+	out.Description = in.Description
+	out.Nickname = in.Nickname
+	return out
+}
@@ -0,0 +1,103 @@
+package convert
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"api/internal/types"
+	"blog-common/auth"
+	"blog_user_service/rpc/types/user"
+
+	"google.golang.org/protobuf/proto"
+)
+
+func TestToUser(t *testing.T) {
+	in := &user.UserInfoVo{
+		Id:          7,
+		Name:        "alice",
+		Email:       "alice@example.com",
+		Age:         30,
+		Description: "hello",
+		Nickname:    "Alice",
+		Role:        auth.RoleAdmin,
+	}
+	got := ToUser(in)
+	want := types.User{
+		Id:          7,
+		Name:        "alice",
+		Email:       "alice@example.com",
+		Age:         30,
+		Description: "hello",
+		Nickname:    "Alice",
+		Role:        auth.RoleAdmin,
+	}
+	if *got != want {
+		t.Errorf("got %+v, want %+v", *got, want)
+	}
+}
+
+// TestUpdateRoundTrip UpdateUserVo 经 proto 转换后除密码和角色外与查询结果一致
+func TestUpdateRoundTrip(t *testing.T) {
+	in := &types.UpdateUserVo{
+		Id:          7,
+		Name:        "alice",
+		Password:    "new-pass",
+		Email:       "alice@example.com",
+		Age:         30,
+		Description: "hello",
+		Nickname:    "Alice",
+		Role:        auth.RoleAdmin,
+	}
+	dto := ToUpdateUserDto(in)
+	want := &user.UpdateUserDto{
+		Id:          7,
+		Name:        "alice",
+		Email:       "alice@example.com",
+		Password:    "new-pass",
+		Age:         30,
+		Description: "hello",
+		Nickname:    "Alice",
+	}
+	if !proto.Equal(dto, want) {
+		t.Fatalf("got %v, want %v", dto, want)
+	}
+
+	// 模拟 rpc 更新后返回的用户信息
+	got := ToUser(&user.UserInfoVo{
+		Id:          dto.Id,
+		Name:        dto.Name,
+		Email:       dto.Email,
+		Age:         dto.Age,
+		Description: dto.Description,
+		Nickname:    dto.Nickname,
+	})
+	back := types.User{
+		Id:          in.Id,
+		Name:        in.Name,
+		Email:       in.Email,
+		Age:         in.Age,
+		Description: in.Description,
+		Nickname:    in.Nickname,
+	}
+	if *got != back {
+		t.Errorf("got %+v, want %+v", *got, back)
+	}
+}
+
+// TestUserHasNoPassword 返回给前端的 User 中没有密码
+func TestUserHasNoPassword(t *testing.T) {
+	b, err := json.Marshal(ToUser(&user.UserInfoVo{Id: 1, Name: "alice"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(strings.ToLower(string(b)), "password") {
+		t.Errorf("User JSON contains password: %s", b)
+	}
+}
+
+func TestNil(t *testing.T) {
+	if ToUser(nil) != nil || ToUpdateUserDto(nil) != nil {
+		t.Error("nil input should convert to nil")
+	}
+}
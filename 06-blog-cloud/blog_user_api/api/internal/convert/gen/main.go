@@ -0,0 +1,32 @@
+// 生成 convert_gen.go 在 internal/convert 目录执行 go generate
+package main
+
+import (
+	"log"
+
+	"api/internal/types"
+	"blog-common/convgen"
+	"blog_user_service/rpc/types/user"
+)
+
+// pairs 生成的转换函数 main_test.go 用它检查 convert_gen.go 是否已重新生成
+var pairs = []convgen.Pair{
+	{
+		Func: "ToUser",
+		From: user.UserInfoVo{},
+		To:   types.User{},
+	},
+	{
+		Func: "ToUpdateUserDto",
+		From: types.UpdateUserVo{},
+		To:   user.UpdateUserDto{},
+		// 角色不能通过 UpdateUserInfo 修改 由 logic 拒绝
+		Skip: []string{"Role"},
+	},
+}
+
+func main() {
+	if err := convgen.WriteFile("convert_gen.go", "convert", pairs...); err != nil {
+		log.Fatal(err)
+	}
+}
@@ -0,0 +1,171 @@
+package fake
+
+import (
+	"context"
+	"sync"
+
+	"blog-common/auth"
+	"blog-common/errorx"
+	"blog_user_service/rpc/types/user"
+	"blog_user_service/rpc/userservice"
+
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/proto"
+)
+
+// UserService 进程内的 userservice.UserService 数据保存在内存中
+// 返回的错误码与 UserService 一致 测试时通过 svc.NewServiceContextWithUserRpc 注入
+type UserService struct {
+	mu     sync.Mutex
+	nextID int64
+	users  map[int64]*record
+}
+
+// record 保存的用户 UserInfoVo 不包含密码 单独保存
+type record struct {
+	info     *user.UserInfoVo
+	password string
+}
+
+var _ userservice.UserService = (*UserService)(nil)
+
+// NewUserService 创建空的 UserService
+func NewUserService() *UserService {
+	return &UserService{users: make(map[int64]*record)}
+}
+
+// Add 直接添加用户 返回用户ID 用于准备测试数据 密码按明文保存 角色为空时为普通用户
+func (s *UserService) Add(u *user.UserInfoVo, password string) int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.add(u, password).info.Id
+}
+
+// Register 注册
+func (s *UserService) Register(ctx context.Context, in *userservice.RegisterDto, opts ...grpc.CallOption) (*userservice.UserInfoVo, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.findByName(in.Name) != nil {
+		return nil, errorx.New(errorx.CodeUserExists, "用户名已存在")
+	}
+	r := s.add(&user.UserInfoVo{Name: in.Name, Email: in.Email}, in.Password)
+	return clone(r.info), nil
+}
+
+// Login 登录 令牌由网关签发 这里只返回空令牌
+func (s *UserService) Login(ctx context.Context, in *userservice.LoginDto, opts ...grpc.CallOption) (*userservice.LoginResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	r := s.findByName(in.Username)
+	if r == nil || r.password != in.Password {
+		return nil, errorx.New(errorx.CodeInvalidCredentials, "用户名或密码错误")
+	}
+	return &user.LoginResponse{}, nil
+}
+
+// ListUser 分页查询用户
+func (s *UserService) ListUser(ctx context.Context, in *userservice.PageInfoDto, opts ...grpc.CallOption) (*userservice.UserInfoVoList, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	pageNumber, pageSize := int64(in.PageNumber), int64(in.PageSize)
+	if pageNumber == 0 {
+		pageNumber = 1
+	}
+	if pageSize == 0 || pageSize > 100 {
+		pageSize = 10
+	}
+	list := &user.UserInfoVoList{Total: int64(len(s.users))}
+	start := (pageNumber - 1) * pageSize
+	for id := start + 1; id <= s.nextID && id <= start+pageSize; id++ {
+		if r, ok := s.users[id]; ok {
+			list.UserInfoVoList = append(list.UserInfoVoList, clone(r.info))
+		}
+	}
+	return list, nil
+}
+
+// GetUserByUsername 根据用户名获取用户信息
+func (s *UserService) GetUserByUsername(ctx context.Context, in *userservice.UsernameDto, opts ...grpc.CallOption) (*userservice.UserInfoVo, error) {
+	if in.Username == "" {
+		return nil, errorx.New(errorx.CodeInvalidParam, "用户名不能为空")
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	r := s.findByName(in.Username)
+	if r == nil {
+		return nil, errorx.New(errorx.CodeUserNotFound, "用户不存在")
+	}
+	return clone(r.info), nil
+}
+
+// GetUserById 根据id查询用户
+func (s *UserService) GetUserById(ctx context.Context, in *userservice.IdDto, opts ...grpc.CallOption) (*userservice.UserInfoVo, error) {
+	if in.Id <= 0 {
+		return nil, errorx.New(errorx.CodeInvalidParam, "无效的用户ID")
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	r, ok := s.users[in.Id]
+	if !ok {
+		return nil, errorx.New(errorx.CodeUserNotFound, "用户不存在")
+	}
+	return clone(r.info), nil
+}
+
+// UpdateUserInfo 更新用户信息 只更新非空字段
+func (s *UserService) UpdateUserInfo(ctx context.Context, in *userservice.UpdateUserDto, opts ...grpc.CallOption) (*userservice.IsSuccess, error) {
+	if in.Id <= 0 {
+		return nil, errorx.New(errorx.CodeInvalidParam, "无效的用户ID")
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	r, ok := s.users[in.Id]
+	if !ok {
+		return nil, errorx.New(errorx.CodeUserNotFound, "用户不存在")
+	}
+	u := r.info
+	if in.Name != "" {
+		u.Name = in.Name
+	}
+	if in.Email != "" {
+		u.Email = in.Email
+	}
+	if in.Password != "" {
+		r.password = in.Password
+	}
+	if in.Age != 0 {
+		u.Age = in.Age
+	}
+	if in.Description != "" {
+		u.Description = in.Description
+	}
+	if in.Nickname != "" {
+		u.Nickname = in.Nickname
+	}
+	return &user.IsSuccess{Success: true}, nil
+}
+
+func (s *UserService) add(u *user.UserInfoVo, password string) *record {
+	s.nextID++
+	u = clone(u)
+	u.Id = s.nextID
+	if u.Role == "" {
+		u.Role = auth.RoleUser
+	}
+	r := &record{info: u, password: password}
+	s.users[u.Id] = r
+	return r
+}
+
+func (s *UserService) findByName(name string) *record {
+	for _, r := range s.users {
+		if r.info.Name == name {
+			return r
+		}
+	}
+	return nil
+}
+
+func clone(u *user.UserInfoVo) *user.UserInfoVo {
+	return proto.Clone(u).(*user.UserInfoVo)
+}
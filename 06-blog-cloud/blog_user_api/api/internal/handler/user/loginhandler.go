@@ -9,6 +9,8 @@ import (
 	"api/internal/logic/user"
 	"api/internal/svc"
 	"api/internal/types"
+	"blog-common/errorx"
+
 	"github.com/zeromicro/go-zero/rest/httpx"
 )
 
@@ -16,13 +18,14 @@ func LoginHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		var req types.LoginDto
 		if err := httpx.Parse(r, &req); err != nil {
-			httpx.ErrorCtx(r.Context(), w, err)
+			httpx.ErrorCtx(r.Context(), w, errorx.NewParamError(err))
 			return
 		}
 
 		l := user.NewLoginLogic(r.Context(), svcCtx)
 		resp, err := l.Login(&req)
 		if err != nil {
+			// 用户名或密码错误时返回401 网关据此统计登录失败次数
 			httpx.ErrorCtx(r.Context(), w, err)
 		} else {
 			httpx.OkJsonCtx(r.Context(), w, resp)
@@ -9,6 +9,8 @@ import (
 	"api/internal/logic/user"
 	"api/internal/svc"
 	"api/internal/types"
+	"blog-common/errorx"
+
 	"github.com/zeromicro/go-zero/rest/httpx"
 )
 
@@ -16,7 +18,7 @@ func PathExampleHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		var req types.QueryPathDto
 		if err := httpx.Parse(r, &req); err != nil {
-			httpx.ErrorCtx(r.Context(), w, err)
+			httpx.ErrorCtx(r.Context(), w, errorx.NewParamError(err))
 			return
 		}
 
@@ -9,6 +9,7 @@ import (
 	"api/internal/logic/user"
 	"api/internal/svc"
 	"api/internal/types"
+	"blog-common/errorx"
 
 	"github.com/zeromicro/go-zero/rest/httpx"
 )
@@ -17,7 +18,7 @@ func RegisterHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		var req types.RegisterDto
 		if err := httpx.Parse(r, &req); err != nil {
-			httpx.ErrorCtx(r.Context(), w, err)
+			httpx.ErrorCtx(r.Context(), w, errorx.NewParamError(err))
 			return
 		}
 
@@ -4,10 +4,12 @@
 package user
 
 import (
+	"blog_user_service/rpc/types/user"
 	"context"
 
 	"api/internal/svc"
 	"api/internal/types"
+	"blog-common/errorx"
 
 	"github.com/zeromicro/go-zero/core/logx"
 )
@@ -26,8 +28,32 @@ func NewLoginLogic(ctx context.Context, svcCtx *svc.ServiceContext) *LoginLogic
 	}
 }
 
-func (l *LoginLogic) Login(req *types.LoginDto) (resp *types.LoginVo, err error) {
-	// todo: add your logic here and delete this line
+// Login 校验用户名和密码 返回用户信息 令牌由网关签发
+func (l *LoginLogic) Login(req *types.LoginDto) (*types.LoginVo, error) {
+	_, err := l.svcCtx.UserRpc.Login(l.ctx, &user.LoginDto{
+		Username: req.Username,
+		Password: req.Password,
+	})
+	if err != nil {
+		// 用户名或密码错误时 UserService 返回 CodeInvalidCredentials 对应 HTTP 401
+		e := errorx.FromError(err)
+		if e.Code == errorx.CodeInvalidCredentials || e.Code == errorx.CodeUnauthorized {
+			l.Logger.Infof("Login failed: Username=%s", req.Username)
+			return nil, errorx.New(errorx.CodeInvalidCredentials, "用户名或密码错误")
+		}
+		l.Logger.Errorf("RPC Login failed: %v", err)
+		return nil, e
+	}
+
+	userInfo, err := l.svcCtx.UserRpc.GetUserByUsername(l.ctx, &user.UsernameDto{Username: req.Username})
+	if err != nil {
+		l.Logger.Errorf("RPC GetUserByUsername failed: %v", err)
+		return nil, errorx.FromError(err)
+	}
 
-	return
+	return &types.LoginVo{
+		Id:    int64(userInfo.Id),
+		Name:  userInfo.Name,
+		Email: userInfo.Email,
+	}, nil
 }
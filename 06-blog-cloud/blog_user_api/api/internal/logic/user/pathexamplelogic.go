@@ -26,8 +26,7 @@ func NewPathExampleLogic(ctx context.Context, svcCtx *svc.ServiceContext) *PathE
 	}
 }
 
+// PathExample 查询指定 id 的用户信息
 func (l *PathExampleLogic) PathExample(req *types.QueryPathDto) (resp *types.QueryVo, err error) {
-	// todo: add your logic here and delete this line
-
-	return
+	return getUser(l.ctx, l.svcCtx, req.Id)
 }
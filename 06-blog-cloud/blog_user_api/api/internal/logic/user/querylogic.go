@@ -4,10 +4,14 @@
 package user
 
 import (
+	"blog_user_service/rpc/types/user"
 	"context"
 
+	"api/internal/convert"
 	"api/internal/svc"
 	"api/internal/types"
+	"blog-common/auth"
+	"blog-common/errorx"
 
 	"github.com/zeromicro/go-zero/core/logx"
 )
@@ -26,8 +30,28 @@ func NewQueryLogic(ctx context.Context, svcCtx *svc.ServiceContext) *QueryLogic
 	}
 }
 
+// Query 查询用户信息 不传 id 时查询自己
 func (l *QueryLogic) Query(req *types.QueryDto) (resp *types.QueryVo, err error) {
-	// todo: add your logic here and delete this line
+	id := req.Id
+	if id == 0 {
+		caller, ok := auth.CallerFrom(l.ctx)
+		if !ok {
+			return nil, errorx.New(errorx.CodeUnauthorized, "未登录")
+		}
+		id = caller.UserID
+	}
+	return getUser(l.ctx, l.svcCtx, id)
+}
 
-	return
+// getUser 调用 UserService 查询用户 不返回密码
+func getUser(ctx context.Context, svcCtx *svc.ServiceContext, id int64) (*types.QueryVo, error) {
+	if id <= 0 {
+		return nil, errorx.New(errorx.CodeInvalidParam, "无效的用户ID")
+	}
+	userInfo, err := svcCtx.UserRpc.GetUserById(ctx, &user.IdDto{Id: id})
+	if err != nil {
+		logx.WithContext(ctx).Errorf("RPC GetUserById failed: %v", err)
+		return nil, errorx.FromError(err)
+	}
+	return &types.QueryVo{User: *convert.ToUser(userInfo)}, nil
 }
@@ -0,0 +1,89 @@
+package user
+
+import (
+	"context"
+	"testing"
+
+	"api/internal/config"
+	"api/internal/fake"
+	"api/internal/svc"
+	"api/internal/types"
+	"blog-common/auth"
+	"blog-common/errorx"
+	"blog_user_service/rpc/types/user"
+)
+
+// newTestServiceContext 使用进程内的 fake.UserService 预置普通用户 alice、bob 和管理员 admin
+func newTestServiceContext(t *testing.T) (*svc.ServiceContext, *fake.UserService, map[string]int64) {
+	t.Helper()
+	users := fake.NewUserService()
+	ids := map[string]int64{
+		"alice": users.Add(&user.UserInfoVo{Name: "alice", Email: "alice@example.com", Nickname: "Alice"}, "alice-pass"),
+		"bob":   users.Add(&user.UserInfoVo{Name: "bob", Email: "bob@example.com", Age: 30}, "bob-pass"),
+		"admin": users.Add(&user.UserInfoVo{Name: "admin", Role: auth.RoleAdmin}, "admin-pass"),
+	}
+	var c config.Config
+	c.Auth.AccessSecret = "test-secret"
+	return svc.NewServiceContextWithUserRpc(c, users), users, ids
+}
+
+// withCaller 模拟 AuthInterceptorMiddleware 校验令牌后的 ctx userID 为 0 时表示未登录
+func withCaller(userID int64) context.Context {
+	if userID == 0 {
+		return context.Background()
+	}
+	return auth.WithCaller(context.Background(), &auth.Caller{UserID: userID})
+}
+
+// errorCode 错误对应的业务错误码 nil 为 CodeOK
+func errorCode(err error) int {
+	if err == nil {
+		return errorx.CodeOK
+	}
+	return errorx.FromError(err).Code
+}
+
+func TestQuery(t *testing.T) {
+	svcCtx, _, ids := newTestServiceContext(t)
+
+	tests := []struct {
+		name     string
+		caller   string
+		id       int64
+		wantCode int
+		wantName string
+	}{
+		{name: "self", caller: "alice", wantName: "alice"},
+		{name: "other user", caller: "alice", id: ids["bob"], wantName: "bob"},
+		{name: "not logged in", wantCode: errorx.CodeUnauthorized},
+		{name: "not found", caller: "alice", id: 999, wantCode: errorx.CodeUserNotFound},
+		{name: "invalid id", caller: "alice", id: -1, wantCode: errorx.CodeInvalidParam},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := NewQueryLogic(withCaller(ids[tt.caller]), svcCtx).Query(&types.QueryDto{Id: tt.id})
+			if code := errorCode(err); code != tt.wantCode {
+				t.Fatalf("code = %d (%v), want %d", code, err, tt.wantCode)
+			}
+			if tt.wantCode != errorx.CodeOK {
+				return
+			}
+			if resp.User.Name != tt.wantName || resp.User.Id != ids[tt.wantName] {
+				t.Errorf("user = %+v, want %s", resp.User, tt.wantName)
+			}
+		})
+	}
+}
+
+func TestQueryConvertsAllFields(t *testing.T) {
+	svcCtx, _, ids := newTestServiceContext(t)
+
+	resp, err := NewQueryLogic(withCaller(ids["alice"]), svcCtx).Query(&types.QueryDto{Id: ids["bob"]})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := types.User{Id: ids["bob"], Name: "bob", Email: "bob@example.com", Age: 30, Role: auth.RoleUser}
+	if resp.User != want {
+		t.Errorf("user = %+v, want %+v", resp.User, want)
+	}
+}
@@ -6,10 +6,10 @@ package user
 import (
 	"blog_user_service/rpc/types/user"
 	"context"
-	"errors"
 
 	"api/internal/svc"
 	"api/internal/types"
+	"blog-common/errorx"
 
 	"github.com/zeromicro/go-zero/core/logx"
 )
@@ -31,6 +31,9 @@ func NewRegisterLogic(ctx context.Context, svcCtx *svc.ServiceContext) *Register
 func (l *RegisterLogic) Register(req *types.RegisterDto) (*types.RegisterResponse, error) {
 	// 记录请求信息
 	l.Logger.Infof("Register request received: Name=%s, Email=%s", req.Name, req.Email)
+	if req.Name == "" || req.Password == "" {
+		return nil, errorx.New(errorx.CodeInvalidParam, "用户名和密码不能为空")
+	}
 
 	// 创建RPC请求对象
 	rpcReq := &user.RegisterDto{
@@ -44,13 +47,13 @@ func (l *RegisterLogic) Register(req *types.RegisterDto) (*types.RegisterRespons
 	userInfo, err := l.svcCtx.UserRpc.Register(l.ctx, rpcReq)
 	if err != nil {
 		l.Logger.Errorf("RPC Register failed: %v", err)
-		return nil, err
+		return nil, errorx.FromError(err)
 	}
 
 	// 检查RPC响应
 	if userInfo == nil {
 		l.Logger.Error("RPC returned nil userInfo")
-		return nil, errors.New("failed to register user")
+		return nil, errorx.New(errorx.CodeInternal, "注册失败")
 	}
 
 	l.Logger.Infof("RPC Register successful, received userInfo: Id=%d, Name=%s, Email=%s",
@@ -58,7 +61,7 @@ func (l *RegisterLogic) Register(req *types.RegisterDto) (*types.RegisterRespons
 
 	// 创建RegisterResponse响应对象
 	resp := &types.RegisterResponse{
-		Id:    int64(userInfo.Id),
+		Id:    userInfo.Id,
 		Name:  userInfo.Name,
 		Email: userInfo.Email,
 	}
@@ -4,10 +4,14 @@
 package user
 
 import (
+	"blog_user_service/rpc/types/user"
 	"context"
 
+	"api/internal/convert"
 	"api/internal/svc"
 	"api/internal/types"
+	"blog-common/auth"
+	"blog-common/errorx"
 
 	"github.com/zeromicro/go-zero/core/logx"
 )
@@ -26,8 +30,56 @@ func NewUpdateLogic(ctx context.Context, svcCtx *svc.ServiceContext) *UpdateLogi
 	}
 }
 
+// Update 更新用户信息 不传 id 时更新自己 修改其他用户需要管理员角色
 func (l *UpdateLogic) Update(req *types.UpdateUserVo) error {
-	// todo: add your logic here and delete this line
+	caller, ok := auth.CallerFrom(l.ctx)
+	if !ok {
+		return errorx.New(errorx.CodeUnauthorized, "未登录")
+	}
+	id := req.Id
+	if id == 0 {
+		id = caller.UserID
+	}
+	if id < 0 {
+		return errorx.New(errorx.CodeInvalidParam, "无效的用户ID")
+	}
+	if id != caller.UserID {
+		admin, err := l.isAdmin(caller.UserID)
+		if err != nil {
+			return err
+		}
+		if !admin {
+			l.Logger.Infof("User %d tried to update user %d", caller.UserID, id)
+			return errorx.New(errorx.CodeForbidden, "只能修改自己的信息")
+		}
+	}
+	if req.Role != "" {
+		return errorx.New(errorx.CodeInvalidParam, "不能修改角色")
+	}
+	if req.Age < 0 || req.Age > 150 {
+		return errorx.New(errorx.CodeInvalidParam, "无效的年龄")
+	}
 
+	in := convert.ToUpdateUserDto(req)
+	in.Id = id
+	resp, err := l.svcCtx.UserRpc.UpdateUserInfo(l.ctx, in)
+	if err != nil {
+		l.Logger.Errorf("RPC UpdateUserInfo failed: %v", err)
+		return errorx.FromError(err)
+	}
+	if !resp.Success {
+		return errorx.New(errorx.CodeInternal, "更新用户信息失败")
+	}
+	l.Logger.Infof("User %d updated user %d", caller.UserID, id)
 	return nil
 }
+
+// isAdmin 查询用户是否为管理员 令牌中没有角色 以 UserService 中的为准
+func (l *UpdateLogic) isAdmin(userID int64) (bool, error) {
+	userInfo, err := l.svcCtx.UserRpc.GetUserById(l.ctx, &user.IdDto{Id: userID})
+	if err != nil {
+		l.Logger.Errorf("RPC GetUserById failed: %v", err)
+		return false, errorx.FromError(err)
+	}
+	return userInfo.Role == auth.RoleAdmin, nil
+}
@@ -0,0 +1,81 @@
+package user
+
+import (
+	"context"
+	"testing"
+
+	"api/internal/types"
+	"blog-common/errorx"
+	"blog_user_service/rpc/types/user"
+)
+
+func TestUpdate(t *testing.T) {
+	tests := []struct {
+		name     string
+		caller   string
+		target   string // 为空时不传 id 更新自己
+		req      types.UpdateUserVo
+		wantCode int
+		// 更新成功后被修改的用户
+		wantUpdated string
+	}{
+		{name: "self without id", caller: "alice", req: types.UpdateUserVo{Nickname: "A"}, wantUpdated: "alice"},
+		{name: "self with id", caller: "alice", target: "alice", req: types.UpdateUserVo{Nickname: "A"}, wantUpdated: "alice"},
+		{name: "other user forbidden", caller: "alice", target: "bob", req: types.UpdateUserVo{Nickname: "A"}, wantCode: errorx.CodeForbidden},
+		{name: "admin updates other user", caller: "admin", target: "bob", req: types.UpdateUserVo{Nickname: "A"}, wantUpdated: "bob"},
+		{name: "not logged in", target: "bob", req: types.UpdateUserVo{Nickname: "A"}, wantCode: errorx.CodeUnauthorized},
+		{name: "role cannot change", caller: "admin", req: types.UpdateUserVo{Role: "admin"}, wantCode: errorx.CodeInvalidParam},
+		{name: "invalid age", caller: "alice", req: types.UpdateUserVo{Age: 200}, wantCode: errorx.CodeInvalidParam},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svcCtx, users, ids := newTestServiceContext(t)
+			req := tt.req
+			req.Id = ids[tt.target]
+
+			err := NewUpdateLogic(withCaller(ids[tt.caller]), svcCtx).Update(&req)
+			if code := errorCode(err); code != tt.wantCode {
+				t.Fatalf("code = %d (%v), want %d", code, err, tt.wantCode)
+			}
+			// 失败时不能修改任何用户 成功时只修改目标用户
+			for name, id := range ids {
+				got, err := users.GetUserById(context.Background(), &user.IdDto{Id: id})
+				if err != nil {
+					t.Fatal(err)
+				}
+				updated := got.Nickname == req.Nickname && req.Nickname != ""
+				if updated != (name == tt.wantUpdated) {
+					t.Errorf("user %s nickname = %q", name, got.Nickname)
+				}
+			}
+		})
+	}
+}
+
+func TestUpdateUserNotFound(t *testing.T) {
+	svcCtx, _, ids := newTestServiceContext(t)
+
+	err := NewUpdateLogic(withCaller(ids["admin"]), svcCtx).Update(&types.UpdateUserVo{Id: 999, Nickname: "A"})
+	if code := errorCode(err); code != errorx.CodeUserNotFound {
+		t.Fatalf("code = %d (%v), want %d", code, err, errorx.CodeUserNotFound)
+	}
+}
+
+func TestUpdateKeepsEmptyFields(t *testing.T) {
+	svcCtx, users, ids := newTestServiceContext(t)
+
+	err := NewUpdateLogic(withCaller(ids["bob"]), svcCtx).Update(&types.UpdateUserVo{Description: "hello", Password: "new-pass"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := users.GetUserById(context.Background(), &user.IdDto{Id: ids["bob"]})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Description != "hello" || got.Email != "bob@example.com" || got.Age != 30 {
+		t.Errorf("user = %+v", got)
+	}
+	if _, err := users.Login(context.Background(), &user.LoginDto{Username: "bob", Password: "new-pass"}); err != nil {
+		t.Errorf("login with new password: %v", err)
+	}
+}
@@ -0,0 +1,91 @@
+package middleware
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"blog-common/auth"
+	"blog-common/errorx"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/zeromicro/go-zero/rest/httpx"
+)
+
+const testSecret = "test-secret"
+
+func init() {
+	// 与 user.go 一致 错误按业务错误码返回
+	httpx.SetErrorHandlerCtx(errorx.ErrorHandler)
+}
+
+func signToken(t *testing.T, secret string, userID int64, expiresIn time.Duration) string {
+	t.Helper()
+	claims := auth.Claims{
+		UserID:   userID,
+		Username: "alice",
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(expiresIn)),
+		},
+	}
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(secret))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return token
+}
+
+func TestAuthInterceptorMiddleware(t *testing.T) {
+	valid := signToken(t, testSecret, 7, time.Hour)
+	tests := []struct {
+		name          string
+		authorization string
+		wantStatus    int
+		wantMessage   string
+	}{
+		{name: "missing token", wantStatus: http.StatusUnauthorized, wantMessage: "缺少令牌"},
+		{name: "not bearer", authorization: "Basic " + valid, wantStatus: http.StatusUnauthorized, wantMessage: "缺少令牌"},
+		{name: "empty bearer", authorization: "Bearer ", wantStatus: http.StatusUnauthorized, wantMessage: "缺少令牌"},
+		{name: "malformed token", authorization: "Bearer not-a-jwt", wantStatus: http.StatusUnauthorized, wantMessage: "无效的令牌"},
+		{name: "wrong secret", authorization: "Bearer " + signToken(t, "other-secret", 7, time.Hour), wantStatus: http.StatusUnauthorized, wantMessage: "无效的令牌"},
+		{name: "expired token", authorization: "Bearer " + signToken(t, testSecret, 7, -time.Minute), wantStatus: http.StatusUnauthorized, wantMessage: "无效的令牌"},
+		{name: "valid token", authorization: "Bearer " + valid, wantStatus: http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var caller *auth.Caller
+			next := func(w http.ResponseWriter, r *http.Request) {
+				caller, _ = auth.CallerFrom(r.Context())
+				w.WriteHeader(http.StatusOK)
+			}
+			req := httptest.NewRequest(http.MethodGet, "/v1/user/query", nil)
+			if tt.authorization != "" {
+				req.Header.Set("Authorization", tt.authorization)
+			}
+			rec := httptest.NewRecorder()
+			NewAuthInterceptorMiddleware(testSecret).Handle(next)(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+			if tt.wantStatus != http.StatusOK {
+				if caller != nil {
+					t.Error("next handler was called")
+				}
+				var body errorx.Response
+				if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+					t.Fatal(err)
+				}
+				if body.Code != errorx.CodeUnauthorized || body.Message != tt.wantMessage {
+					t.Errorf("body = %+v, want code %d message %q", body, errorx.CodeUnauthorized, tt.wantMessage)
+				}
+				return
+			}
+			if caller == nil || caller.UserID != 7 || caller.Username != "alice" || caller.Token != valid {
+				t.Errorf("caller = %+v", caller)
+			}
+		})
+	}
+}
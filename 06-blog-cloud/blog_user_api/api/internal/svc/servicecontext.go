@@ -6,6 +6,7 @@ package svc
 import (
 	"api/internal/config"
 	"api/internal/middleware"
+	"blog-common/tracing"
 	"blog_user_service/rpc/userservice"
 
 	"github.com/zeromicro/go-zero/rest"
@@ -19,13 +20,19 @@ type ServiceContext struct {
 }
 
 func NewServiceContext(c config.Config) *ServiceContext {
-	client, err := zrpc.NewClient(c.UserRpc)
+	// 请求ID通过 gRPC metadata 传给 UserService
+	client, err := zrpc.NewClient(c.UserRpc, zrpc.WithUnaryClientInterceptor(tracing.UnaryClientInterceptor))
 	if err != nil {
 		panic(err)
 	}
+	return NewServiceContextWithUserRpc(c, userservice.NewUserService(client))
+}
+
+// NewServiceContextWithUserRpc 使用指定的 UserService 测试时可以传入 fake.NewUserService()
+func NewServiceContextWithUserRpc(c config.Config, userRpc userservice.UserService) *ServiceContext {
 	return &ServiceContext{
 		Config:          c,
-		AuthInterceptor: middleware.NewAuthInterceptorMiddleware().Handle,
-		UserRpc:         userservice.NewUserService(client),
+		AuthInterceptor: middleware.NewAuthInterceptorMiddleware(c.Auth.AccessSecret).Handle,
+		UserRpc:         userRpc,
 	}
 }
@@ -9,11 +9,13 @@ type LoginDto struct {
 }
 
 type LoginVo struct {
-	Token string `json:"token"`
+	Id    int64  `json:"id"`
+	Name  string `json:"name"`
+	Email string `json:"email"`
 }
 
 type QueryDto struct {
-	Id int64 `json:"id"`
+	Id int64 `form:"id,optional"`
 }
 
 type QueryPathDto struct {
@@ -50,7 +52,6 @@ type UpdateUserVo struct {
 type User struct {
 	Id          int64  `json:"id"`
 	Name        string `json:"name"`
-	Password    string `json:"password"`
 	Email       string `json:"email"`
 	Age         int    `json:"age"`
 	Description string `json:"description"`
@@ -29,7 +29,6 @@ func main() {
 	registerReq := &user.RegisterDto{
 		Name:     "test_user_" + time.Now().Format("150405"), // 确保用户名唯一
 		Password: "123456",
-		Phone:    "13800138000",
 		Email:    "test_" + time.Now().Format("150405") + "@example.com",
 	}
 
@@ -90,12 +89,11 @@ func main() {
 	// 测试更新用户信息接口
 	fmt.Println("\n=== 测试更新用户信息接口 ===")
 	if registerResp != nil {
-		updateReq := &user.UserInfoVo{
-			Id:     registerResp.Id,
-			Name:   registerReq.Name + "_updated",
-			Email:  registerReq.Email,
-			Phone:  "13900139000",
-			Gender: 1,
+		updateReq := &user.UpdateUserDto{
+			Id:       registerResp.Id,
+			Name:     registerReq.Name + "_updated",
+			Email:    registerReq.Email,
+			Nickname: "测试用户",
 		}
 
 		updateResp, err := client.UpdateUserInfo(context.Background(), updateReq)
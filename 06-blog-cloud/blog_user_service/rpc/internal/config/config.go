@@ -1,7 +1,12 @@
 package config
 
-import "github.com/zeromicro/go-zero/zrpc"
+import (
+	"blog-common/database"
+
+	"github.com/zeromicro/go-zero/zrpc"
+)
 
 type Config struct {
 	zrpc.RpcServerConf
+	MySQL database.Config
 }
@@ -0,0 +1,53 @@
+// Code generated by blog-common/convgen. DO NOT EDIT.
+// 字段变化后执行 go generate 重新生成
+
+package convert
+
+import (
+	"blog_user_service/rpc/models"
+	"blog_user_service/rpc/types/user"
+)
+
+// ToUserInfoVo models.User 转换为 user.UserInfoVo
+func ToUserInfoVo(in *models.User) *user.UserInfoVo {
+	if in == nil {
+		return nil
+	}
+	out := &user.UserInfoVo{}
+	out.Id = in.ID
+	out.Name = in.Name
+	out.Email = in.Email
+	out.Age = int32(in.Age)
+	out.Description = in.Description
+	out.Nickname = in.Nickname
+	out.Role = in.Role
+	return out
+}
+
+// UserFromRegisterDto user.RegisterDto 转换为 models.User
+func UserFromRegisterDto(in *user.RegisterDto) *models.User {
+	if in == nil {
+		return nil
+	}
+	out := &models.User{}
+	out.Name = in.Name
+	out.Password = in.Password
+	out.Email = in.Email
+	return out
+}
+
+// UserFromUpdateUserDto user.UpdateUserDto 转换为 models.User
+func UserFromUpdateUserDto(in *user.UpdateUserDto) *models.User {
+	if in == nil {
+		return nil
+	}
+	out := &models.User{}
+	out.ID = in.Id
+	out.Name = in.Name
+	out.Password = in.Password
+	out.Email = in.Email
+	out.Age = int(in.Age)
+	out.Description = in.Description
+	out.Nickname = in.Nickname
+	return out
+}
@@ -0,0 +1,91 @@
+package convert
+
+import (
+	"strings"
+	"testing"
+
+	"blog-common/auth"
+	"blog_user_service/rpc/models"
+	"blog_user_service/rpc/types/user"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+func TestUpdateUserDtoRoundTrip(t *testing.T) {
+	in := &user.UpdateUserDto{
+		Id:          7,
+		Name:        "alice",
+		Email:       "alice@example.com",
+		Age:         30,
+		Description: "hello",
+		Nickname:    "Alice",
+	}
+	m := UserFromUpdateUserDto(in)
+	m.Role = auth.RoleAdmin
+	got := ToUserInfoVo(m)
+
+	want := &user.UserInfoVo{
+		Id:          in.Id,
+		Name:        in.Name,
+		Email:       in.Email,
+		Age:         in.Age,
+		Description: in.Description,
+		Nickname:    in.Nickname,
+		Role:        auth.RoleAdmin,
+	}
+	if !proto.Equal(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestUserFromUpdateUserDtoKeepsPassword(t *testing.T) {
+	m := UserFromUpdateUserDto(&user.UpdateUserDto{Id: 7, Password: "new-pass"})
+	if m.ID != 7 || m.Password != "new-pass" {
+		t.Errorf("got %+v", m)
+	}
+	// 角色只能由管理员接口修改
+	if m.Role != "" {
+		t.Errorf("role = %q, want empty", m.Role)
+	}
+}
+
+func TestUserFromRegisterDto(t *testing.T) {
+	m := UserFromRegisterDto(&user.RegisterDto{Name: "alice", Password: "pass", Email: "alice@example.com"})
+	want := models.User{Name: "alice", Password: "pass", Email: "alice@example.com"}
+	if *m != want {
+		t.Errorf("got %+v, want %+v", *m, want)
+	}
+}
+
+// TestUserInfoVoHasNoPassword 密码不能出现在 UserInfoVo 的字段和编码结果中
+func TestUserInfoVoHasNoPassword(t *testing.T) {
+	fields := (&user.UserInfoVo{}).ProtoReflect().Descriptor().Fields()
+	for i := range fields.Len() {
+		if name := string(fields.Get(i).Name()); strings.Contains(strings.ToLower(name), "password") {
+			t.Errorf("UserInfoVo has field %s", name)
+		}
+	}
+
+	const secret = "s3cret-hash"
+	vo := ToUserInfoVo(&models.User{BaseModel: models.BaseModel{ID: 1}, Name: "alice", Password: secret})
+	vo.ProtoReflect().Range(func(fd protoreflect.FieldDescriptor, v protoreflect.Value) bool {
+		if v.String() == secret {
+			t.Errorf("field %s carries the password", fd.Name())
+		}
+		return true
+	})
+	b, err := proto.Marshal(vo)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(b), secret) {
+		t.Error("encoded UserInfoVo contains the password")
+	}
+}
+
+func TestNil(t *testing.T) {
+	if ToUserInfoVo(nil) != nil || UserFromRegisterDto(nil) != nil || UserFromUpdateUserDto(nil) != nil {
+		t.Error("nil input should convert to nil")
+	}
+}
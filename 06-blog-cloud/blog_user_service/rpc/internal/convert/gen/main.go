@@ -0,0 +1,44 @@
+// 生成 convert_gen.go 在 internal/convert 目录执行 go generate
+package main
+
+import (
+	"log"
+
+	"blog-common/convgen"
+	"blog_user_service/rpc/models"
+	"blog_user_service/rpc/types/user"
+)
+
+// baseFields models.BaseModel 中由数据库维护的字段
+var baseFields = []string{"CreatedAt", "UpdatedAt", "DeletedAt", "IsDeleted"}
+
+// pairs 生成的转换函数 main_test.go 用它检查 convert_gen.go 是否已重新生成
+var pairs = []convgen.Pair{
+	{
+		Func: "ToUserInfoVo",
+		From: models.User{},
+		To:   user.UserInfoVo{},
+		// 响应中不返回密码
+		Skip: append([]string{"Password"}, baseFields...),
+	},
+	{
+		Func: "UserFromRegisterDto",
+		From: user.RegisterDto{},
+		To:   models.User{},
+		// 密码在 logic 中加密 角色注册时固定为 user
+		Skip: append([]string{"ID", "Age", "Description", "Nickname", "Role"}, baseFields...),
+	},
+	{
+		Func: "UserFromUpdateUserDto",
+		From: user.UpdateUserDto{},
+		To:   models.User{},
+		// 角色不能通过 UpdateUserInfo 修改
+		Skip: append([]string{"Role"}, baseFields...),
+	},
+}
+
+func main() {
+	if err := convgen.WriteFile("convert_gen.go", "convert", pairs...); err != nil {
+		log.Fatal(err)
+	}
+}
@@ -0,0 +1,24 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"testing"
+
+	"blog-common/convgen"
+)
+
+// TestGeneratedUpToDate 字段变化后没有重新执行 go generate 时失败
+func TestGeneratedUpToDate(t *testing.T) {
+	want, err := convgen.Generate("convert", pairs...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := os.ReadFile("../convert_gen.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Error("convert_gen.go 不是最新的 在 internal/convert 目录执行 go generate 后提交")
+	}
+}
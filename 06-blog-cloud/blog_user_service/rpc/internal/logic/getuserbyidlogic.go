@@ -4,9 +4,9 @@ import (
 	"context"
 	"errors"
 
-	"blog_user_service/rpc/inits"
+	"blog-common/errorx"
+	"blog_user_service/rpc/internal/convert"
 	"blog_user_service/rpc/internal/svc"
-	"blog_user_service/rpc/models"
 	"blog_user_service/rpc/types/user"
 
 	"github.com/zeromicro/go-zero/core/logx"
@@ -31,37 +31,24 @@ func NewGetUserByIdLogic(ctx context.Context, svcCtx *svc.ServiceContext) *GetUs
 func (l *GetUserByIdLogic) GetUserById(in *user.IdDto) (*user.UserInfoVo, error) {
 	l.Logger.Infof("GetUserById request received: Id=%d", in.Id)
 
-	// 获取mysql配置
-	db := inits.MysqlDb
-
 	// 参数验证
 	if in.Id <= 0 {
 		l.Logger.Errorf("Invalid user ID: %d", in.Id)
-		return nil, errors.New("无效的用户ID")
+		return nil, errorx.New(errorx.CodeInvalidParam, "无效的用户ID")
 	}
 
 	// 查询用户
-	var dbUser models.User
-	result := db.First(&dbUser, in.Id)
-	if result.Error != nil {
-		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+	dbUser, err := l.svcCtx.UserRepo.FindByID(l.ctx, in.Id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
 			l.Logger.Errorf("User not found for ID: %d", in.Id)
-			return nil, errors.New("用户不存在")
+			return nil, errorx.New(errorx.CodeUserNotFound, "用户不存在")
 		}
-		l.Logger.Errorf("Database query error: %v", result.Error)
-		return nil, result.Error
-	}
-
-	// 创建用户信息响应
-	userVo := &user.UserInfoVo{
-		Id:     uint32(dbUser.ID),
-		Name:   dbUser.Name,
-		Email:  dbUser.Email,
-		Gender: 1,
-		Phone:  "13555554444",
+		l.Logger.Errorf("Database query error: %v", err)
+		return nil, err
 	}
 
 	l.Logger.Infof("User found: ID=%d, Name=%s, Email=%s", dbUser.ID, dbUser.Name, dbUser.Email)
 
-	return userVo, nil
+	return convert.ToUserInfoVo(dbUser), nil
 }
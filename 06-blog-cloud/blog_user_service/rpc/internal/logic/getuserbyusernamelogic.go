@@ -2,11 +2,15 @@ package logic
 
 import (
 	"context"
+	"errors"
 
+	"blog-common/errorx"
+	"blog_user_service/rpc/internal/convert"
 	"blog_user_service/rpc/internal/svc"
 	"blog_user_service/rpc/types/user"
 
 	"github.com/zeromicro/go-zero/core/logx"
+	"gorm.io/gorm"
 )
 
 type GetUserByUsernameLogic struct {
@@ -25,7 +29,23 @@ func NewGetUserByUsernameLogic(ctx context.Context, svcCtx *svc.ServiceContext)
 
 // 根据用户名获取用户信息
 func (l *GetUserByUsernameLogic) GetUserByUsername(in *user.UsernameDto) (*user.UserInfoVo, error) {
-	// todo: add your logic here and delete this line
+	l.Logger.Infof("GetUserByUsername request received: Username=%s", in.Username)
 
-	return &user.UserInfoVo{}, nil
+	// 参数验证
+	if in.Username == "" {
+		return nil, errorx.New(errorx.CodeInvalidParam, "用户名不能为空")
+	}
+
+	// 查询用户
+	dbUser, err := l.svcCtx.UserRepo.FindByName(l.ctx, in.Username)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			l.Logger.Errorf("User not found: %s", in.Username)
+			return nil, errorx.New(errorx.CodeUserNotFound, "用户不存在")
+		}
+		l.Logger.Errorf("Database query error: %v", err)
+		return nil, err
+	}
+
+	return convert.ToUserInfoVo(dbUser), nil
 }
@@ -3,9 +3,8 @@ package logic
 import (
 	"context"
 
-	"blog_user_service/rpc/inits"
+	"blog_user_service/rpc/internal/convert"
 	"blog_user_service/rpc/internal/svc"
-	"blog_user_service/rpc/models"
 	"blog_user_service/rpc/types/user"
 
 	"github.com/zeromicro/go-zero/core/logx"
@@ -29,9 +28,6 @@ func NewListUserLogic(ctx context.Context, svcCtx *svc.ServiceContext) *ListUser
 func (l *ListUserLogic) ListUser(in *user.PageInfoDto) (*user.UserInfoVoList, error) {
 	l.Logger.Infof("ListUser request received: Page=%d, Size=%d", in.PageNumber, in.PageSize)
 
-	// 获取mysql配置
-	db := inits.MysqlDb
-
 	// 参数验证
 	pageNumber := in.PageNumber
 	if pageNumber <= 0 {
@@ -45,30 +41,18 @@ func (l *ListUserLogic) ListUser(in *user.PageInfoDto) (*user.UserInfoVoList, er
 
 	offset := (pageNumber - 1) * pageSize
 
-	// 查询总数
-	var total int64
-	if err := db.Model(&models.User{}).Count(&total).Error; err != nil {
-		l.Logger.Errorf("Failed to count users: %v", err)
-		return nil, err
-	}
-
-	// 查询数据
-	var users []models.User
-	if err := db.Offset(int(offset)).Limit(int(pageSize)).Find(&users).Error; err != nil {
+	// 查询数据和总数
+	users, total, err := l.svcCtx.UserRepo.List(l.ctx, int(offset), int(pageSize))
+	if err != nil {
 		l.Logger.Errorf("Failed to list users: %v", err)
 		return nil, err
 	}
 
 	// 转换为响应格式
 	userInfos := make([]*user.UserInfoVo, len(users))
-	for i, u := range users {
-		userInfos[i] = &user.UserInfoVo{
-			Id:     uint32(u.ID),
-			Name:   u.Name,
-			Email:  u.Email,
-			Gender: 1,
-			Phone:  "13555554444",
-		}
+	for i := range users {
+		u := &users[i]
+		userInfos[i] = convert.ToUserInfoVo(u)
 		l.Logger.Infof("User found: ID=%d, Name=%s, Email=%s", u.ID, u.Name, u.Email)
 	}
 
@@ -76,7 +60,7 @@ func (l *ListUserLogic) ListUser(in *user.PageInfoDto) (*user.UserInfoVoList, er
 
 	// 返回结果
 	return &user.UserInfoVoList{
-			Total:          int32(total),
+			Total:          total,
 			UserInfoVoList: userInfos,
 		},
 		nil
@@ -6,10 +6,9 @@ import (
 	"encoding/hex"
 	"errors"
 
+	"blog-common/errorx"
 	"blog-common/utils"
-	"blog_user_service/rpc/inits"
 	"blog_user_service/rpc/internal/svc"
-	"blog_user_service/rpc/models"
 	"blog_user_service/rpc/types/user"
 
 	"github.com/zeromicro/go-zero/core/logx"
@@ -34,19 +33,15 @@ func NewLoginLogic(ctx context.Context, svcCtx *svc.ServiceContext) *LoginLogic
 func (l *LoginLogic) Login(in *user.LoginDto) (*user.LoginResponse, error) {
 	l.Logger.Infof("Login request received: Username=%s", in.Username)
 
-	//获取mysql配置
-	db := inits.MysqlDb
-
 	// 查询用户
-	var dbUser models.User
-	result := db.Where("name = ?", in.Username).First(&dbUser)
-	if result.Error != nil {
-		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+	dbUser, err := l.svcCtx.UserRepo.FindByName(l.ctx, in.Username)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
 			l.Logger.Errorf("User not found: %s", in.Username)
-			return nil, errors.New("用户名或密码错误")
+			return nil, errorx.New(errorx.CodeInvalidCredentials, "用户名或密码错误")
 		}
-		l.Logger.Errorf("Database query error: %v", result.Error)
-		return nil, result.Error
+		l.Logger.Errorf("Database query error: %v", err)
+		return nil, err
 	}
 
 	// 密码加密验证
@@ -55,7 +50,7 @@ func (l *LoginLogic) Login(in *user.LoginDto) (*user.LoginResponse, error) {
 
 	if encryptedPassword != dbUser.Password {
 		l.Logger.Errorf("Password mismatch for user: %s", in.Username)
-		return nil, errors.New("用户名或密码错误")
+		return nil, errorx.New(errorx.CodeInvalidCredentials, "用户名或密码错误")
 	}
 
 	// 生成JWT令牌
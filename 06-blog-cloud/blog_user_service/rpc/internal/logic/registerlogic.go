@@ -6,9 +6,10 @@ import (
 	"encoding/hex"
 	"errors"
 
-	"blog_user_service/rpc/inits"
+	"blog-common/auth"
+	"blog-common/errorx"
+	"blog_user_service/rpc/internal/convert"
 	"blog_user_service/rpc/internal/svc"
-	"blog_user_service/rpc/models"
 	"blog_user_service/rpc/types/user"
 
 	"github.com/zeromicro/go-zero/core/logx"
@@ -30,43 +31,32 @@ func NewRegisterLogic(ctx context.Context, svcCtx *svc.ServiceContext) *Register
 }
 
 // 注册
-func (l *RegisterLogic) Register(in *user.RegisterDto) (userVo *user.UserInfoVo, err error) {
-	// 创建一个新的UserInfoVo实例
-	userVo = new(user.UserInfoVo)
-	l.Logger.Infof("Register: %v", in)
-	//获取mysql配置
-	db := inits.MysqlDb
-	username := in.Name
-	var existingUser models.User
+func (l *RegisterLogic) Register(in *user.RegisterDto) (*user.UserInfoVo, error) {
+	l.Logger.Infof("Register: Name=%s, Email=%s", in.Name, in.Email)
 	// 检查用户名是否已存在，如果不存在则会返回RecordNotFound错误
-	result := db.Where("name = ?", username).First(&existingUser)
-	if result.Error == nil {
+	_, err := l.svcCtx.UserRepo.FindByName(l.ctx, in.Name)
+	if err == nil {
 		// 用户已存在
-		return nil, errors.New("用户名已存在")
-	} else if !errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return nil, errorx.New(errorx.CodeUserExists, "用户名已存在")
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
 		// 非记录不存在的其他错误
-		return nil, result.Error
+		return nil, err
 	}
 	// 用户名不存在，可以继续创建
+	//创建用户 注册的都是普通用户
+	newUser := convert.UserFromRegisterDto(in)
 	//密码加密
 	hash := md5.Sum([]byte(in.Password))
-	in.Password = hex.EncodeToString(hash[:])
-	//创建用户
-	user := models.User{
-		Name:     in.Name,
-		Password: in.Password,
-		Email:    in.Email,
-	}
-	if err := db.Create(&user).Error; err != nil {
+	newUser.Password = hex.EncodeToString(hash[:])
+	newUser.Role = auth.RoleUser
+	if err := l.svcCtx.UserRepo.Create(l.ctx, newUser); err != nil {
+		// 检查之后其他请求注册了同名用户 由唯一索引拦截
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			return nil, errorx.New(errorx.CodeUserExists, "用户名已存在")
+		}
 		return nil, err
 	}
 
-	// 设置UserInfoVo字段
-	l.Logger.Infof("User ID from database: %d", user.ID)
-	userVo.Id = uint32(user.ID)
-	l.Logger.Infof("Set userVo.Id to: %d", userVo.Id)
-	userVo.Name = user.Name
-	userVo.Email = user.Email
-	l.Logger.Infof("Returning userVo: Id=%d, Name=%s, Email=%s", userVo.Id, userVo.Name, userVo.Email)
-	return userVo, nil
+	l.Logger.Infof("User created: Id=%d, Name=%s, Email=%s", newUser.ID, newUser.Name, newUser.Email)
+	return convert.ToUserInfoVo(newUser), nil
 }
@@ -0,0 +1,51 @@
+package logic
+
+import (
+	"context"
+	"testing"
+
+	"blog-common/auth"
+	"blog-common/errorx"
+	"blog_user_service/rpc/internal/config"
+	"blog_user_service/rpc/internal/repository"
+	"blog_user_service/rpc/internal/svc"
+	"blog_user_service/rpc/types/user"
+)
+
+func newTestServiceContext() *svc.ServiceContext {
+	return svc.NewServiceContextWithRepo(config.Config{}, repository.NewMemoryUserRepository())
+}
+
+// errorCode 错误对应的业务错误码 nil 为 CodeOK
+func errorCode(err error) int {
+	if err == nil {
+		return errorx.CodeOK
+	}
+	return errorx.FromError(err).Code
+}
+
+func TestRegister(t *testing.T) {
+	svcCtx := newTestServiceContext()
+	ctx := context.Background()
+
+	vo, err := NewRegisterLogic(ctx, svcCtx).Register(&user.RegisterDto{Name: "alice", Password: "pass", Email: "alice@example.com"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if vo.Id == 0 || vo.Name != "alice" || vo.Email != "alice@example.com" || vo.Role != auth.RoleUser {
+		t.Errorf("user = %v", vo)
+	}
+	// 数据库中保存的是加密后的密码
+	stored, err := svcCtx.UserRepo.FindByID(ctx, vo.Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stored.Password == "" || stored.Password == "pass" {
+		t.Errorf("stored password = %q", stored.Password)
+	}
+
+	_, err = NewRegisterLogic(ctx, svcCtx).Register(&user.RegisterDto{Name: "alice", Password: "other"})
+	if code := errorCode(err); code != errorx.CodeUserExists {
+		t.Errorf("code = %d (%v), want %d", code, err, errorx.CodeUserExists)
+	}
+}
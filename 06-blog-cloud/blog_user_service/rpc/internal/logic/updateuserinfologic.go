@@ -6,7 +6,8 @@ import (
 	"encoding/hex"
 	"errors"
 
-	"blog_user_service/rpc/inits"
+	"blog-common/errorx"
+	"blog_user_service/rpc/internal/convert"
 	"blog_user_service/rpc/internal/svc"
 	"blog_user_service/rpc/models"
 	"blog_user_service/rpc/types/user"
@@ -29,60 +30,45 @@ func NewUpdateUserInfoLogic(ctx context.Context, svcCtx *svc.ServiceContext) *Up
 	}
 }
 
-func (l *UpdateUserInfoLogic) UpdateUserInfo(in *user.UserInfoVo) (*user.IsSuccess, error) {
+// 更新用户信息 只更新非空字段
+func (l *UpdateUserInfoLogic) UpdateUserInfo(in *user.UpdateUserDto) (*user.IsSuccess, error) {
 	l.Logger.Infof("UpdateUserInfo request received: UserId=%d", in.Id)
 	
-	// 获取mysql配置
-	db := inits.MysqlDb
-	
 	// 参数验证
 	if in.Id <= 0 {
 		l.Logger.Errorf("Invalid user ID: %d", in.Id)
-		return &user.IsSuccess{Success: false}, errors.New("无效的用户ID")
+		return &user.IsSuccess{Success: false}, errorx.New(errorx.CodeInvalidParam, "无效的用户ID")
 	}
 	
 	// 检查用户是否存在
-	var dbUser models.User
-	result := db.First(&dbUser, in.Id)
-	if result.Error != nil {
-		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+	if _, err := l.svcCtx.UserRepo.FindByID(l.ctx, in.Id); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
 			l.Logger.Errorf("User not found for ID: %d", in.Id)
-			return &user.IsSuccess{Success: false}, errors.New("用户不存在")
+			return &user.IsSuccess{Success: false}, errorx.New(errorx.CodeUserNotFound, "用户不存在")
 		}
-		l.Logger.Errorf("Database query error: %v", result.Error)
-		return &user.IsSuccess{Success: false}, result.Error
+		l.Logger.Errorf("Database query error: %v", err)
+		return &user.IsSuccess{Success: false}, err
 	}
 	
-	// 创建更新字段映射
-	updates := make(map[string]interface{})
-	
-	// 只更新非空字段
-	if in.Name != "" {
-		updates["name"] = in.Name
-	}
-	if in.Email != "" {
-		updates["email"] = in.Email
-	}
+	// 只更新非空字段 gorm 的 Updates 会跳过结构体中的零值
+	updates := convert.UserFromUpdateUserDto(in)
+	updates.ID = 0
 	if in.Password != "" {
 		// 密码加密
 		hash := md5.Sum([]byte(in.Password))
-		updates["password"] = hex.EncodeToString(hash[:])
-	}
-	if in.Phone != "" {
-		updates["phone"] = in.Phone
-	}
-	if in.Gender > 0 {
-		updates["gender"] = in.Gender
+		updates.Password = hex.EncodeToString(hash[:])
 	}
 	
 	// 如果有需要更新的字段
-	if len(updates) > 0 {
-		result = db.Model(&dbUser).Updates(updates)
-		if result.Error != nil {
-			l.Logger.Errorf("Failed to update user: %v", result.Error)
-			return &user.IsSuccess{Success: false}, result.Error
+	if *updates != (models.User{}) {
+		if err := l.svcCtx.UserRepo.Updates(l.ctx, in.Id, updates); err != nil {
+			if errors.Is(err, gorm.ErrDuplicatedKey) {
+				return &user.IsSuccess{Success: false}, errorx.New(errorx.CodeUserExists, "用户名已存在")
+			}
+			l.Logger.Errorf("Failed to update user: %v", err)
+			return &user.IsSuccess{Success: false}, err
 		}
-		l.Logger.Infof("User updated successfully: ID=%d, updated fields=%v", in.Id, updates)
+		l.Logger.Infof("User updated successfully: ID=%d", in.Id)
 	} else {
 		l.Logger.Infof("No fields provided to update for user: ID=%d", in.Id)
 	}
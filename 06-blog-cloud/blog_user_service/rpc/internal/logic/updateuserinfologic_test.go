@@ -0,0 +1,58 @@
+package logic
+
+import (
+	"context"
+	"testing"
+
+	"blog-common/auth"
+	"blog-common/errorx"
+	"blog_user_service/rpc/types/user"
+)
+
+func TestUpdateUserInfo(t *testing.T) {
+	svcCtx := newTestServiceContext()
+	ctx := context.Background()
+	vo, err := NewRegisterLogic(ctx, svcCtx).Register(&user.RegisterDto{Name: "alice", Password: "pass", Email: "alice@example.com"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 只更新非空字段
+	if _, err := NewUpdateUserInfoLogic(ctx, svcCtx).UpdateUserInfo(&user.UpdateUserDto{Id: vo.Id, Nickname: "Alice", Password: "new-pass"}); err != nil {
+		t.Fatal(err)
+	}
+	got, err := NewGetUserByIdLogic(ctx, svcCtx).GetUserById(&user.IdDto{Id: vo.Id})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Nickname != "Alice" || got.Email != "alice@example.com" || got.Role != auth.RoleUser {
+		t.Errorf("user = %v", got)
+	}
+	if _, err := NewLoginLogic(ctx, svcCtx).Login(&user.LoginDto{Username: "alice", Password: "new-pass"}); err != nil {
+		t.Errorf("login with new password: %v", err)
+	}
+	_, err = NewLoginLogic(ctx, svcCtx).Login(&user.LoginDto{Username: "alice", Password: "pass"})
+	if code := errorCode(err); code != errorx.CodeInvalidCredentials {
+		t.Errorf("login with old password: code = %d (%v), want %d", code, err, errorx.CodeInvalidCredentials)
+	}
+}
+
+func TestUpdateUserInfoInvalid(t *testing.T) {
+	svcCtx := newTestServiceContext()
+	tests := []struct {
+		name     string
+		id       int64
+		wantCode int
+	}{
+		{name: "invalid id", id: 0, wantCode: errorx.CodeInvalidParam},
+		{name: "not found", id: 99, wantCode: errorx.CodeUserNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewUpdateUserInfoLogic(context.Background(), svcCtx).UpdateUserInfo(&user.UpdateUserDto{Id: tt.id, Nickname: "x"})
+			if code := errorCode(err); code != tt.wantCode {
+				t.Errorf("code = %d (%v), want %d", code, err, tt.wantCode)
+			}
+		})
+	}
+}
@@ -0,0 +1,123 @@
+package repository
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"blog_user_service/rpc/models"
+
+	"gorm.io/gorm"
+)
+
+// memoryUserRepository 数据保存在内存中的 UserRepository 行为与 gorm 实现一致
+type memoryUserRepository struct {
+	mu     sync.Mutex
+	nextID int64
+	users  map[int64]models.User
+}
+
+// NewMemoryUserRepository 数据保存在内存中的 UserRepository 测试 logic 时通过 svc.NewServiceContextWithRepo 注入
+func NewMemoryUserRepository() UserRepository {
+	return &memoryUserRepository{users: make(map[int64]models.User)}
+}
+
+func (r *memoryUserRepository) FindByID(ctx context.Context, id int64) (*models.User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	u, ok := r.users[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &u, nil
+}
+
+func (r *memoryUserRepository) FindByName(ctx context.Context, name string) (*models.User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	u, ok := r.findByName(name)
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &u, nil
+}
+
+func (r *memoryUserRepository) List(ctx context.Context, offset, limit int) ([]models.User, int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	ids := make([]int64, 0, len(r.users))
+	for id := range r.users {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+	var users []models.User
+	for i := offset; i < len(ids) && i < offset+limit; i++ {
+		users = append(users, r.users[ids[i]])
+	}
+	return users, int64(len(ids)), nil
+}
+
+func (r *memoryUserRepository) Create(ctx context.Context, u *models.User) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.findByName(u.Name); ok {
+		return gorm.ErrDuplicatedKey
+	}
+	r.nextID++
+	now := time.Now()
+	u.ID, u.CreatedAt, u.UpdatedAt = r.nextID, now, now
+	// 与表结构中 role 的默认值一致
+	if u.Role == "" {
+		u.Role = "user"
+	}
+	r.users[u.ID] = *u
+	return nil
+}
+
+func (r *memoryUserRepository) Updates(ctx context.Context, id int64, changes *models.User) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	u, ok := r.users[id]
+	if !ok {
+		// gorm 更新不存在的记录时不返回错误
+		return nil
+	}
+	if changes.Name != "" {
+		if other, ok := r.findByName(changes.Name); ok && other.ID != id {
+			return gorm.ErrDuplicatedKey
+		}
+		u.Name = changes.Name
+	}
+	if changes.Password != "" {
+		u.Password = changes.Password
+	}
+	if changes.Email != "" {
+		u.Email = changes.Email
+	}
+	if changes.Age != 0 {
+		u.Age = changes.Age
+	}
+	if changes.Description != "" {
+		u.Description = changes.Description
+	}
+	if changes.Nickname != "" {
+		u.Nickname = changes.Nickname
+	}
+	if changes.Role != "" {
+		u.Role = changes.Role
+	}
+	u.UpdatedAt = time.Now()
+	r.users[id] = u
+	return nil
+}
+
+func (r *memoryUserRepository) findByName(name string) (models.User, bool) {
+	for _, u := range r.users {
+		if u.Name == name {
+			return u, true
+		}
+	}
+	return models.User{}, false
+}
@@ -0,0 +1,74 @@
+// Package repository 用户服务的数据访问 logic 只依赖接口
+// 查询不到时返回 gorm.ErrRecordNotFound 用户名重复时返回 gorm.ErrDuplicatedKey
+package repository
+
+import (
+	"context"
+
+	"blog_user_service/rpc/models"
+
+	"gorm.io/gorm"
+)
+
+// UserRepository 用户数据访问
+type UserRepository interface {
+	// FindByID 根据ID查询用户
+	FindByID(ctx context.Context, id int64) (*models.User, error)
+	// FindByName 根据用户名查询用户
+	FindByName(ctx context.Context, name string) (*models.User, error)
+	// List 分页查询用户 返回当前页和总数
+	List(ctx context.Context, offset, limit int) ([]models.User, int64, error)
+	// Create 新增用户 成功后 u.ID 为新用户的ID
+	Create(ctx context.Context, u *models.User) error
+	// Updates 更新用户 只更新 changes 中的非零字段
+	Updates(ctx context.Context, id int64, changes *models.User) error
+}
+
+type gormUserRepository struct {
+	db *gorm.DB
+}
+
+// NewUserRepository 基于 gorm 的 UserRepository
+func NewUserRepository(db *gorm.DB) UserRepository {
+	return &gormUserRepository{db: db}
+}
+
+func (r *gormUserRepository) FindByID(ctx context.Context, id int64) (*models.User, error) {
+	var u models.User
+	if err := r.db.WithContext(ctx).First(&u, id).Error; err != nil {
+		return nil, err
+	}
+	return &u, nil
+}
+
+func (r *gormUserRepository) FindByName(ctx context.Context, name string) (*models.User, error) {
+	var u models.User
+	if err := r.db.WithContext(ctx).Where("name = ?", name).First(&u).Error; err != nil {
+		return nil, err
+	}
+	return &u, nil
+}
+
+func (r *gormUserRepository) List(ctx context.Context, offset, limit int) ([]models.User, int64, error) {
+	db := r.db.WithContext(ctx)
+	var total int64
+	if err := db.Model(&models.User{}).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	var users []models.User
+	if err := db.Order("id").Offset(offset).Limit(limit).Find(&users).Error; err != nil {
+		return nil, 0, err
+	}
+	return users, total, nil
+}
+
+func (r *gormUserRepository) Create(ctx context.Context, u *models.User) error {
+	return r.db.WithContext(ctx).Create(u).Error
+}
+
+func (r *gormUserRepository) Updates(ctx context.Context, id int64, changes *models.User) error {
+	// 主键只用于条件 不作为更新内容
+	c := *changes
+	c.ID = 0
+	return r.db.WithContext(ctx).Model(&models.User{BaseModel: models.BaseModel{ID: id}}).Updates(&c).Error
+}
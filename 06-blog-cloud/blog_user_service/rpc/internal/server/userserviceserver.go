@@ -53,7 +53,8 @@ func (s *UserServiceServer) GetUserById(ctx context.Context, in *user.IdDto) (*u
 	return l.GetUserById(in)
 }
 
-func (s *UserServiceServer) UpdateUserInfo(ctx context.Context, in *user.UserInfoVo) (*user.IsSuccess, error) {
+// 更新用户信息 只更新非空字段
+func (s *UserServiceServer) UpdateUserInfo(ctx context.Context, in *user.UpdateUserDto) (*user.IsSuccess, error) {
 	l := logic.NewUpdateUserInfoLogic(ctx, s.svcCtx)
 	return l.UpdateUserInfo(in)
 }
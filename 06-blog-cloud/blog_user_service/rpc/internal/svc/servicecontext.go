@@ -1,13 +1,35 @@
 package svc
 
-import "blog_user_service/rpc/internal/config"
+import (
+	"log"
+
+	"blog-common/database"
+	"blog-common/migrate"
+	"blog_user_service/rpc/internal/config"
+	"blog_user_service/rpc/internal/repository"
+	"blog_user_service/rpc/migrations"
+)
 
 type ServiceContext struct {
-	Config config.Config
+	Config   config.Config
+	UserRepo repository.UserRepository
 }
 
 func NewServiceContext(c config.Config) *ServiceContext {
+	db := database.MustOpen(c.MySQL)
+	// 执行未执行过的迁移 表结构变化都要追加到 migrations.All
+	if c.MySQL.IsAutoMigrate {
+		if err := migrate.Run(db, migrations.All); err != nil {
+			log.Fatalf("failed to migrate database: %v", err)
+		}
+	}
+	return NewServiceContextWithRepo(c, repository.NewUserRepository(db))
+}
+
+// NewServiceContextWithRepo 使用指定的 UserRepository 测试时可以传入 repository.NewMemoryUserRepository()
+func NewServiceContextWithRepo(c config.Config, userRepo repository.UserRepository) *ServiceContext {
 	return &ServiceContext{
-		Config: c,
+		Config:   c,
+		UserRepo: userRepo,
 	}
 }
@@ -0,0 +1,68 @@
+// Package migrations 用户服务的数据库迁移
+// 每个版本使用自己的表结构快照 不引用 models 中会继续变化的模型
+package migrations
+
+import (
+	"time"
+
+	"blog-common/migrate"
+
+	"gorm.io/gorm"
+)
+
+// All 全部迁移 按版本递增 已发布的版本不能修改 只能追加
+var All = []migrate.Migration{
+	{Version: 1, Description: "创建 users 表", Up: createUsers},
+	{Version: 2, Description: "用户名唯一 角色默认为 user", Up: uniqueNameAndDefaultRole},
+}
+
+type baseModelV1 struct {
+	ID        int64 `gorm:"primaryKey"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	DeletedAt gorm.DeletedAt
+	IsDeleted bool
+}
+
+// userV1 引入迁移前由 AutoMigrate 创建的 users 表 已有的数据库执行时不会有变化
+type userV1 struct {
+	baseModelV1
+	Name        string
+	Password    string
+	Email       string
+	Age         int
+	Description string
+	Nickname    string
+	Role        string
+}
+
+func (userV1) TableName() string {
+	return "users"
+}
+
+func createUsers(tx *gorm.DB) error {
+	return tx.AutoMigrate(&userV1{})
+}
+
+type userV2 struct {
+	baseModelV1
+	Name        string `gorm:"type:varchar(64);not null;uniqueIndex"`
+	Password    string
+	Email       string
+	Age         int
+	Description string
+	Nickname    string
+	Role        string `gorm:"type:varchar(16);not null;default:user"`
+}
+
+func (userV2) TableName() string {
+	return "users"
+}
+
+// uniqueNameAndDefaultRole 先补全空角色再修改列 注册时已检查重名 已有数据不会违反唯一索引
+func uniqueNameAndDefaultRole(tx *gorm.DB) error {
+	if err := tx.Table("users").Where("role = ? OR role IS NULL", "").Update("role", "user").Error; err != nil {
+		return err
+	}
+	return tx.AutoMigrate(&userV2{})
+}
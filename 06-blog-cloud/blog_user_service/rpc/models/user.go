@@ -14,13 +14,15 @@ type BaseModel struct {
 	IsDeleted bool           `json:"is_deleted" gorm:"column:is_deleted"`
 }
 
+// User 用户 表结构由 migrations 维护 修改字段时要追加迁移并重新生成 internal/convert
 type User struct {
 	BaseModel
-	Name        string `json:"name"`
+	Name        string `json:"name" gorm:"type:varchar(64);not null;uniqueIndex"`
 	Password    string `json:"password"`
 	Email       string `json:"email"`
 	Age         int    `json:"age"`
 	Description string `json:"description"`
 	Nickname    string `json:"nickname"`
-	Role        string `json:"role"`
+	// 角色 auth.RoleUser 或 auth.RoleAdmin
+	Role string `json:"role" gorm:"type:varchar(16);not null;default:user"`
 }
@@ -41,7 +41,8 @@ type UserServiceClient interface {
 	GetUserByUsername(ctx context.Context, in *UsernameDto, opts ...grpc.CallOption) (*UserInfoVo, error)
 	// 根据id查询用户
 	GetUserById(ctx context.Context, in *IdDto, opts ...grpc.CallOption) (*UserInfoVo, error)
-	UpdateUserInfo(ctx context.Context, in *UserInfoVo, opts ...grpc.CallOption) (*IsSuccess, error)
+	// 更新用户信息 只更新非空字段
+	UpdateUserInfo(ctx context.Context, in *UpdateUserDto, opts ...grpc.CallOption) (*IsSuccess, error)
 }
 
 type userServiceClient struct {
@@ -102,7 +103,7 @@ func (c *userServiceClient) GetUserById(ctx context.Context, in *IdDto, opts ...
 	return out, nil
 }
 
-func (c *userServiceClient) UpdateUserInfo(ctx context.Context, in *UserInfoVo, opts ...grpc.CallOption) (*IsSuccess, error) {
+func (c *userServiceClient) UpdateUserInfo(ctx context.Context, in *UpdateUserDto, opts ...grpc.CallOption) (*IsSuccess, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(IsSuccess)
 	err := c.cc.Invoke(ctx, UserService_UpdateUserInfo_FullMethodName, in, out, cOpts...)
@@ -126,7 +127,8 @@ type UserServiceServer interface {
 	GetUserByUsername(context.Context, *UsernameDto) (*UserInfoVo, error)
 	// 根据id查询用户
 	GetUserById(context.Context, *IdDto) (*UserInfoVo, error)
-	UpdateUserInfo(context.Context, *UserInfoVo) (*IsSuccess, error)
+	// 更新用户信息 只更新非空字段
+	UpdateUserInfo(context.Context, *UpdateUserDto) (*IsSuccess, error)
 	mustEmbedUnimplementedUserServiceServer()
 }
 
@@ -152,7 +154,7 @@ func (UnimplementedUserServiceServer) GetUserByUsername(context.Context, *Userna
 func (UnimplementedUserServiceServer) GetUserById(context.Context, *IdDto) (*UserInfoVo, error) {
 	return nil, status.Error(codes.Unimplemented, "method GetUserById not implemented")
 }
-func (UnimplementedUserServiceServer) UpdateUserInfo(context.Context, *UserInfoVo) (*IsSuccess, error) {
+func (UnimplementedUserServiceServer) UpdateUserInfo(context.Context, *UpdateUserDto) (*IsSuccess, error) {
 	return nil, status.Error(codes.Unimplemented, "method UpdateUserInfo not implemented")
 }
 func (UnimplementedUserServiceServer) mustEmbedUnimplementedUserServiceServer() {}
@@ -267,7 +269,7 @@ func _UserService_GetUserById_Handler(srv interface{}, ctx context.Context, dec
 }
 
 func _UserService_UpdateUserInfo_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(UserInfoVo)
+	in := new(UpdateUserDto)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
@@ -279,7 +281,7 @@ func _UserService_UpdateUserInfo_Handler(srv interface{}, ctx context.Context, d
 		FullMethod: UserService_UpdateUserInfo_FullMethodName,
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(UserServiceServer).UpdateUserInfo(ctx, req.(*UserInfoVo))
+		return srv.(UserServiceServer).UpdateUserInfo(ctx, req.(*UpdateUserDto))
 	}
 	return interceptor(ctx, in, info, handler)
 }
@@ -1,9 +1,10 @@
 package main
 
 import (
+	"flag"
 	"fmt"
 
-	"blog_user_service/rpc/inits"
+	"blog-common/tracing"
 	"blog_user_service/rpc/internal/config"
 	"blog_user_service/rpc/internal/server"
 	"blog_user_service/rpc/internal/svc"
@@ -16,12 +17,15 @@ import (
 	"google.golang.org/grpc/reflection"
 )
 
+var configFile = flag.String("f", "etc/config.yaml", "the config file")
+
 func main() {
+	flag.Parse()
+
 	var c config.Config
-	conf.MustLoad("etc/config.yaml", &c)
+	conf.MustLoad(*configFile, &c)
+	// 打开数据库连接 创建数据访问
 	ctx := svc.NewServiceContext(c)
-	//初始化数据库
-	inits.InitDB()
 
 	s := zrpc.MustNewServer(c.RpcServerConf, func(grpcServer *grpc.Server) {
 		user.RegisterUserServiceServer(grpcServer, server.NewUserServiceServer(ctx))
@@ -30,6 +34,8 @@ func main() {
 			reflection.Register(grpcServer)
 		}
 	})
+	// 从 gRPC metadata 中取出请求ID 写入 logx 上下文
+	s.AddUnaryInterceptors(tracing.UnaryServerInterceptor)
 	defer s.Stop()
 
 	fmt.Printf("Starting rpc server at %s...\n", c.ListenOn)
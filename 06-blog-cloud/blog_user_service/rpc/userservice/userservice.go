@@ -20,6 +20,7 @@ type (
 	LoginResponse  = user.LoginResponse
 	PageInfoDto    = user.PageInfoDto
 	RegisterDto    = user.RegisterDto
+	UpdateUserDto  = user.UpdateUserDto
 	UserInfoVo     = user.UserInfoVo
 	UserInfoVoList = user.UserInfoVoList
 	UsernameDto    = user.UsernameDto
@@ -35,7 +36,8 @@ type (
 		GetUserByUsername(ctx context.Context, in *UsernameDto, opts ...grpc.CallOption) (*UserInfoVo, error)
 		// 根据id查询用户
 		GetUserById(ctx context.Context, in *IdDto, opts ...grpc.CallOption) (*UserInfoVo, error)
-		UpdateUserInfo(ctx context.Context, in *UserInfoVo, opts ...grpc.CallOption) (*IsSuccess, error)
+		// 更新用户信息 只更新非空字段
+		UpdateUserInfo(ctx context.Context, in *UpdateUserDto, opts ...grpc.CallOption) (*IsSuccess, error)
 	}
 
 	defaultUserService struct {
@@ -79,7 +81,8 @@ func (m *defaultUserService) GetUserById(ctx context.Context, in *IdDto, opts ..
 	return client.GetUserById(ctx, in, opts...)
 }
 
-func (m *defaultUserService) UpdateUserInfo(ctx context.Context, in *UserInfoVo, opts ...grpc.CallOption) (*IsSuccess, error) {
+// 更新用户信息 只更新非空字段
+func (m *defaultUserService) UpdateUserInfo(ctx context.Context, in *UpdateUserDto, opts ...grpc.CallOption) (*IsSuccess, error) {
 	client := user.NewUserServiceClient(m.cli.Conn())
 	return client.UpdateUserInfo(ctx, in, opts...)
 }